@@ -29,6 +29,8 @@ import (
 	"github.com/memberclass-backend-golang/internal/domain/ports/ai"
 	bunnyport "github.com/memberclass-backend-golang/internal/domain/ports/bunny"
 	comment2 "github.com/memberclass-backend-golang/internal/domain/ports/comment"
+	lessonport "github.com/memberclass-backend-golang/internal/domain/ports/lesson"
+	"github.com/memberclass-backend-golang/internal/domain/ports/pdf_processor"
 	sso3 "github.com/memberclass-backend-golang/internal/domain/ports/sso"
 	tenant2 "github.com/memberclass-backend-golang/internal/domain/ports/tenant"
 	user2 "github.com/memberclass-backend-golang/internal/domain/ports/user"
@@ -97,7 +99,10 @@ func main() {
 			resend.New,
 
 			user3.NewValidateSessionUseCase,
-			lessons.NewPdfProcessorUseCase,
+			// Converted PDFs are also indexed into the transcription slice's search chunks.
+			func(repoResolver lessonport.LessonRepoResolver, pdfService pdf_processor.PdfProcessService, storageService ports.Storage, transcriptionFeat *transcriptionworker.Feature, logger ports.Logger) pdf_processor.PdfProcessorUseCase {
+				return lessons.NewPdfProcessorUseCase(repoResolver, pdfService, storageService, transcriptionFeat, logger)
+			},
 			bunny2.NewTenantGetTenantBunnyCredentialsUseCase,
 			bunny2.NewUploadVideoBunnyCdnUseCase,
 			func(logger ports.Logger, commentRepo comment2.CommentRepository, userRepo user2.UserRepository) comment2.CommentUseCase {
@@ -114,8 +119,8 @@ func main() {
 			// Transcription slice owns the entire pipeline (Bunny → Whisper →
 			// chunk → embed → Railway pgvector). Pulls its own *sql.DB out
 			// of the DBMap (transcription bucket) + memberclass DefaultDB.
-			// No cron — the internal admin UI POSTs to the slice with the
-			// explicit list of lessonIds it wants transcribed.
+			// The internal admin UI POSTs explicit lessonIds; the optional
+			// auto-enqueue job is registered in startApplication.
 			func(dbMap database.DBMap, defaultDB *sql.DB, log ports.Logger, bunnySvc bunnyport.BunnyService) *transcriptionworker.Feature {
 				txDB := dbMap["transcription"]
 				if txDB == nil {
@@ -133,8 +138,9 @@ func main() {
 			func(ssoRepo sso3.SSORepository, userRepo user2.UserRepository, logger ports.Logger) sso3.SSOUseCase {
 				return sso4.NewSSOUseCase(ssoRepo, userRepo, logger)
 			},
-			func(vitrineRepo vitrine2.VitrineRepository) vitrine2.VitrineUseCase {
-				return vitrine3.NewVitrineUseCase(vitrineRepo)
+			// GetLesson attaches the transcription slice's AI summary.
+			func(vitrineRepo vitrine2.VitrineRepository, transcriptionFeat *transcriptionworker.Feature) vitrine2.VitrineUseCase {
+				return vitrine3.NewVitrineUseCase(vitrineRepo, transcriptionFeat)
 			},
 
 			rate_limit.NewRateLimitMiddleware,
@@ -181,9 +187,15 @@ func startApplication(
 ) {
 	router.SetupRoutes()
 
-	// Scheduler is kept running for future ports.Job entries; today every
-	// transcription enqueue flows through the HTTP route the internal
-	// admin UI calls, so no Job is registered here.
+	// Transcription auto-enqueue picks up newly published (or re-uploaded)
+	// video lessons of aiEnabled tenants. Opt-in through
+	// TRANSCRIPTION_AUTO_ENQUEUE_SCHEDULE; explicit enqueues still flow
+	// through the HTTP route the internal admin UI calls.
+	if schedule := transcriptionFeat.AutoEnqueueSchedule(); schedule != "" {
+		if err := scheduler.AddJob(transcriptionFeat.AutoEnqueueJob(), schedule); err != nil {
+			log.Error("Failed to schedule transcription auto-enqueue: " + err.Error())
+		}
+	}
 	scheduler.Start()
 
 	// Member-import slice: clear orphaned "processing" imports on startup,
@@ -175,7 +175,11 @@ func (r *Router) SetupRoutes() {
 			//   POST  /tenants/process-lessons
 			//   GET   /jobs/{jobId}
 			//   PATCH /lessons/{lessonId}/transcription
-			r.transcription.Register(router, transcription.MiddlewareSet{})
+			//   POST  /student/search (mc-api-key)
+			r.transcription.Register(router, transcription.MiddlewareSet{
+				AuthExternal:    r.authExternalMiddleware.Authenticate,
+				RateLimitTenant: r.rateLimitTenantMiddleware.LimitByTenant,
+			})
 		})
 
 		router.Route("/videos", func(router chi.Router) {
@@ -36,6 +36,17 @@ type UploadVideoRequest struct {
 }
 
 
+// AddCaptionRequest uploads one caption track to a Bunny Stream video.
+// CaptionsFile is the raw WebVTT/SRT content; the service base64-encodes
+// it as the Stream API expects.
+type AddCaptionRequest struct {
+	GUID         string `json:"guid"`
+	SrcLang      string `json:"srclang"`
+	Label        string `json:"label"`
+	CaptionsFile []byte `json:"captionsFile"`
+}
+
+
 type BunnyParametersAccess struct {
 	LibraryID string `json:"libraryId"`
 	LibraryApiKey string `json:"libraryApiKey"`
@@ -1,5 +1,7 @@
 package vitrine
 
+import "time"
+
 type VitrineResponse struct {
 	Vitrines []VitrineData `json:"vitrines"`
 	Total    int           `json:"total"`
@@ -45,6 +47,27 @@ type LessonData struct {
 	MediaURL  *string `json:"mediaUrl,omitempty"`
 	Thumbnail *string `json:"thumbnail,omitempty"`
 	Order     *int    `json:"order,omitempty"`
+	// AISummary is only filled by GetLesson, once the lesson's transcript
+	// has been summarized. Lesson.Content (the producer's own
+	// description) is never touched.
+	AISummary *LessonAISummary `json:"aiSummary,omitempty"`
+}
+
+// LessonAISummary is the LLM-written overview generated from the lesson
+// transcript.
+type LessonAISummary struct {
+	Summary     string          `json:"summary"`
+	Chapters    []LessonChapter `json:"chapters"`
+	KeyPoints   []string        `json:"keyPoints"`
+	Language    string          `json:"language,omitempty"`
+	Model       string          `json:"model"`
+	GeneratedAt time.Time       `json:"generatedAt"`
+}
+
+// LessonChapter starts at StartTime seconds into the video.
+type LessonChapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"startTime"`
 }
 
 type VitrineDetailResponse struct {
@@ -11,4 +11,5 @@ type BunnyService interface {
 	GetCollections(ctx context.Context, bunnyParametersAccess dto.BunnyParametersAccess) (*dto.BunnyCollectionsResponse, error)
 	UploadVideo(ctx context.Context, uploadVideoRequest dto.UploadVideoRequest, bunnyParametersAccess dto.BunnyParametersAccess) error
 	CreateVideo(ctx context.Context, video dto.CreateVideoRequest, bunnyParametersAccess dto.BunnyParametersAccess) (*dto.CreateVideoResponse, error)
+	AddCaption(ctx context.Context, addCaptionRequest dto.AddCaptionRequest, bunnyParametersAccess dto.BunnyParametersAccess) error
 }
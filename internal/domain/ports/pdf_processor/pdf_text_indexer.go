@@ -0,0 +1,10 @@
+package pdf_processor
+
+import "context"
+
+// PdfTextIndexer queues a lesson's PDF for page-by-page text extraction
+// and semantic search indexing. It must not download the file itself:
+// the conversion already holds the request open long enough.
+type PdfTextIndexer interface {
+	IndexLessonPdf(ctx context.Context, lessonID string) error
+}
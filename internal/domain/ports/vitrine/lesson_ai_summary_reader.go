@@ -0,0 +1,13 @@
+package vitrine
+
+import (
+	"context"
+
+	"github.com/memberclass-backend-golang/internal/domain/dto/response/vitrine"
+)
+
+// LessonAISummaryReader returns the generated summary of a lesson, or nil
+// when none exists yet.
+type LessonAISummaryReader interface {
+	GetLessonAISummary(ctx context.Context, lessonID, tenantID string) (*vitrine.LessonAISummary, error)
+}
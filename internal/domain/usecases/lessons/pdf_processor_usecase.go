@@ -22,6 +22,7 @@ type pdfProcessorUseCase struct {
 	repoResolver   lesson.LessonRepoResolver
 	pdfService     pdf_processor.PdfProcessService
 	storageService ports.Storage
+	textIndexer    pdf_processor.PdfTextIndexer
 	logger         ports.Logger
 }
 
@@ -40,12 +41,14 @@ func NewPdfProcessorUseCase(
 	repoResolver lesson.LessonRepoResolver,
 	pdfService pdf_processor.PdfProcessService,
 	storageService ports.Storage,
+	textIndexer pdf_processor.PdfTextIndexer,
 	logger ports.Logger,
 ) pdf_processor.PdfProcessorUseCase {
 	return &pdfProcessorUseCase{
 		repoResolver:   repoResolver,
 		pdfService:     pdfService,
 		storageService: storageService,
+		textIndexer:    textIndexer,
 		logger:         logger,
 	}
 }
@@ -141,6 +144,17 @@ func (u *pdfProcessorUseCase) ProcessLesson(ctx context.Context, lessonID string
 		}
 	}
 
+	// 8. Queue page text indexing for search (best effort: the pages are
+	// already saved). The search stack only knows the default bucket's
+	// tenants, so lessons from the other databases are not indexed.
+	if u.textIndexer != nil {
+		if repo != u.repoResolver.Default() {
+			u.logger.Info(fmt.Sprintf("Skipping PDF text indexing for lesson %s: bucket '%s' is not indexed", lessonID, bucket))
+		} else if err := u.textIndexer.IndexLessonPdf(ctx, lessonID); err != nil {
+			u.logger.Error(fmt.Sprintf("Error queueing PDF text indexing for lesson %s: %v", lessonID, err))
+		}
+	}
+
 	return &dto.ProcessResult{
 		Success:        processedPages > 0,
 		TotalPages:     len(images),
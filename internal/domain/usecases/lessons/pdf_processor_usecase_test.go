@@ -2,6 +2,7 @@ package lessons
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -12,7 +13,9 @@ import (
 	"github.com/memberclass-backend-golang/internal/domain/entities/tenant"
 	"github.com/memberclass-backend-golang/internal/domain/memberclasserrors"
 	lesson2 "github.com/memberclass-backend-golang/internal/domain/ports/lesson"
+	"github.com/memberclass-backend-golang/internal/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 // --- Mock Lesson Repository ---
@@ -503,7 +506,7 @@ func TestNewPdfProcessorUseCase(t *testing.T) {
 	storageService := &mockStorageService{}
 	logger := &mockLogger{}
 
-	useCase := NewPdfProcessorUseCase(resolver, pdfService, storageService, logger)
+	useCase := NewPdfProcessorUseCase(resolver, pdfService, storageService, nil, logger)
 
 	assert.NotNil(t, useCase)
 }
@@ -580,6 +583,71 @@ func TestProcessLesson_Success(t *testing.T) {
 	assert.NotNil(t, result)
 }
 
+func TestProcessLesson_IndexesPdfText(t *testing.T) {
+	repo := newMockLessonRepository()
+	resolver := newMockRepoResolver(repo)
+	indexer := mocks.NewMockPdfTextIndexer(t)
+
+	useCase := &pdfProcessorUseCase{
+		repoResolver:   resolver,
+		pdfService:     newMockPdfService(),
+		storageService: &mockStorageService{},
+		textIndexer:    indexer,
+		logger:         &mockLogger{},
+	}
+
+	lessonID := "lesson1"
+	repo.lessons[lessonID] = &lessons.Lesson{
+		ID:       &lessonID,
+		MediaURL: stringPtr("http://example.com/doc1.pdf"),
+	}
+	// Indexing is best effort: its failure must not fail the conversion.
+	indexer.EXPECT().IndexLessonPdf(mock.Anything, lessonID).
+		Return(errors.New("enqueue pdf embedding: connection refused"))
+
+	result, err := useCase.ProcessLesson(context.Background(), lessonID)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
+// otherBucketResolver finds lessons in a repository that is not the
+// default one, like an ephra or celetusclass lesson.
+type otherBucketResolver struct {
+	*mockLessonRepoResolver
+	def lesson2.LessonRepository
+}
+
+func (r *otherBucketResolver) Default() lesson2.LessonRepository {
+	return r.def
+}
+
+func TestProcessLesson_SkipsIndexingOutsideDefaultBucket(t *testing.T) {
+	repo := newMockLessonRepository()
+	resolver := &otherBucketResolver{mockLessonRepoResolver: newMockRepoResolver(repo), def: newMockLessonRepository()}
+	// No expectation: any IndexLessonPdf call fails the test.
+	indexer := mocks.NewMockPdfTextIndexer(t)
+
+	useCase := &pdfProcessorUseCase{
+		repoResolver:   resolver,
+		pdfService:     newMockPdfService(),
+		storageService: &mockStorageService{},
+		textIndexer:    indexer,
+		logger:         &mockLogger{},
+	}
+
+	lessonID := "lesson1"
+	repo.lessons[lessonID] = &lessons.Lesson{
+		ID:       &lessonID,
+		MediaURL: stringPtr("http://example.com/doc1.pdf"),
+	}
+
+	result, err := useCase.ProcessLesson(context.Background(), lessonID)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
 func TestProcessLesson_NoMediaURL(t *testing.T) {
 	repo := newMockLessonRepository()
 	resolver := newMockRepoResolver(repo)
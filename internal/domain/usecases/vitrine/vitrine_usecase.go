@@ -11,11 +11,15 @@ import (
 
 type VitrineUseCaseImpl struct {
 	vitrineRepository vitrineports.VitrineRepository
+	aiSummaries       vitrineports.LessonAISummaryReader
 }
 
-func NewVitrineUseCase(vitrineRepository vitrineports.VitrineRepository) vitrineports.VitrineUseCase {
+// NewVitrineUseCase builds the use case. aiSummaries may be nil, in which
+// case lessons are returned without an AI summary.
+func NewVitrineUseCase(vitrineRepository vitrineports.VitrineRepository, aiSummaries vitrineports.LessonAISummaryReader) vitrineports.VitrineUseCase {
 	return &VitrineUseCaseImpl{
 		vitrineRepository: vitrineRepository,
+		aiSummaries:       aiSummaries,
 	}
 }
 
@@ -115,5 +119,18 @@ func (uc *VitrineUseCaseImpl) GetLesson(ctx context.Context, lessonID, tenantID
 		tenantID = tenant.ID
 	}
 
-	return uc.vitrineRepository.GetLessonByID(ctx, lessonID, tenantID)
+	response, err := uc.vitrineRepository.GetLessonByID(ctx, lessonID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The AI summary is an optional extra: if the summaries store is
+	// unavailable the lesson is still returned, just without it.
+	if uc.aiSummaries != nil && response != nil {
+		if summary, err := uc.aiSummaries.GetLessonAISummary(ctx, lessonID, tenantID); err == nil {
+			response.Lesson.AISummary = summary
+		}
+	}
+
+	return response, nil
 }
@@ -17,7 +17,7 @@ import (
 func TestNewVitrineUseCase(t *testing.T) {
 	mockRepo := mocks.NewMockVitrineRepository(t)
 
-	useCase := NewVitrineUseCase(mockRepo)
+	useCase := NewVitrineUseCase(mockRepo, nil)
 
 	assert.NotNil(t, useCase)
 }
@@ -103,7 +103,7 @@ func TestVitrineUseCase_GetVitrines(t *testing.T) {
 			mockRepo := mocks.NewMockVitrineRepository(t)
 			tt.mockSetup(mockRepo)
 
-			useCase := NewVitrineUseCase(mockRepo)
+			useCase := NewVitrineUseCase(mockRepo, nil)
 
 			result, err := useCase.GetVitrines(context.Background(), tt.tenantID)
 
@@ -246,7 +246,7 @@ func TestVitrineUseCase_GetVitrine(t *testing.T) {
 			mockRepo := mocks.NewMockVitrineRepository(t)
 			tt.mockSetup(mockRepo)
 
-			useCase := NewVitrineUseCase(mockRepo)
+			useCase := NewVitrineUseCase(mockRepo, nil)
 
 			result, err := useCase.GetVitrine(tt.ctx, tt.vitrineID, tt.tenantID, tt.includeChildren)
 
@@ -376,7 +376,7 @@ func TestVitrineUseCase_GetCourse(t *testing.T) {
 			mockRepo := mocks.NewMockVitrineRepository(t)
 			tt.mockSetup(mockRepo)
 
-			useCase := NewVitrineUseCase(mockRepo)
+			useCase := NewVitrineUseCase(mockRepo, nil)
 
 			result, err := useCase.GetCourse(tt.ctx, tt.courseID, tt.tenantID, tt.includeChildren)
 
@@ -506,7 +506,7 @@ func TestVitrineUseCase_GetModule(t *testing.T) {
 			mockRepo := mocks.NewMockVitrineRepository(t)
 			tt.mockSetup(mockRepo)
 
-			useCase := NewVitrineUseCase(mockRepo)
+			useCase := NewVitrineUseCase(mockRepo, nil)
 
 			result, err := useCase.GetModule(tt.ctx, tt.moduleID, tt.tenantID, tt.includeChildren)
 
@@ -631,7 +631,7 @@ func TestVitrineUseCase_GetLesson(t *testing.T) {
 			mockRepo := mocks.NewMockVitrineRepository(t)
 			tt.mockSetup(mockRepo)
 
-			useCase := NewVitrineUseCase(mockRepo)
+			useCase := NewVitrineUseCase(mockRepo, nil)
 
 			result, err := useCase.GetLesson(tt.ctx, tt.lessonID, tt.tenantID)
 
@@ -657,3 +657,60 @@ func TestVitrineUseCase_GetLesson(t *testing.T) {
 		})
 	}
 }
+
+func TestVitrineUseCase_GetLesson_AISummary(t *testing.T) {
+	lesson := func() *vitrine.LessonDetailResponse {
+		return &vitrine.LessonDetailResponse{
+			Lesson: vitrine.LessonData{ID: "lesson-123", Name: "Lesson 1"},
+		}
+	}
+
+	t.Run("should attach the AI summary when one exists", func(t *testing.T) {
+		mockRepo := mocks.NewMockVitrineRepository(t)
+		mockSummaries := mocks.NewMockLessonAISummaryReader(t)
+		mockRepo.EXPECT().GetLessonByID(mock.Anything, "lesson-123", "tenant-123").Return(lesson(), nil)
+		mockSummaries.EXPECT().GetLessonAISummary(mock.Anything, "lesson-123", "tenant-123").Return(&vitrine.LessonAISummary{
+			Summary:   "Resumo da aula",
+			Chapters:  []vitrine.LessonChapter{{Title: "Introdução", StartTime: 0}},
+			KeyPoints: []string{"Ponto 1"},
+			Model:     "gpt-4o-mini",
+		}, nil)
+
+		useCase := NewVitrineUseCase(mockRepo, mockSummaries)
+		result, err := useCase.GetLesson(context.Background(), "lesson-123", "tenant-123")
+
+		assert.NoError(t, err)
+		assert.NotNil(t, result.Lesson.AISummary)
+		assert.Equal(t, "Resumo da aula", result.Lesson.AISummary.Summary)
+		assert.Len(t, result.Lesson.AISummary.Chapters, 1)
+	})
+
+	t.Run("should return the lesson when the summary lookup fails", func(t *testing.T) {
+		mockRepo := mocks.NewMockVitrineRepository(t)
+		mockSummaries := mocks.NewMockLessonAISummaryReader(t)
+		mockRepo.EXPECT().GetLessonByID(mock.Anything, "lesson-123", "tenant-123").Return(lesson(), nil)
+		mockSummaries.EXPECT().GetLessonAISummary(mock.Anything, "lesson-123", "tenant-123").Return(nil, errors.New("db down"))
+
+		useCase := NewVitrineUseCase(mockRepo, mockSummaries)
+		result, err := useCase.GetLesson(context.Background(), "lesson-123", "tenant-123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "lesson-123", result.Lesson.ID)
+		assert.Nil(t, result.Lesson.AISummary)
+	})
+
+	t.Run("should not look up a summary when the lesson is not found", func(t *testing.T) {
+		mockRepo := mocks.NewMockVitrineRepository(t)
+		mockSummaries := mocks.NewMockLessonAISummaryReader(t)
+		mockRepo.EXPECT().GetLessonByID(mock.Anything, "lesson-123", "tenant-123").Return(nil, &memberclasserrors.MemberClassError{
+			Code:    404,
+			Message: "Aula não encontrada",
+		})
+
+		useCase := NewVitrineUseCase(mockRepo, mockSummaries)
+		result, err := useCase.GetLesson(context.Background(), "lesson-123", "tenant-123")
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+	})
+}
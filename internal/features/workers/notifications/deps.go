@@ -9,6 +9,10 @@
 //   - Resolve recipients per fanout/audience, filter by
 //     UsersOnTenants.pushDisabledTypes.
 //   - Dispatch via FCM topic (audience=tenant) or multicast (chunks of 500).
+//     Each batch is split by locale — NotificationDevice.locale, then
+//     User.language (each only when the column exists), then
+//     Tenant.language, then pt-BR — so every device gets text rendered in
+//     its language.
 //   - Persist progress (sentCount/failedCount/lastBatchIndex) so a crashed
 //     run can resume without resending.
 //   - Daily cleanup: 30d retention on Notification + top-100 trim on
@@ -36,6 +40,11 @@ type Feature struct {
 	running bool
 	cancel  context.CancelFunc
 	done    chan struct{}
+
+	// localeCols caches getLocaleColumns; nil until the first successful
+	// probe.
+	localeMu   sync.Mutex
+	localeCols *localeColumns
 }
 
 // New builds the slice. Wire it in cmd/api/main.go via fx.Provide.
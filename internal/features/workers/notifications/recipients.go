@@ -6,10 +6,28 @@ import (
 
 // recipient is a single (userId, fcm token) pair the worker will push to.
 // One user may appear multiple times (one per device) — that's fine, each
-// token is an independent FCM target.
+// token is an independent FCM target. deviceLocale and userLanguage are
+// NotificationDevice.locale and User.language, "" when unset or when the
+// column does not exist (see localeColumns).
 type recipient struct {
-	userID string
-	token  string
+	userID       string
+	token        string
+	deviceLocale string
+	userLanguage string
+}
+
+// localeExprs returns the SQL for the two locale columns of a recipient
+// query: the column when it exists, NULL otherwise. userIDCol is the
+// query's user id expression.
+func (c localeColumns) localeExprs(userIDCol string) (device, user string) {
+	device, user = "NULL", "NULL"
+	if c.deviceLocale {
+		device = `nd."locale"::text`
+	}
+	if c.userLanguage {
+		user = `(SELECT u."language"::text FROM "User" u WHERE u.id = ` + userIDCol + `)`
+	}
+	return device, user
 }
 
 // resolveRecipients enumerates the destinations for a multicast send.
@@ -24,13 +42,14 @@ type recipient struct {
 // shifts between calls (e.g. the planner picks a different join), resumed
 // runs target a different slice of users — duplicate sends to some and
 // missed sends to others. The ORDER BY in each query keeps the slice stable.
-func (f *Feature) resolveRecipients(ctx context.Context, n Notification) ([]recipient, error) {
+func (f *Feature) resolveRecipients(ctx context.Context, n Notification, cols localeColumns) ([]recipient, error) {
 	switch {
 	case n.Fanout == FanoutWrite:
 		// Personal notification — one or more devices for a single user, joined
 		// through the UserNotification row that the writer (Next.js) created.
-		const q = `
-			SELECT un."userId", nd.token
+		device, user := cols.localeExprs(`un."userId"`)
+		q := `
+			SELECT un."userId", nd.token, COALESCE(` + device + `, ''), COALESCE(` + user + `, '')
 			FROM "UserNotification" un
 			JOIN "UsersOnTenants" uot
 			  ON uot."userId" = un."userId" AND uot."tenantId" = un."tenantId"
@@ -48,8 +67,9 @@ func (f *Feature) resolveRecipients(ctx context.Context, n Notification) ([]reci
 		// because the user has not logged in yet to bind the device. Those
 		// anonymous devices ALWAYS receive (no preference to honor); logged-in
 		// devices apply the pushDisabledTypes filter.
-		const q = `
-			SELECT COALESCE(nd."userId", ''), nd.token
+		device, user := cols.localeExprs(`nd."userId"`)
+		q := `
+			SELECT COALESCE(nd."userId", ''), nd.token, COALESCE(` + device + `, ''), COALESCE(` + user + `, '')
 			FROM "NotificationDevice" nd
 			LEFT JOIN "UsersOnTenants" uot
 			  ON uot."userId" = nd."userId" AND uot."tenantId" = nd."tenantId"
@@ -63,8 +83,9 @@ func (f *Feature) resolveRecipients(ctx context.Context, n Notification) ([]reci
 		return f.queryRecipients(ctx, q, n.TenantID, string(n.Type))
 
 	case n.Fanout == FanoutRead && deref(n.AudienceType) == string(AudienceDelivery):
-		const q = `
-			SELECT mod."memberId", nd.token
+		device, user := cols.localeExprs(`mod."memberId"`)
+		q := `
+			SELECT mod."memberId", nd.token, COALESCE(` + device + `, ''), COALESCE(` + user + `, '')
 			FROM "MemberOnDelivery" mod
 			JOIN "UsersOnTenants" uot
 			  ON uot."userId" = mod."memberId" AND uot."tenantId" = mod."tenantId"
@@ -91,7 +112,7 @@ func (f *Feature) queryRecipients(ctx context.Context, q string, args ...any) ([
 	var out []recipient
 	for rows.Next() {
 		var r recipient
-		if err := rows.Scan(&r.userID, &r.token); err != nil {
+		if err := rows.Scan(&r.userID, &r.token, &r.deviceLocale, &r.userLanguage); err != nil {
 			return nil, err
 		}
 		out = append(out, r)
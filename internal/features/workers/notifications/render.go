@@ -6,15 +6,26 @@ import (
 	"strings"
 )
 
-// pushTemplate is the pt-BR text for a system notification key. Broadcasts
-// don't use this — they ship Title/Body literal strings.
+// pushTemplate is the localized text for a system notification key.
+// Broadcasts don't use this — they ship Title/Body literal strings.
 type pushTemplate struct {
 	title string
 	body  string
 }
 
-// ptBR mirrors the Next.js `messages/pt-BR.json` push entries. KEEP IN SYNC
-// when adding new system notification types — both sides need the key.
+// defaultPushLocale is used when none of the device, the user and the
+// tenant names a supported locale, and for keys missing from another
+// locale's map.
+const defaultPushLocale = "pt-BR"
+
+// pushLocales lists the supported locales in the order each batch's
+// per-locale sends go out.
+var pushLocales = []string{"pt-BR", "en", "es"}
+
+// ptBR, en and es mirror the Next.js `messages/<locale>.json` push
+// entries. KEEP IN SYNC when adding new system notification types — both
+// sides need the key, and ptBR must have every key since it is the
+// fallback.
 //
 // The {placeholder} tokens are interpolated from Notification.messageData,
 // which is a JSON object the writer (Next.js) already validated.
@@ -29,24 +40,89 @@ var ptBR = map[string]pushTemplate{
 	},
 }
 
+var en = map[string]pushTemplate{
+	"notifications.commentReply": {
+		title: "Your question was answered",
+		body:  `Your question on the lesson "{lessonName}" was answered.`,
+	},
+	"notifications.postComment": {
+		title: "New comment on your post",
+		body:  "{actorName} commented on your community post.",
+	},
+}
+
+var es = map[string]pushTemplate{
+	"notifications.commentReply": {
+		title: "Tu pregunta fue respondida",
+		body:  `Tu pregunta en la clase "{lessonName}" fue respondida.`,
+	},
+	"notifications.postComment": {
+		title: "Comentaron tu publicación",
+		body:  "{actorName} comentó tu publicación en la comunidad.",
+	},
+}
+
+var pushTemplates = map[string]map[string]pushTemplate{
+	"pt-BR": ptBR,
+	"en":    en,
+	"es":    es,
+}
+
+// normalizePushLocale maps a device, user or tenant locale ("pt_BR", "en-US",
+// "ES") to one of pushLocales, or "" when the language is not supported.
+func normalizePushLocale(raw string) string {
+	lang := strings.ToLower(strings.TrimSpace(raw))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch lang {
+	case "pt":
+		return "pt-BR"
+	case "en", "es":
+		return lang
+	}
+	return ""
+}
+
+// resolvePushLocale picks the first supported locale among the
+// candidates — device first, then user, then tenant — falling back to
+// defaultPushLocale.
+func resolvePushLocale(candidates ...string) string {
+	for _, c := range candidates {
+		if l := normalizePushLocale(c); l != "" {
+			return l
+		}
+	}
+	return defaultPushLocale
+}
+
+// isLiteralPush reports whether n carries its own title/body (broadcasts),
+// which every device gets as-is whatever its locale.
+func isLiteralPush(n Notification) bool {
+	return n.Title != nil && n.Body != nil
+}
+
 // renderForPush picks the title/body that will appear on the device's
 // notification tray.
 //
 //   - Broadcasts (admin) carry literal Title/Body — used as-is.
 //   - System notifications carry MessageKey + MessageData — looked up in
-//     `ptBR` and interpolated.
+//     the locale's templates (ptBR when the key is missing there) and
+//     interpolated.
 //
-// MVP is pt-BR only. Per-device locale is a future enhancement; the app
-// can already re-render in the user's language when it opens because
-// MessageData is forwarded as the FCM data payload elsewhere.
-func renderForPush(n Notification) (title, body string) {
-	if n.Title != nil && n.Body != nil {
+// The app can still re-render in the user's language when it opens
+// because MessageData is forwarded as the FCM data payload elsewhere.
+func renderForPush(n Notification, locale string) (title, body string) {
+	if isLiteralPush(n) {
 		return *n.Title, *n.Body
 	}
 	if n.MessageKey == nil {
 		return "", ""
 	}
-	tpl, ok := ptBR[*n.MessageKey]
+	tpl, ok := pushTemplates[locale][*n.MessageKey]
+	if !ok {
+		tpl, ok = ptBR[*n.MessageKey]
+	}
 	if !ok {
 		// Fallback: at least show the key so a missing translation is
 		// visible in QA instead of an empty notification.
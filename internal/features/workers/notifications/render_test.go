@@ -10,6 +10,7 @@ func TestRenderForPush(t *testing.T) {
 	tests := []struct {
 		name      string
 		n         Notification
+		locale    string
 		wantTitle string
 		wantBody  string
 	}{
@@ -47,6 +48,36 @@ func TestRenderForPush(t *testing.T) {
 			wantTitle: "Comentaram no seu post",
 			wantBody:  "{actorName} comentou no seu post da comunidade.",
 		},
+		{
+			name: "system commentReply in English",
+			n: Notification{
+				MessageKey:  ptr("notifications.commentReply"),
+				MessageData: []byte(`{"lessonName":"Lesson 1"}`),
+			},
+			locale:    "en",
+			wantTitle: "Your question was answered",
+			wantBody:  `Your question on the lesson "Lesson 1" was answered.`,
+		},
+		{
+			name: "system postComment in Spanish",
+			n: Notification{
+				MessageKey:  ptr("notifications.postComment"),
+				MessageData: []byte(`{"actorName":"Ana"}`),
+			},
+			locale:    "es",
+			wantTitle: "Comentaron tu publicación",
+			wantBody:  "Ana comentó tu publicación en la comunidad.",
+		},
+		{
+			name: "broadcast ignores locale",
+			n: Notification{
+				Title: ptr("Aviso"),
+				Body:  ptr("Estamos em manutenção"),
+			},
+			locale:    "en",
+			wantTitle: "Aviso",
+			wantBody:  "Estamos em manutenção",
+		},
 		{
 			name:      "no title, no key returns empty",
 			n:         Notification{},
@@ -56,7 +87,7 @@ func TestRenderForPush(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			gotT, gotB := renderForPush(tc.n)
+			gotT, gotB := renderForPush(tc.n, tc.locale)
 			if gotT != tc.wantTitle {
 				t.Errorf("title: got %q want %q", gotT, tc.wantTitle)
 			}
@@ -66,3 +97,33 @@ func TestRenderForPush(t *testing.T) {
 		})
 	}
 }
+
+func TestResolvePushLocale(t *testing.T) {
+	cases := []struct {
+		device, user, tenant, want string
+	}{
+		{"en-US", "es", "pt-BR", "en"},
+		{"es_MX", "", "", "es"},
+		{"", "es", "en", "es"},
+		{"", "", "en", "en"},
+		{"fr-FR", "de", "ES", "es"},
+		{"pt", "", "en", "pt-BR"},
+		{"", "", "", "pt-BR"},
+		{"de", "fr", "ja", "pt-BR"},
+	}
+	for _, c := range cases {
+		if got := resolvePushLocale(c.device, c.user, c.tenant); got != c.want {
+			t.Errorf("resolvePushLocale(%q, %q, %q) = %q, want %q", c.device, c.user, c.tenant, got, c.want)
+		}
+	}
+}
+
+func TestRenderForPush_EveryLocaleHasPtBRKeys(t *testing.T) {
+	for locale, templates := range pushTemplates {
+		for key := range templates {
+			if _, ok := ptBR[key]; !ok {
+				t.Errorf("%s has %q, missing from the ptBR fallback", locale, key)
+			}
+		}
+	}
+}
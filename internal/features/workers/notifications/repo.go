@@ -109,21 +109,70 @@ func (f *Feature) setRecipientCount(ctx context.Context, id string, n int) error
 	return err
 }
 
-// getTenantInstance reads Tenant.notificationsInstance — the value drives
-// which Firebase project the FCM client uses. Returns "" when the column
-// is null (default project).
-func (f *Feature) getTenantInstance(ctx context.Context, tenantID string) (string, error) {
-	var instance sql.NullString
-	err := f.db.QueryRowContext(ctx,
-		`SELECT "notificationsInstance" FROM "Tenant" WHERE id = $1`, tenantID,
-	).Scan(&instance)
+// getTenantPushSettings reads Tenant.notificationsInstance — the value
+// drives which Firebase project the FCM client uses — and Tenant.language,
+// the locale for devices that report none. Either is "" when the column
+// is null (default project, default locale).
+func (f *Feature) getTenantPushSettings(ctx context.Context, tenantID string) (instance, language string, err error) {
+	var inst, lang sql.NullString
+	err = f.db.QueryRowContext(ctx,
+		`SELECT "notificationsInstance", "language" FROM "Tenant" WHERE id = $1`, tenantID,
+	).Scan(&inst, &lang)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
-	if !instance.Valid {
-		return "", nil
+	return inst.String, lang.String, nil
+}
+
+// localeColumns records which optional per-recipient locale columns the
+// database has. This repo does not own the Prisma schema and neither
+// NotificationDevice.locale nor User.language is guaranteed to exist, so
+// the recipient queries only read the ones that do.
+type localeColumns struct {
+	deviceLocale bool
+	userLanguage bool
+}
+
+// getLocaleColumns probes information_schema once per process and caches
+// the answer. A failed probe is not cached: the caller falls back to the
+// tenant language and the next dispatch probes again. Adding either column
+// takes effect on the next worker restart.
+func (f *Feature) getLocaleColumns(ctx context.Context) (localeColumns, error) {
+	f.localeMu.Lock()
+	defer f.localeMu.Unlock()
+	if f.localeCols != nil {
+		return *f.localeCols, nil
+	}
+	rows, err := f.db.QueryContext(ctx, `
+		SELECT table_name, column_name
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+		  AND ((table_name = 'NotificationDevice' AND column_name = 'locale')
+		    OR (table_name = 'User' AND column_name = 'language'))
+	`)
+	if err != nil {
+		return localeColumns{}, err
+	}
+	defer rows.Close()
+
+	var cols localeColumns
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return localeColumns{}, err
+		}
+		switch table {
+		case "NotificationDevice":
+			cols.deviceLocale = true
+		case "User":
+			cols.userLanguage = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return localeColumns{}, err
 	}
-	return instance.String, nil
+	f.localeCols = &cols
+	return cols, nil
 }
 
 // deleteDevice removes a stale FCM token (FCM returned
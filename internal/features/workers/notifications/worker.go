@@ -135,9 +135,9 @@ func (f *Feature) tick(ctx context.Context) error {
 // fanout/audience. It never returns nil for "I tried but the row is now
 // failed" — the caller checks error and writes the failed row itself.
 func (f *Feature) dispatch(ctx context.Context, dlog *dispatchLog, n Notification) error {
-	instance, err := f.getTenantInstance(ctx, n.TenantID)
+	instance, language, err := f.getTenantPushSettings(ctx, n.TenantID)
 	if err != nil {
-		return fmt.Errorf("get tenant instance: %w", err)
+		return fmt.Errorf("get tenant push settings: %w", err)
 	}
 
 	sender, projectID, err := f.fcm.messaging(ctx, instance)
@@ -148,15 +148,73 @@ func (f *Feature) dispatch(ctx context.Context, dlog *dispatchLog, n Notificatio
 		"firebase_project_id", projectID,
 		"notifications_instance", instance)
 
-	title, body := renderForPush(n)
-	return f.sendMulticast(ctx, dlog, sender, n, title, body)
+	return f.sendMulticast(ctx, dlog, sender, n, language)
 }
 
-// sendMulticast resolves the recipient list, chunks at 500 tokens, and
-// updates lastBatchIndex after each chunk so a crash mid-broadcast resumes
-// without duplicating sends.
-func (f *Feature) sendMulticast(ctx context.Context, dlog *dispatchLog, sender fcmSender, n Notification, title, body string) error {
-	recipients, err := f.resolveRecipients(ctx, n)
+// pushBatch is one checkpointed slice of up to batchSize recipients, cut
+// from the query order alone. Locale never moves a recipient to another
+// batch, so lastBatchIndex means the same slice across a deploy or after a
+// device changes language. Inside the batch each localeSend is one FCM
+// multicast call.
+type pushBatch struct {
+	sends []localeSend
+}
+
+// localeSend is the part of a batch whose devices share a locale, with
+// the title/body rendered for it.
+type localeSend struct {
+	locale     string
+	title      string
+	body       string
+	recipients []recipient
+}
+
+// planBatches chunks the recipients at batchSize in query order and
+// splits each chunk by locale (device locale, then user language, then
+// tenantLanguage, then pt-BR), in pushLocales order. Broadcasts render
+// the same for everyone and stay one send per batch.
+func planBatches(n Notification, recipients []recipient, tenantLanguage string) []pushBatch {
+	var batches []pushBatch
+	for i := 0; i < len(recipients); i += batchSize {
+		chunk := recipients[i:min(i+batchSize, len(recipients))]
+		groups := map[string][]recipient{}
+		for _, r := range chunk {
+			locale := defaultPushLocale
+			if !isLiteralPush(n) {
+				locale = resolvePushLocale(r.deviceLocale, r.userLanguage, tenantLanguage)
+			}
+			groups[locale] = append(groups[locale], r)
+		}
+		var batch pushBatch
+		for _, locale := range pushLocales {
+			group := groups[locale]
+			if len(group) == 0 {
+				continue
+			}
+			title, body := renderForPush(n, locale)
+			batch.sends = append(batch.sends, localeSend{
+				locale:     locale,
+				title:      title,
+				body:       body,
+				recipients: group,
+			})
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// sendMulticast resolves the recipient list, chunks at 500 tokens, sends
+// each chunk as one multicast per locale, and updates lastBatchIndex after
+// each chunk so a crash mid-broadcast resumes without duplicating sends.
+func (f *Feature) sendMulticast(ctx context.Context, dlog *dispatchLog, sender fcmSender, n Notification, tenantLanguage string) error {
+	cols, err := f.getLocaleColumns(ctx)
+	if err != nil {
+		// Only the per-device/per-user locale is lost; the tenant language
+		// still applies.
+		dlog.Warn("notifications.worker.locale_columns_probe_failed", "error", err.Error())
+	}
+	recipients, err := f.resolveRecipients(ctx, n, cols)
 	if err != nil {
 		return fmt.Errorf("resolve recipients: %w", err)
 	}
@@ -171,10 +229,10 @@ func (f *Feature) sendMulticast(ctx context.Context, dlog *dispatchLog, sender f
 		return f.markSent(ctx, n.ID, 0, 0)
 	}
 
-	totalBatches := (len(recipients) + batchSize - 1) / batchSize
+	batches := planBatches(n, recipients, tenantLanguage)
 	dlog.Info("notifications.worker.recipients_resolved",
 		"recipients", len(recipients),
-		"batches", totalBatches,
+		"batches", len(batches),
 		"batch_size", batchSize)
 
 	if n.RecipientCount == nil {
@@ -199,45 +257,47 @@ func (f *Feature) sendMulticast(ctx context.Context, dlog *dispatchLog, sender f
 	failed := n.FailedCount
 	deadTokens := 0
 
-	for batchIdx, i := startBatch, startBatch*batchSize; i < len(recipients); batchIdx, i = batchIdx+1, i+batchSize {
-		end := min(i+batchSize, len(recipients))
-		chunk := recipients[i:end]
+	for batchIdx := startBatch; batchIdx < len(batches); batchIdx++ {
+		for _, send := range batches[batchIdx].sends {
+			chunk := send.recipients
 
-		tokens := make([]string, len(chunk))
-		for k, r := range chunk {
-			tokens[k] = r.token
-		}
+			tokens := make([]string, len(chunk))
+			for k, r := range chunk {
+				tokens[k] = r.token
+			}
 
-		resp, err := sender.SendEachForMulticast(ctx, &messaging.MulticastMessage{
-			Tokens:       tokens,
-			Notification: &messaging.Notification{Title: title, Body: body},
-		})
-		if err != nil {
-			return fmt.Errorf("fcm multicast batch %d: %w", batchIdx, err)
-		}
+			resp, err := sender.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+				Tokens:       tokens,
+				Notification: &messaging.Notification{Title: send.title, Body: send.body},
+			})
+			if err != nil {
+				return fmt.Errorf("fcm multicast batch %d (%s): %w", batchIdx, send.locale, err)
+			}
 
-		sent += resp.SuccessCount
-		failed += resp.FailureCount
-
-		// Best-effort: drop tokens FCM said are dead. If the user reinstalls
-		// the app it'll register a new token via NotificationDevice anyway.
-		for k, r := range resp.Responses {
-			if r.Error != nil && messaging.IsUnregistered(r.Error) {
-				deadTokens++
-				if dErr := f.deleteDevice(ctx, n.TenantID, tokens[k]); dErr != nil {
-					dlog.Warn("notifications.worker.delete_device_failed",
-						"user_id", chunk[k].userID, "error", dErr.Error())
+			sent += resp.SuccessCount
+			failed += resp.FailureCount
+
+			// Best-effort: drop tokens FCM said are dead. If the user reinstalls
+			// the app it'll register a new token via NotificationDevice anyway.
+			for k, r := range resp.Responses {
+				if r.Error != nil && messaging.IsUnregistered(r.Error) {
+					deadTokens++
+					if dErr := f.deleteDevice(ctx, n.TenantID, tokens[k]); dErr != nil {
+						dlog.Warn("notifications.worker.delete_device_failed",
+							"user_id", chunk[k].userID, "error", dErr.Error())
+					}
 				}
 			}
-		}
 
-		dlog.Info("notifications.worker.multicast_batch_sent",
-			"batch_index", batchIdx,
-			"batch_size", len(chunk),
-			"batch_success", resp.SuccessCount,
-			"batch_failure", resp.FailureCount,
-			"running_sent", sent,
-			"running_failed", failed)
+			dlog.Info("notifications.worker.multicast_batch_sent",
+				"batch_index", batchIdx,
+				"batch_size", len(chunk),
+				"locale", send.locale,
+				"batch_success", resp.SuccessCount,
+				"batch_failure", resp.FailureCount,
+				"running_sent", sent,
+				"running_failed", failed)
+		}
 
 		if err := f.updateProgress(ctx, n.ID, sent, failed, batchIdx); err != nil {
 			dlog.Warn("notifications.worker.progress_update_failed", "error", err.Error())
@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"sync"
 	"testing"
@@ -81,6 +82,9 @@ func newTestFeature(t *testing.T, sender *fakeSender) (*Feature, sqlmock.Sqlmock
 			return sender, nil
 		},
 	}
+	// Pretend the optional locale columns were already probed and exist;
+	// TestGetLocaleColumns covers the probe itself.
+	f.localeCols = &localeColumns{deviceLocale: true, userLanguage: true}
 	return f, mock, func() { _ = db.Close() }
 }
 
@@ -130,19 +134,19 @@ func TestDispatch_TenantAudience_MulticastsAllDevices(t *testing.T) {
 
 	t.Setenv("FIREBASE_SERVICE_ACCOUNT_KEY", `{"type":"service_account"}`)
 
-	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "notificationsInstance" FROM "Tenant"`)).
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "notificationsInstance", "language" FROM "Tenant"`)).
 		WithArgs("t1").
-		WillReturnRows(sqlmock.NewRows([]string{"notificationsInstance"}).AddRow(nil))
+		WillReturnRows(sqlmock.NewRows([]string{"notificationsInstance", "language"}).AddRow(nil, nil))
 
 	// Three devices: anonymous (userId=null), logged-in user-A, logged-in
 	// user-B. All returned by the query because the disabled-types filter
 	// was satisfied for B and bypassed for the anonymous row.
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(nd."userId", ''), nd.token`)).
 		WithArgs("t1", string(TypeAdminBroadcast)).
-		WillReturnRows(sqlmock.NewRows([]string{"userId", "token"}).
-			AddRow("", "anon-tok").
-			AddRow("uA", "tokA").
-			AddRow("uB", "tokB"))
+		WillReturnRows(sqlmock.NewRows([]string{"userId", "token", "deviceLocale", "userLanguage"}).
+			AddRow("", "anon-tok", "", "").
+			AddRow("uA", "tokA", "en-US", "").
+			AddRow("uB", "tokB", "", "es"))
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
 		SET "recipientCount"`)).
 		WithArgs("n1", 3).
@@ -171,6 +175,148 @@ func TestDispatch_TenantAudience_MulticastsAllDevices(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+// ---------- planBatches: per-locale rendering ----------
+
+func TestPlanBatches_SplitsEachBatchByLocale(t *testing.T) {
+	n := Notification{
+		ID: "n1", TenantID: "t1",
+		Type: TypeCommentReply, Fanout: FanoutWrite,
+		MessageKey:  ptr("notifications.commentReply"),
+		MessageData: []byte(`{"lessonName":"A1"}`),
+	}
+	recipients := []recipient{
+		{userID: "u1", token: "tok-es", deviceLocale: "es-AR"},
+		{userID: "u1", token: "tok-tenant"},
+		{userID: "u2", token: "tok-pt", deviceLocale: "pt-BR", userLanguage: "en"},
+		{userID: "u3", token: "tok-user", userLanguage: "es"},
+	}
+
+	batches := planBatches(n, recipients, "en")
+	require.Len(t, batches, 1)
+	sends := batches[0].sends
+	require.Len(t, sends, 3)
+	require.Equal(t, "pt-BR", sends[0].locale)
+	require.Equal(t, []recipient{recipients[2]}, sends[0].recipients)
+	require.Equal(t, "en", sends[1].locale)
+	require.Equal(t, []recipient{recipients[1]}, sends[1].recipients)
+	require.Equal(t, "Your question was answered", sends[1].title)
+	require.Equal(t, "es", sends[2].locale)
+	require.Equal(t, []recipient{recipients[0], recipients[3]}, sends[2].recipients)
+	require.Equal(t, `Tu pregunta en la clase "A1" fue respondida.`, sends[2].body)
+
+	// Broadcast text is literal: one send regardless of device locale.
+	n = Notification{Title: ptr("hi"), Body: ptr("there")}
+	batches = planBatches(n, recipients, "en")
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0].sends, 1)
+	require.Len(t, batches[0].sends[0].recipients, 4)
+}
+
+// TestPlanBatches_BoundariesIgnoreLocale pins what lastBatchIndex relies
+// on: batch membership follows the query order only, so a locale change
+// between runs cannot move a recipient into an already-finished batch.
+func TestPlanBatches_BoundariesIgnoreLocale(t *testing.T) {
+	n := Notification{MessageKey: ptr("notifications.commentReply")}
+	recipients := make([]recipient, batchSize+100)
+	for i := range recipients {
+		recipients[i] = recipient{userID: "u", token: fmt.Sprintf("tok-%d", i)}
+	}
+	before := planBatches(n, recipients, "")
+	for i := range recipients {
+		if i%2 == 0 {
+			recipients[i].deviceLocale = "es"
+		}
+	}
+	after := planBatches(n, recipients, "")
+
+	tokens := func(b pushBatch) []string {
+		var out []string
+		for _, s := range b.sends {
+			for _, r := range s.recipients {
+				out = append(out, r.token)
+			}
+		}
+		return out
+	}
+	require.Len(t, before, 2)
+	require.Len(t, after, 2)
+	for i := range before {
+		require.ElementsMatch(t, tokens(before[i]), tokens(after[i]), "batch %d", i)
+	}
+}
+
+func TestSendMulticast_SendsOneMulticastPerLocale(t *testing.T) {
+	sender := &fakeSender{}
+	f, mock, cleanup := newTestFeature(t, sender)
+	defer cleanup()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT un."userId", nd.token, COALESCE(nd."locale"::text, ''), COALESCE((SELECT u."language"::text FROM "User" u WHERE u.id = un."userId"), '')`)).
+		WithArgs("nL", string(TypeCommentReply)).
+		WillReturnRows(sqlmock.NewRows([]string{"userId", "token", "deviceLocale", "userLanguage"}).
+			AddRow("u1", "phone", "es", "").
+			AddRow("u1", "tablet", "", ""))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
+		SET "recipientCount"`)).
+		WithArgs("nL", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Both sends belong to batch 0: one progress update.
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
+		SET "sentCount" = $2, "failedCount" = $3, "lastBatchIndex" = $4`)).
+		WithArgs("nL", 2, 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
+		SET status = 'sent'`)).
+		WithArgs("nL", 2, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n := Notification{
+		ID: "nL", TenantID: "t1",
+		Type: TypeCommentReply, Fanout: FanoutWrite,
+		MessageKey: ptr("notifications.commentReply"),
+	}
+	require.NoError(t, f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, "pt-BR"))
+
+	require.Len(t, sender.multi, 2)
+	require.Equal(t, []string{"tablet"}, sender.multi[0].Tokens)
+	require.Equal(t, "Sua dúvida foi respondida", sender.multi[0].Notification.Title)
+	require.Equal(t, []string{"phone"}, sender.multi[1].Tokens)
+	require.Equal(t, "Tu pregunta fue respondida", sender.multi[1].Notification.Title)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// ---------- getLocaleColumns ----------
+
+// TestGetLocaleColumns verifies the recipient queries never reference a
+// locale column the database does not have.
+func TestGetLocaleColumns(t *testing.T) {
+	sender := &fakeSender{}
+	f, mock, cleanup := newTestFeature(t, sender)
+	defer cleanup()
+	f.localeCols = nil
+
+	// Only User.language exists; the probe runs once and is cached.
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM information_schema.columns`)).
+		WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name"}).AddRow("User", "language"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT un."userId", nd.token, COALESCE(NULL, ''), COALESCE((SELECT u."language"::text`)).
+		WithArgs("n1", string(TypeCommentReply)).
+		WillReturnRows(sqlmock.NewRows([]string{"userId", "token", "deviceLocale", "userLanguage"}))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
+		SET "recipientCount"`)).
+		WithArgs("n1", 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
+		SET status = 'sent'`)).
+		WithArgs("n1", 0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n := Notification{ID: "n1", TenantID: "t1", Type: TypeCommentReply, Fanout: FanoutWrite}
+	require.NoError(t, f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, ""))
+	cols, err := f.getLocaleColumns(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, localeColumns{userLanguage: true}, cols)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 // ---------- sendMulticast: empty recipients → marks sent 0/0 ----------
 
 func TestSendMulticast_NoRecipients_MarksSentZero(t *testing.T) {
@@ -189,7 +335,7 @@ func TestSendMulticast_NoRecipients_MarksSentZero(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT mod."memberId", nd.token`)).
 		WithArgs("d1", "t1", string(TypeAdminBroadcast)).
-		WillReturnRows(sqlmock.NewRows([]string{"userId", "token"}))
+		WillReturnRows(sqlmock.NewRows([]string{"userId", "token", "deviceLocale", "userLanguage"}))
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
 		SET "recipientCount"`)).
 		WithArgs("n2", 0).
@@ -199,7 +345,7 @@ func TestSendMulticast_NoRecipients_MarksSentZero(t *testing.T) {
 		WithArgs("n2", 0, 0).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
-	require.NoError(t, f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, "hi", "there"))
+	require.NoError(t, f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, ""))
 	require.Len(t, sender.multi, 0)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -217,9 +363,9 @@ func TestSendMulticast_ResumesAfterLastBatchIndex(t *testing.T) {
 
 	// Build a recipient list of 1200 tokens — 3 chunks of 500/500/200.
 	const total = 1200
-	rows := sqlmock.NewRows([]string{"userId", "token"})
+	rows := sqlmock.NewRows([]string{"userId", "token", "deviceLocale", "userLanguage"})
 	for i := range total {
-		rows.AddRow("u", "tok-"+string(rune('a'+i%26)))
+		rows.AddRow("u", "tok-"+string(rune('a'+i%26)), "", "")
 	}
 	at := string(AudienceDelivery)
 	aid := "d1"
@@ -258,7 +404,7 @@ func TestSendMulticast_ResumesAfterLastBatchIndex(t *testing.T) {
 		LastBatchIndex: &lbi,
 	}
 
-	require.NoError(t, f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, "hi", "there"))
+	require.NoError(t, f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, ""))
 
 	// Two batches should have been dispatched on this run, not three.
 	require.Len(t, sender.multi, 2)
@@ -291,8 +437,8 @@ func TestSendMulticast_AllFailed_ReturnsError(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(`SELECT mod."memberId", nd.token`)).
 		WithArgs("d1", "t1", string(TypeAdminBroadcast)).
-		WillReturnRows(sqlmock.NewRows([]string{"userId", "token"}).
-			AddRow("u1", "t1").AddRow("u2", "t2"))
+		WillReturnRows(sqlmock.NewRows([]string{"userId", "token", "deviceLocale", "userLanguage"}).
+			AddRow("u1", "t1", "", "").AddRow("u2", "t2", "", ""))
 	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "Notification"
 		SET "recipientCount"`)).
 		WithArgs("nFail", 2).
@@ -309,7 +455,7 @@ func TestSendMulticast_AllFailed_ReturnsError(t *testing.T) {
 		AudienceType: &at, AudienceID: &aid,
 	}
 
-	err := f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, "hi", "there")
+	err := f.sendMulticast(context.Background(), newDispatchLog(f.log, n), sender, n, "")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "all 2 FCM sends failed")
 	require.NoError(t, mock.ExpectationsWereMet())
@@ -363,4 +509,3 @@ func TestSelectFirebaseKey_BadJSON(t *testing.T) {
 	_, _, err := selectFirebaseKey("")
 	require.Error(t, err)
 }
-
@@ -0,0 +1,298 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ---------- DTOs ----------
+
+// answerRequest is the body of POST /api/v1/ai/answer. Scope, mode,
+// language and boostLanguage mean exactly what they mean for /search;
+// `limit` is how many chunks go into the prompt as context.
+type answerRequest struct {
+	TenantID      string      `json:"tenantId"`
+	Question      string      `json:"question"`
+	Scope         searchScope `json:"scope"`
+	Limit         int         `json:"limit"`
+	Mode          string      `json:"mode,omitempty"`
+	Language      string      `json:"language,omitempty"`
+	BoostLanguage string      `json:"boostLanguage,omitempty"`
+}
+
+// answerCitation points back at one chunk the answer relied on. Index is
+// the [n] marker used in the answer text. PDF citations carry PageNumber
+// instead of a video and times.
+type answerCitation struct {
+	Index      int     `json:"index"`
+	ChunkID    string  `json:"chunkId"`
+	LessonID   string  `json:"lessonId"`
+	CourseID   string  `json:"courseId,omitempty"`
+	VideoID    string  `json:"videoId"`
+	StartTime  float64 `json:"startTime"`
+	EndTime    float64 `json:"endTime"`
+	SourceType string  `json:"sourceType"`
+	PageNumber int     `json:"pageNumber,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+// answerResponse always comes back 200 when retrieval worked: a refusal
+// is a normal outcome (Answered=false + Reason), not an error.
+type answerResponse struct {
+	TenantID  string           `json:"tenantId"`
+	Question  string           `json:"question"`
+	Answered  bool             `json:"answered"`
+	Answer    string           `json:"answer,omitempty"`
+	Reason    string           `json:"reason,omitempty"`
+	Citations []answerCitation `json:"citations"`
+	Model     string           `json:"model,omitempty"`
+}
+
+const (
+	answerDefaultContextChunks = 6
+
+	// defaultAnswerMinSimilarity: below this cosine similarity the best
+	// chunk is almost never on-topic for text-embedding-3-small, and the
+	// LLM would be answering from its own knowledge instead of the course.
+	// Override with TRANSCRIPTION_ANSWER_MIN_SIMILARITY.
+	defaultAnswerMinSimilarity = 0.3
+
+	// answerOutputTokensEstimate prices the reply for the budget check.
+	answerOutputTokensEstimate = 500
+
+	// answerNoAnswerToken is what the prompt asks the model to reply when
+	// the context does not contain the answer.
+	answerNoAnswerToken = "SEM_RESPOSTA"
+
+	answerRefusalNoContext = "Não encontrei conteúdo suficiente no curso para responder a essa pergunta."
+)
+
+// answerSystemPrompt pins the model to the retrieved excerpts. Portuguese
+// because students ask in Portuguese and the answer should match.
+const answerSystemPrompt = `Você é um assistente que responde dúvidas de alunos usando APENAS os trechos de aulas fornecidos.
+Regras:
+- Responda em português, de forma direta.
+- Cite os trechos usados com o número entre colchetes, por exemplo [1] ou [2][3].
+- Não use conhecimento externo aos trechos.
+- Se os trechos não contiverem a resposta, responda exatamente ` + answerNoAnswerToken + `.`
+
+// ---------- 1. HTTP handler ----------
+
+// Answer handles `POST /api/v1/ai/answer`.
+//
+// Retrieves chunks through searchChunks (same scope, mode and language
+// rules as /search), drops the ones under the similarity threshold, and asks the
+// chat model for an answer grounded on what is left. Refuses without
+// calling the model when nothing clears the threshold.
+func (f *Feature) Answer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	limitBody(w, r)
+	var req answerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.TenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	if strings.TrimSpace(req.Question) == "" {
+		writeCustomError(w, http.StatusBadRequest, "question é obrigatório", "MISSING_QUESTION")
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = answerDefaultContextChunks
+	}
+	search := searchRequest{
+		TenantID:      req.TenantID,
+		Query:         req.Question,
+		Scope:         req.Scope,
+		Limit:         req.Limit,
+		Mode:          req.Mode,
+		Language:      req.Language,
+		BoostLanguage: req.BoostLanguage,
+	}
+	if msg, code := normalizeSearchRequest(&search); code != "" {
+		writeCustomError(w, http.StatusBadRequest, msg, code)
+		return
+	}
+
+	resp, status, err := f.answerQuestion(r.Context(), search)
+	if err != nil {
+		writeError(w, status, http.StatusText(status), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ---------- 2. Business rule ----------
+
+// answerQuestion answers req.Query from the chunks req retrieves. req has
+// been through normalizeSearchRequest.
+func (f *Feature) answerQuestion(ctx context.Context, req searchRequest) (*answerResponse, int, error) {
+	found, status, err := f.searchChunks(ctx, req)
+	if err != nil {
+		return nil, status, err
+	}
+
+	resp := &answerResponse{
+		TenantID:  req.TenantID,
+		Question:  req.Query,
+		Citations: make([]answerCitation, 0),
+	}
+
+	// deps.go applies defaultAnswerMinSimilarity when the variable is
+	// unset; 0 here means the threshold was turned off on purpose.
+	grounding := make([]searchHit, 0, len(found.Hits))
+	for _, h := range found.Hits {
+		if h.Similarity >= f.answerMinSimilarity {
+			grounding = append(grounding, h)
+		}
+	}
+	if len(grounding) == 0 {
+		resp.Reason = answerRefusalNoContext
+		return resp, http.StatusOK, nil
+	}
+
+	chat := f.chatClient()
+	messages := buildAnswerMessages(req.Query, grounding)
+	promptTokens := 0
+	for _, m := range messages {
+		promptTokens += countModelTokens(chat.Model(), m.Content)
+	}
+	inEst, outEst := chatCostCents(chat.Model(), promptTokens, answerOutputTokensEstimate)
+	if err := f.checkJobBudget(ctx, req.TenantID, "", inEst+outEst); err != nil {
+		if errors.Is(err, errBudgetExceeded) {
+			return nil, http.StatusPaymentRequired, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	completion, err := chat.Complete(ctx, messages)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("chat completion: %w", err)
+	}
+	resp.Model = completion.Model
+	f.recordAnswerUsage(ctx, req, completion, len(grounding))
+
+	text := strings.TrimSpace(completion.Content)
+	if text == "" || strings.Contains(text, answerNoAnswerToken) {
+		resp.Reason = answerRefusalNoContext
+		return resp, http.StatusOK, nil
+	}
+
+	resp.Answered = true
+	resp.Answer = text
+	cited := parseCitationIndexes(text, len(grounding))
+	if len(cited) == 0 {
+		// The model answered without markers. Every chunk in the prompt
+		// is a candidate source, so surface them all rather than none.
+		for i := range grounding {
+			cited = append(cited, i+1)
+		}
+	}
+	for _, n := range cited {
+		h := grounding[n-1]
+		resp.Citations = append(resp.Citations, answerCitation{
+			Index:      n,
+			ChunkID:    h.ChunkID,
+			LessonID:   h.LessonID,
+			CourseID:   h.CourseID,
+			VideoID:    h.VideoID,
+			StartTime:  h.StartTime,
+			EndTime:    h.EndTime,
+			SourceType: h.SourceType,
+			PageNumber: h.PageNumber,
+			Similarity: h.Similarity,
+		})
+	}
+	return resp, http.StatusOK, nil
+}
+
+// buildAnswerMessages numbers the context chunks [1]..[n] in retrieval
+// order; the numbers are what the model cites and what
+// parseCitationIndexes maps back.
+func buildAnswerMessages(question string, hits []searchHit) []chatMessage {
+	var b strings.Builder
+	for i, h := range hits {
+		if h.PageNumber > 0 {
+			fmt.Fprintf(&b, "[%d] (aula %s, pág. %d)\n%s\n\n", i+1, h.LessonID, h.PageNumber, h.Text)
+			continue
+		}
+		fmt.Fprintf(&b, "[%d] (aula %s, %s–%s)\n%s\n\n",
+			i+1, h.LessonID, formatTimestamp(h.StartTime), formatTimestamp(h.EndTime), h.Text)
+	}
+	b.WriteString("Pergunta: ")
+	b.WriteString(question)
+	return []chatMessage{
+		{Role: "system", Content: answerSystemPrompt},
+		{Role: "user", Content: b.String()},
+	}
+}
+
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// parseCitationIndexes returns the distinct [n] markers in text, in order
+// of first appearance, ignoring numbers outside 1..max (hallucinated
+// sources).
+func parseCitationIndexes(text string, max int) []int {
+	seen := make(map[int]bool)
+	var out []int
+	for _, m := range citationMarker.FindAllStringSubmatch(text, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > max || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out = append(out, n)
+	}
+	return out
+}
+
+// formatTimestamp renders seconds as m:ss (or h:mm:ss) for the prompt.
+func formatTimestamp(secs float64) string {
+	t := int(secs)
+	h, m, s := t/3600, (t%3600)/60, t%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// recordAnswerUsage writes the completion's tokens to token_usage. Best
+// effort: the student already has an answer, so a failed insert is logged
+// instead of turning the response into an error.
+func (f *Feature) recordAnswerUsage(ctx context.Context, req searchRequest, c *chatCompletion, contextChunks int) {
+	inCents, outCents := chatCostCents(c.Model, c.PromptTokens, c.CompletionTokens)
+	meta, _ := json.Marshal(map[string]any{
+		"contextChunks": contextChunks,
+		"mode":          req.Mode,
+	})
+	if _, err := f.transcriptionDB.ExecContext(ctx, sqlInsertTokenUsage,
+		uuid.NewString(), req.TenantID, nullableString(req.Scope.CourseID), nil, nil,
+		c.PromptTokens, c.CompletionTokens, c.PromptTokens+c.CompletionTokens,
+		inCents, outCents, inCents+outCents,
+		c.Model, "answer", meta,
+	); err != nil {
+		f.log.Error("transcription.answer.token_usage_failed", "tenant", req.TenantID, "error", err.Error())
+	}
+}
@@ -0,0 +1,202 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// fakeChat is the local stand-in for the LLM port.
+type fakeChat struct {
+	reply    string
+	calls    int
+	messages []chatMessage
+}
+
+func (c *fakeChat) Complete(ctx context.Context, messages []chatMessage) (*chatCompletion, error) {
+	c.calls++
+	c.messages = messages
+	return &chatCompletion{Content: c.reply, Model: "fake-chat", PromptTokens: 1000, CompletionTokens: 100}, nil
+}
+
+func (c *fakeChat) Model() string { return "fake-chat" }
+
+// newAnswerFeature is the shared fixture with chat as the answer model.
+func newAnswerFeature(t *testing.T, chat chatCompleter) (*Feature, sqlmock.Sqlmock) {
+	t.Helper()
+	fx := newTestFixture(t)
+	fx.f.chat = chat
+	fx.f.answerMinSimilarity = 0.5
+	return fx.f, fx.txMock
+}
+
+func postAnswer(f *Feature, req answerRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	r := httptest.NewRequest(http.MethodPost, "/answer", bytes.NewReader(body))
+	r.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.Answer(w, r)
+	return w
+}
+
+var searchColumns = []string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}
+
+func TestAnswer_RefusesBelowThresholdWithoutCallingLLM(t *testing.T) {
+	chat := &fakeChat{reply: "não deveria ser chamado"}
+	f, mock := newAnswerFeature(t, chat)
+	mock.ExpectQuery(`FROM chunks`).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow("c1", "l1", nil, "v1", "assunto distante", 0.0, 10.0, "VIDEO", nil, 0.21))
+
+	w := postAnswer(f, answerRequest{TenantID: "t", Question: "qual a capital da França?"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp answerResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Answered || resp.Reason == "" || len(resp.Citations) != 0 {
+		t.Fatalf("expected refusal, got %+v", resp)
+	}
+	if chat.calls != 0 {
+		t.Fatalf("LLM called %d times on a refusal", chat.calls)
+	}
+}
+
+func TestAnswer_CitesChunksWithDeepLinks(t *testing.T) {
+	chat := &fakeChat{reply: "Use o cupom no checkout [2]. Depois confirme o pedido [1][2][9]."}
+	f, mock := newAnswerFeature(t, chat)
+	mock.ExpectQuery(`FROM chunks`).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow("c1", "l1", "course-1", "v1", "confirme o pedido", 60.0, 95.5, "VIDEO", nil, 0.81).
+			AddRow("c2", "l2", "course-1", "v2", "cupom no checkout", 125.0, 140.0, "VIDEO", nil, 0.74).
+			AddRow("c3", "l3", "course-1", "v3", "irrelevante", 0.0, 5.0, "VIDEO", nil, 0.12))
+	mock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t", nil, nil, nil, 1000, 100, 1100, 1, 1, 2, "fake-chat", "answer", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := postAnswer(f, answerRequest{TenantID: "t", Question: "como uso o cupom?"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp answerResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if !resp.Answered || resp.Model != "fake-chat" {
+		t.Fatalf("unexpected: %+v", resp)
+	}
+	// [9] is out of range and dropped; order follows first appearance.
+	if len(resp.Citations) != 2 || resp.Citations[0].ChunkID != "c2" || resp.Citations[1].ChunkID != "c1" {
+		t.Fatalf("citations = %+v", resp.Citations)
+	}
+	if c := resp.Citations[0]; c.LessonID != "l2" || c.StartTime != 125 || c.EndTime != 140 {
+		t.Fatalf("deep link lost: %+v", c)
+	}
+	// The below-threshold chunk must not reach the prompt.
+	if strings.Contains(chat.messages[1].Content, "irrelevante") {
+		t.Fatal("chunk under the threshold was sent to the LLM")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnswer_ModelRefusalIsNotAnAnswer(t *testing.T) {
+	chat := &fakeChat{reply: answerNoAnswerToken}
+	f, mock := newAnswerFeature(t, chat)
+	mock.ExpectQuery(`FROM chunks`).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow("c1", "l1", nil, "v1", "algo", 0.0, 10.0, "VIDEO", nil, 0.9))
+	mock.ExpectExec(`INSERT INTO token_usage`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := postAnswer(f, answerRequest{TenantID: "t", Question: "x?"})
+	var resp answerResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.Answered || resp.Answer != "" {
+		t.Fatalf("status=%d resp=%+v", w.Code, resp)
+	}
+}
+
+func TestAnswer_ZeroThresholdKeepsEveryChunk(t *testing.T) {
+	chat := &fakeChat{reply: "Resposta [1]."}
+	f, mock := newAnswerFeature(t, chat)
+	f.answerMinSimilarity = 0
+	mock.ExpectQuery(`FROM chunks`).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow("c1", "l1", nil, "v1", "pouco parecido", 0.0, 10.0, "VIDEO", nil, 0.05))
+	mock.ExpectExec(`INSERT INTO token_usage`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := postAnswer(f, answerRequest{TenantID: "t", Question: "x?"})
+	var resp answerResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || !resp.Answered || chat.calls != 1 {
+		t.Fatalf("status=%d calls=%d resp=%+v", w.Code, chat.calls, resp)
+	}
+}
+
+func TestAnswer_OverBudgetSkipsLLM(t *testing.T) {
+	chat := &fakeChat{reply: "não deveria ser chamado"}
+	f, mock := newAnswerFeature(t, chat)
+	f.budgets = budgetConfig{defaultCents: 100}
+	mock.ExpectQuery(`FROM chunks`).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow("c1", "l1", nil, "v1", "algo", 0.0, 10.0, "VIDEO", nil, 0.9))
+	mock.ExpectQuery(`FROM token_usage`).WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(100))
+	mock.ExpectQuery(`FROM jobs o`).WithArgs("t", "").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+
+	w := postAnswer(f, answerRequest{TenantID: "t", Question: "x?"})
+	if w.Code != http.StatusPaymentRequired || chat.calls != 0 {
+		t.Fatalf("status=%d calls=%d body=%s", w.Code, chat.calls, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnswer_AppliesSearchLanguageRules(t *testing.T) {
+	chat := &fakeChat{reply: "Resposta [1]."}
+	f, mock := newAnswerFeature(t, chat)
+	mock.ExpectQuery(`FROM chunks.*language = \$`).
+		WithArgs(sqlmock.AnyArg(), "t", "es", answerDefaultContextChunks).
+		WillReturnRows(sqlmock.NewRows(searchColumns).
+			AddRow("c1", "l1", nil, "v1", "algo", 0.0, 10.0, "VIDEO", nil, 0.9))
+	mock.ExpectExec(`INSERT INTO token_usage`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	w := postAnswer(f, answerRequest{TenantID: "t", Question: "x?", Language: "es-AR"})
+	if w.Code != http.StatusOK || chat.calls != 1 {
+		t.Fatalf("status=%d calls=%d body=%s", w.Code, chat.calls, w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	w = postAnswer(f, answerRequest{TenantID: "t", Question: "x?", BoostLanguage: "português!"})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_LANGUAGE") {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestOpenAIChat_ParsesCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer k" {
+			t.Fatalf("bad request: %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"model":"gpt-4o-mini-2024","choices":[{"message":{"role":"assistant","content":"oi [1]"}}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`))
+	}))
+	defer server.Close()
+
+	c := newOpenAIChat(server.URL, "k", "", server.Client())
+	got, err := c.Complete(context.Background(), []chatMessage{{Role: "user", Content: "oi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != "oi [1]" || got.Model != "gpt-4o-mini-2024" || got.PromptTokens != 12 || got.CompletionTokens != 3 {
+		t.Fatalf("unexpected: %+v", got)
+	}
+}
@@ -55,11 +55,7 @@ const hlsAllowedExtensions = "3gp,aac,avi,ac3,eac3,flac,mkv,m3u8,m4a,m4s,m4v,mpg
 // demuxer options that the file demuxer rejects, so they're gated on
 // isHTTPURL.
 func extractAudioMP3(ctx context.Context, input, outPath string) (string, error) {
-	args := []string{
-		"-y",
-		"-loglevel", "error",
-		"-protocol_whitelist", ffmpegProtocolWhitelist,
-	}
+	var inputArgs []string
 	if isHTTPURL(input) {
 		// Three flags work together to make Bunny's audio-only HLS
 		// variants playable on the alpine ffmpeg 8 build:
@@ -71,13 +67,40 @@ func extractAudioMP3(ctx context.Context, input, outPath string) (string, error)
 		//                              ("detected format mpegts extension none
 		//                              mismatches allowed extensions") which
 		//                              picky=true rejects.
-		args = append(args,
+		inputArgs = []string{
 			"-referer", bunnyIframeReferer,
 			"-allowed_extensions", hlsAllowedExtensions,
 			"-allowed_segment_extensions", hlsAllowedExtensions,
 			"-extension_picky", "0",
-		)
+		}
+	}
+	return runExtractAudio(ctx, input, outPath, inputArgs)
+}
+
+// extractDirectAudioMP3 is extractAudioMP3 for media URLs outside Bunny
+// (an MP4 or HLS file in object storage): no Bunny Referer, and the HLS
+// extension whitelist only when the URL is a playlist — the mp4 demuxer
+// rejects those options.
+func extractDirectAudioMP3(ctx context.Context, input, outPath string) (string, error) {
+	var inputArgs []string
+	if isHLSURL(input) {
+		inputArgs = []string{
+			"-allowed_extensions", hlsAllowedExtensions,
+			"-allowed_segment_extensions", hlsAllowedExtensions,
+		}
 	}
+	return runExtractAudio(ctx, input, outPath, inputArgs)
+}
+
+// runExtractAudio is the shared ffmpeg invocation; inputArgs go right
+// before `-i`.
+func runExtractAudio(ctx context.Context, input, outPath string, inputArgs []string) (string, error) {
+	args := []string{
+		"-y",
+		"-loglevel", "error",
+		"-protocol_whitelist", ffmpegProtocolWhitelist,
+	}
+	args = append(args, inputArgs...)
 	args = append(args,
 		"-i", input,
 		"-vn",
@@ -98,6 +121,14 @@ func isHTTPURL(s string) bool {
 	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
 }
 
+// isHLSURL reports whether a media URL points at an .m3u8 playlist.
+func isHLSURL(s string) bool {
+	if i := strings.IndexAny(s, "?#"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.HasSuffix(strings.ToLower(s), ".m3u8")
+}
+
 // splitAudioByDuration slices `src` into N parts of `segSeconds` seconds
 // each using ffmpeg's segment muxer. `-c copy` re-uses the existing MP3
 // frames, so the operation is fast (no re-encode). The output filenames
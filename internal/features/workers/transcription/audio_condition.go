@@ -0,0 +1,235 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Audio conditioning before Whisper. Whisper bills every second it is
+// sent, including silent intros, long pauses and dead air at the end of
+// a recording. Each part goes through two ffmpeg runs: a silencedetect
+// analysis, then one encode that cuts the long silences (aselect) and
+// normalizes loudness (loudnorm) so quiet recordings are recognised
+// better. The cuts are recorded in an audioRemap so the
+// segment timestamps Whisper returns still line up with the video.
+
+const (
+	// silenceNoiseDB is the level under which audio counts as silence.
+	silenceNoiseDB = -40
+	// silenceMinSeconds is the shortest silence worth cutting; shorter
+	// pauses are part of normal speech.
+	silenceMinSeconds = 2.0
+	// silencePadSeconds of each cut silence are kept on both sides so
+	// words fading in or out are not clipped and Whisper still sees a
+	// pause between sentences.
+	silencePadSeconds = 0.5
+	// loudnormFilter is EBU R128 single-pass normalization to a speech
+	// friendly -16 LUFS.
+	loudnormFilter = "loudnorm=I=-16:TP=-1.5:LRA=11"
+)
+
+// timeRange is a [Start, End) stretch of audio in seconds.
+type timeRange struct {
+	Start float64
+	End   float64
+}
+
+// audioSpan maps one kept stretch of the conditioned audio (starting at
+// Trimmed) back to where it sits in the source audio (Source).
+type audioSpan struct {
+	Trimmed float64
+	Source  float64
+	Length  float64
+}
+
+// audioRemap converts conditioned-audio timestamps back to the source
+// timeline. No spans means nothing was cut and times map 1:1.
+// SourceDuration is the length of the audio before trimming.
+type audioRemap struct {
+	Spans          []audioSpan
+	SourceDuration float64
+}
+
+var (
+	silenceStartRe   = regexp.MustCompile(`silence_start: (-?[0-9.]+)`)
+	silenceEndRe     = regexp.MustCompile(`silence_end: (-?[0-9.]+)`)
+	ffmpegDurationRe = regexp.MustCompile(`Duration: (\d+):(\d{2}):(\d{2}(?:\.\d+)?)`)
+)
+
+// conditionAudio writes the trimmed, loudness-normalized version of src
+// to outPath (mono 16 kHz MP3, like extractAudioMP3) and returns the
+// remap from outPath's timeline to src's.
+func conditionAudio(ctx context.Context, src, outPath string) (audioRemap, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-hide_banner",
+		"-nostats",
+		"-protocol_whitelist", ffmpegProtocolWhitelist,
+		"-i", src,
+		"-af", fmt.Sprintf("silencedetect=noise=%ddB:d=%g", silenceNoiseDB, silenceMinSeconds),
+		"-f", "null",
+		"-",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return audioRemap{}, fmt.Errorf("ffmpeg silencedetect: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	silences, duration := parseSilenceDetect(string(out))
+	kept := keptRanges(silences, duration, silencePadSeconds)
+	remap := buildAudioRemap(kept, duration)
+
+	filter := loudnormFilter
+	if len(remap.Spans) > 0 {
+		filter = "aselect='" + aselectExpr(kept) + "',asetpts=N/SR/TB," + loudnormFilter
+	}
+	cmd = exec.CommandContext(ctx, "ffmpeg",
+		"-y",
+		"-loglevel", "error",
+		"-protocol_whitelist", ffmpegProtocolWhitelist,
+		"-i", src,
+		"-af", filter,
+		"-ac", "1",
+		"-ar", "16000",
+		"-acodec", "libmp3lame",
+		"-ab", "64k",
+		outPath,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return audioRemap{}, fmt.Errorf("ffmpeg condition: %w (%s)", err, strings.TrimSpace(string(out)))
+	}
+	return remap, nil
+}
+
+// parseSilenceDetect reads ffmpeg's silencedetect log: the input
+// duration and every silence, the last one running to the end of the
+// input when it has no silence_end.
+func parseSilenceDetect(out string) ([]timeRange, float64) {
+	var duration float64
+	if m := ffmpegDurationRe.FindStringSubmatch(out); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		mi, _ := strconv.Atoi(m[2])
+		s, _ := strconv.ParseFloat(m[3], 64)
+		duration = float64(h*3600+mi*60) + s
+	}
+
+	var (
+		silences []timeRange
+		open     = -1.0
+	)
+	for _, line := range strings.Split(out, "\n") {
+		if m := silenceStartRe.FindStringSubmatch(line); m != nil {
+			open, _ = strconv.ParseFloat(m[1], 64)
+			open = max(open, 0)
+			continue
+		}
+		if m := silenceEndRe.FindStringSubmatch(line); m != nil && open >= 0 {
+			end, _ := strconv.ParseFloat(m[1], 64)
+			silences = append(silences, timeRange{Start: open, End: end})
+			open = -1
+		}
+	}
+	if open >= 0 && duration > open {
+		silences = append(silences, timeRange{Start: open, End: duration})
+	}
+	return silences, duration
+}
+
+// keptRanges is the complement of the silences within [0, duration),
+// keeping pad seconds of each silence next to speech; a silence at the
+// very start or end has no speech on its outer side and is cut up to the
+// edge. Returns nil — keep everything — when the duration is unknown or
+// nothing would be cut or left.
+func keptRanges(silences []timeRange, duration, pad float64) []timeRange {
+	if duration <= 0 || len(silences) == 0 {
+		return nil
+	}
+	var kept []timeRange
+	cursor := 0.0
+	for _, s := range silences {
+		cutStart, cutEnd := s.Start+pad, s.End-pad
+		if s.Start <= 0 {
+			cutStart = 0
+		}
+		if s.End >= duration {
+			cutEnd = duration
+		}
+		if cutEnd <= cutStart || cutStart < cursor {
+			continue
+		}
+		if cutStart > cursor {
+			kept = append(kept, timeRange{Start: cursor, End: cutStart})
+		}
+		cursor = cutEnd
+	}
+	if cursor < duration {
+		kept = append(kept, timeRange{Start: cursor, End: duration})
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	if len(kept) == 1 && kept[0].Start == 0 && kept[0].End == duration {
+		return nil
+	}
+	return kept
+}
+
+// buildAudioRemap lays the kept ranges end to end, which is what
+// aselect + asetpts produce.
+func buildAudioRemap(kept []timeRange, duration float64) audioRemap {
+	m := audioRemap{SourceDuration: duration}
+	trimmed := 0.0
+	for _, r := range kept {
+		m.Spans = append(m.Spans, audioSpan{Trimmed: trimmed, Source: r.Start, Length: r.End - r.Start})
+		trimmed += r.End - r.Start
+	}
+	return m
+}
+
+// aselectExpr is the aselect expression keeping only the given ranges.
+func aselectExpr(kept []timeRange) string {
+	terms := make([]string, len(kept))
+	for i, r := range kept {
+		terms[i] = fmt.Sprintf("between(t,%.3f,%.3f)", r.Start, r.End)
+	}
+	return strings.Join(terms, "+")
+}
+
+// toSource maps a conditioned-audio time to the source timeline. A time
+// right on a cut belongs to the span after it when it starts a segment
+// and to the span before it when it ends one, so no segment is stretched
+// over the silence that was removed.
+func (m audioRemap) toSource(t float64, segmentEnd bool) float64 {
+	if len(m.Spans) == 0 {
+		return t
+	}
+	i := sort.Search(len(m.Spans), func(i int) bool {
+		end := m.Spans[i].Trimmed + m.Spans[i].Length
+		if segmentEnd {
+			return end >= t
+		}
+		return end > t
+	})
+	if i == len(m.Spans) {
+		// Past the last span (encoder padding): extend it linearly.
+		i = len(m.Spans) - 1
+	}
+	s := m.Spans[i]
+	return s.Source + max(t-s.Trimmed, 0)
+}
+
+// apply rewrites resp's segment timestamps to the source timeline and
+// records the source length of the part, which is what the pipeline
+// offsets the next part by. resp.Duration stays the billed length.
+func (m audioRemap) apply(resp *whisperResponse) {
+	for i := range resp.Segments {
+		resp.Segments[i].Start = m.toSource(resp.Segments[i].Start, false)
+		resp.Segments[i].End = m.toSource(resp.Segments[i].End, true)
+	}
+	if m.SourceDuration > 0 {
+		resp.SourceDuration = m.SourceDuration
+	}
+}
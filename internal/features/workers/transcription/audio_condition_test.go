@@ -0,0 +1,81 @@
+package transcription
+
+import (
+	"reflect"
+	"testing"
+)
+
+const silenceDetectLog = `Input #0, mp3, from 'audio.mp3':
+  Duration: 00:01:40.00, start: 0.000000, bitrate: 64 kb/s
+[silencedetect @ 0x1] silence_start: 0
+[silencedetect @ 0x1] silence_end: 12 | silence_duration: 12
+[silencedetect @ 0x1] silence_start: 40
+[silencedetect @ 0x1] silence_end: 45 | silence_duration: 5
+[silencedetect @ 0x1] silence_start: 90
+`
+
+func TestParseSilenceDetect(t *testing.T) {
+	silences, duration := parseSilenceDetect(silenceDetectLog)
+	if duration != 100 {
+		t.Fatalf("duration = %v", duration)
+	}
+	want := []timeRange{{0, 12}, {40, 45}, {90, 100}}
+	if !reflect.DeepEqual(silences, want) {
+		t.Fatalf("silences = %+v, want %+v", silences, want)
+	}
+}
+
+func TestKeptRanges_PadsInnerSilencesAndCutsEdges(t *testing.T) {
+	kept := keptRanges([]timeRange{{0, 12}, {40, 45}, {90, 100}}, 100, 0.5)
+	want := []timeRange{{11.5, 40.5}, {44.5, 90.5}}
+	if !reflect.DeepEqual(kept, want) {
+		t.Fatalf("kept = %+v, want %+v", kept, want)
+	}
+	if got := aselectExpr(kept); got != "between(t,11.500,40.500)+between(t,44.500,90.500)" {
+		t.Fatalf("aselect = %q", got)
+	}
+
+	if kept := keptRanges(nil, 100, 0.5); kept != nil {
+		t.Fatalf("no silences: kept = %+v", kept)
+	}
+	if kept := keptRanges([]timeRange{{0, 100}}, 100, 0.5); kept != nil {
+		t.Fatalf("all silence: kept = %+v", kept)
+	}
+	if kept := keptRanges([]timeRange{{10, 20}}, 0, 0.5); kept != nil {
+		t.Fatalf("unknown duration: kept = %+v", kept)
+	}
+}
+
+func TestAudioRemap_MapsSegmentsBackToSource(t *testing.T) {
+	// Kept: [11.5, 40.5) then [44.5, 90.5) -> trimmed [0, 29) and [29, 75).
+	m := buildAudioRemap([]timeRange{{11.5, 40.5}, {44.5, 90.5}}, 100)
+	resp := &whisperResponse{
+		Duration: 75,
+		Segments: []whisperSegment{
+			{Start: 0, End: 10, Text: "a"},
+			{Start: 20, End: 29, Text: "b"},
+			{Start: 29, End: 30, Text: "c"},
+			{Start: 70, End: 76, Text: "d"},
+		},
+	}
+	m.apply(resp)
+	want := []whisperSegment{
+		{Start: 11.5, End: 21.5, Text: "a"},
+		{Start: 31.5, End: 40.5, Text: "b"},
+		{Start: 44.5, End: 45.5, Text: "c"},
+		{Start: 85.5, End: 91.5, Text: "d"},
+	}
+	if !reflect.DeepEqual(resp.Segments, want) {
+		t.Fatalf("segments = %+v, want %+v", resp.Segments, want)
+	}
+	if resp.Duration != 75 || resp.sourceLength() != 100 {
+		t.Fatalf("duration = %v, source = %v", resp.Duration, resp.sourceLength())
+	}
+
+	// No spans: identity, billed length is the source length.
+	plain := &whisperResponse{Duration: 30, Segments: []whisperSegment{{Start: 1, End: 2}}}
+	audioRemap{}.apply(plain)
+	if plain.Segments[0].Start != 1 || plain.sourceLength() != 30 {
+		t.Fatalf("identity remap changed %+v", plain)
+	}
+}
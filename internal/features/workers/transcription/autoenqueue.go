@@ -0,0 +1,223 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/memberclass-backend-golang/internal/domain/ports"
+)
+
+// defaultAutoEnqueueTenantLimit caps how many VIDEO_PROCESSING jobs one
+// tenant can have PENDING or RUNNING because of the auto-enqueue job. A
+// tenant that publishes a 300-lesson course gets it transcribed over
+// several runs instead of flooding the queue ahead of everyone else.
+// Override with TRANSCRIPTION_AUTO_ENQUEUE_TENANT_LIMIT.
+const defaultAutoEnqueueTenantLimit = 10
+
+// autoEnqueueJob is the ports.Job the scheduler runs to transcribe newly
+// published (or re-uploaded) video lessons without an admin click.
+type autoEnqueueJob struct {
+	f *Feature
+}
+
+func (j *autoEnqueueJob) Name() string { return "transcription-auto-enqueue" }
+
+func (j *autoEnqueueJob) Execute(ctx context.Context) error { return j.f.autoEnqueue(ctx) }
+
+// AutoEnqueueJob returns the scheduler job. Register it on jobs.Scheduler
+// with AutoEnqueueSchedule when that is non-empty.
+func (f *Feature) AutoEnqueueJob() ports.Job { return &autoEnqueueJob{f: f} }
+
+// AutoEnqueueSchedule is the cron spec (with seconds) from
+// TRANSCRIPTION_AUTO_ENQUEUE_SCHEDULE. Empty means auto-enqueue is off.
+func (f *Feature) AutoEnqueueSchedule() string { return f.autoEnqueueSchedule }
+
+// autoLesson is one published Bunny lesson as the auto-enqueue job sees it.
+type autoLesson struct {
+	ID        string
+	Name      string
+	MediaURL  string
+	CourseID  string
+	Completed bool
+}
+
+// autoEnqueue walks every aiEnabled tenant and enqueues its new or
+// changed video lessons. A lesson qualifies when no videos row has its
+// current mediaUrl (the UNIQUE (tenant_id, source_url) key) and no job was
+// already created for that lesson + URL, and it is either still
+// untranscribed or was transcribed from a different URL. A failure on one
+// tenant is logged and the run moves on.
+func (f *Feature) autoEnqueue(ctx context.Context) error {
+	if err := f.preflight(); err != nil {
+		return err
+	}
+	rows, err := f.memberclassDB.QueryContext(ctx, sqlSelectAutoEnqueueTenants)
+	if err != nil {
+		return fmt.Errorf("list aiEnabled tenants: %w", err)
+	}
+	type tenantRow struct{ ID, AccessKey string }
+	var tenants []tenantRow
+	for rows.Next() {
+		var t tenantRow
+		if err := rows.Scan(&t.ID, &t.AccessKey); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan tenant: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate tenants: %w", err)
+	}
+
+	total := 0
+	for _, t := range tenants {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		n, err := f.autoEnqueueTenant(ctx, t.ID, t.AccessKey)
+		switch {
+		case errors.Is(err, errBudgetExceeded):
+			f.log.Warn("transcription.auto_enqueue.over_budget", "tenant", t.ID, "reason", err.Error())
+		case err != nil:
+			f.log.Error("transcription.auto_enqueue.tenant_failed", "tenant", t.ID, "error", err.Error())
+		case n > 0:
+			f.log.Info("transcription.auto_enqueue.enqueued", "tenant", t.ID, "count", n)
+		}
+		total += n
+	}
+	f.log.Info("transcription.auto_enqueue.done", "tenants", len(tenants), "enqueued", total)
+	return nil
+}
+
+// autoEnqueueTenant enqueues up to the tenant's remaining room under the
+// per-tenant limit and returns how many jobs it created. accessKey is
+// empty for tenants without Bunny credentials; the budget estimate then
+// falls back to budgetFallbackLessonSeconds per lesson.
+func (f *Feature) autoEnqueueTenant(ctx context.Context, tenantID, accessKey string) (int, error) {
+	limit := f.autoEnqueueTenantLimit
+	if limit <= 0 {
+		limit = defaultAutoEnqueueTenantLimit
+	}
+	var queued int
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlCountTenantQueuedJobs, tenantID).Scan(&queued); err != nil {
+		return 0, fmt.Errorf("count queued jobs: %w", err)
+	}
+	room := limit - queued
+	if room <= 0 {
+		f.log.Info("transcription.auto_enqueue.throttled", "tenant", tenantID, "queued", queued, "limit", limit)
+		return 0, nil
+	}
+
+	lessons, err := f.listAutoLessons(ctx, tenantID)
+	if err != nil || len(lessons) == 0 {
+		return 0, err
+	}
+	candidates, err := f.selectAutoCandidates(ctx, tenantID, lessons)
+	if err != nil {
+		return 0, err
+	}
+	if len(candidates) > room {
+		candidates = candidates[:room]
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	batch := make([]budgetLesson, 0, len(candidates))
+	for _, l := range candidates {
+		batch = append(batch, budgetLesson{ID: l.ID, MediaURL: l.MediaURL})
+	}
+	estimates, err := f.checkBatchBudget(ctx, tenantID, accessKey, batch)
+	if err != nil {
+		return 0, err
+	}
+
+	enqueued := 0
+	for _, l := range candidates {
+		payload, err := json.Marshal(jobPayload{
+			LessonID:           l.ID,
+			TenantID:           tenantID,
+			VideoURL:           l.MediaURL,
+			CourseID:           l.CourseID,
+			Title:              l.Name,
+			EstimatedCostCents: estimates[l.ID],
+		})
+		if err != nil {
+			f.log.Error("transcription.auto_enqueue.marshal_failed", "lesson", l.ID, "error", err.Error())
+			continue
+		}
+		var jobID string
+		err = f.transcriptionDB.QueryRowContext(ctx, sqlInsertAutoJob,
+			uuid.NewString(), tenantID, 0, payload, 3, l.ID, l.MediaURL,
+		).Scan(&jobID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another replica's run (or an admin click) got there first.
+			continue
+		}
+		if err != nil {
+			f.log.Error("transcription.auto_enqueue.insert_failed",
+				"tenant", tenantID, "lesson", l.ID, "error", err.Error())
+			continue
+		}
+		enqueued++
+	}
+	return enqueued, nil
+}
+
+// listAutoLessons returns the tenant's published lessons whose mediaUrl
+// detectSource can transcribe; anything else is skipped silently.
+func (f *Feature) listAutoLessons(ctx context.Context, tenantID string) ([]autoLesson, error) {
+	rows, err := f.memberclassDB.QueryContext(ctx, sqlSelectAutoEnqueueLessons, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list video lessons: %w", err)
+	}
+	defer rows.Close()
+	var out []autoLesson
+	for rows.Next() {
+		var l autoLesson
+		if err := rows.Scan(&l.ID, &l.Name, &l.MediaURL, &l.CourseID, &l.Completed); err != nil {
+			return nil, fmt.Errorf("scan lesson: %w", err)
+		}
+		if _, _, err := f.detectSource(l.MediaURL); err != nil {
+			continue
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// selectAutoCandidates asks the transcription DB which of the tenant's
+// lessons need a job, keeping the course order of the input.
+func (f *Feature) selectAutoCandidates(ctx context.Context, tenantID string, lessons []autoLesson) ([]autoLesson, error) {
+	ids := make([]string, len(lessons))
+	urls := make([]string, len(lessons))
+	completed := make([]bool, len(lessons))
+	byID := make(map[string]autoLesson, len(lessons))
+	for i, l := range lessons {
+		ids[i], urls[i], completed[i] = l.ID, l.MediaURL, l.Completed
+		byID[l.ID] = l
+	}
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectAutoEnqueueCandidates,
+		tenantID, pq.Array(ids), pq.Array(urls), pq.Array(completed))
+	if err != nil {
+		return nil, fmt.Errorf("select candidates: %w", err)
+	}
+	defer rows.Close()
+	var out []autoLesson
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan candidate: %w", err)
+		}
+		if l, ok := byID[id]; ok {
+			out = append(out, l)
+		}
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,138 @@
+package transcription
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func newAutoEnqueueFeature(t *testing.T, limit int) (*Feature, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	t.Helper()
+	fx := newTestFixture(t)
+	fx.f.autoEnqueueTenantLimit = limit
+	return fx.f, fx.txMock, fx.mcMock
+}
+
+func TestAutoEnqueueJob_Name(t *testing.T) {
+	f := &Feature{autoEnqueueSchedule: "0 */15 * * * *"}
+	if got := f.AutoEnqueueJob().Name(); got != "transcription-auto-enqueue" {
+		t.Fatalf("Name() = %q", got)
+	}
+	if f.AutoEnqueueSchedule() != "0 */15 * * * *" {
+		t.Fatalf("schedule = %q", f.AutoEnqueueSchedule())
+	}
+}
+
+func TestAutoEnqueue_EnqueuesCandidatesUpToTenantRoom(t *testing.T) {
+	f, txMock, mcMock := newAutoEnqueueFeature(t, 3)
+
+	mcMock.ExpectQuery(`FROM "Tenant".*"aiEnabled" = true`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "bunnyLibraryApiKey"}).
+			AddRow("t1", "k1").
+			AddRow("t2", "k2"))
+
+	// t1: one job already queued → room for 2 of its 3 candidates.
+	txMock.ExpectQuery(`SELECT COUNT\(\*\).*FROM jobs`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mcMock.ExpectQuery(`FROM "Lesson" l`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "mediaUrl", "course_id", "transcription_completed"}).
+			AddRow("l1", "Aula 1", "https://iframe.mediadelivery.net/embed/1/a", "c1", true).
+			AddRow("l2", "Aula 2", "https://iframe.mediadelivery.net/embed/1/b", "c1", false).
+			AddRow("l3", "Aula 3", "https://iframe.mediadelivery.net/embed/1/c", "c1", false).
+			AddRow("l4", "Aula 4", "https://iframe.mediadelivery.net/embed/1/d", "c1", false))
+	txMock.ExpectQuery(`unnest\(\$2::text\[\], \$3::text\[\], \$4::bool\[\]\)`).
+		WithArgs("t1",
+			pq.Array([]string{"l1", "l2", "l3", "l4"}),
+			pq.Array([]string{
+				"https://iframe.mediadelivery.net/embed/1/a",
+				"https://iframe.mediadelivery.net/embed/1/b",
+				"https://iframe.mediadelivery.net/embed/1/c",
+				"https://iframe.mediadelivery.net/embed/1/d",
+			}),
+			pq.Array([]bool{true, false, false, false})).
+		WillReturnRows(sqlmock.NewRows([]string{"lesson_id"}).AddRow("l1").AddRow("l3").AddRow("l4"))
+	txMock.ExpectQuery(`INSERT INTO jobs.*NOT EXISTS.*FROM videos`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "l1", "https://iframe.mediadelivery.net/embed/1/a").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("j1"))
+	// Lost the race to another replica: no row, not an error.
+	txMock.ExpectQuery(`INSERT INTO jobs.*NOT EXISTS.*FROM videos`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "l3", "https://iframe.mediadelivery.net/embed/1/c").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// t2: already at its limit → skipped without listing lessons.
+	txMock.ExpectQuery(`SELECT COUNT\(\*\).*FROM jobs`).WithArgs("t2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	if err := f.autoEnqueue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mcMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoEnqueueTenant_NoCandidates(t *testing.T) {
+	f, txMock, mcMock := newAutoEnqueueFeature(t, 0)
+	txMock.ExpectQuery(`SELECT COUNT\(\*\).*FROM jobs`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mcMock.ExpectQuery(`FROM "Lesson" l`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "mediaUrl", "course_id", "transcription_completed"}).
+			AddRow("l1", "Aula 1", "https://iframe.mediadelivery.net/embed/1/a", "c1", true))
+	txMock.ExpectQuery(`unnest`).
+		WillReturnRows(sqlmock.NewRows([]string{"lesson_id"}))
+
+	n, err := f.autoEnqueueTenant(context.Background(), "t1", "k1")
+	if err != nil || n != 0 {
+		t.Fatalf("n = %d err = %v", n, err)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoEnqueue_TenantWithoutBunnyCredentials(t *testing.T) {
+	f, txMock, mcMock := newAutoEnqueueFeature(t, 10)
+	// A Bunny meta call would fail against this client, so the estimate
+	// has to come from the fallback duration without touching the network.
+	f.httpClient = nil
+	f.budgets = budgetConfig{defaultCents: 10000}
+
+	mcMock.ExpectQuery(`FROM "Tenant".*"aiEnabled" = true`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "bunnyLibraryApiKey"}).AddRow("t1", ""))
+	txMock.ExpectQuery(`SELECT COUNT\(\*\).*FROM jobs`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mcMock.ExpectQuery(`FROM "Lesson" l`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "mediaUrl", "course_id", "transcription_completed"}).
+			AddRow("l1", "Aula 1", "https://memberclass.nyc3.digitaloceanspaces.com/aulas/aula-1.mp4", "c1", false).
+			AddRow("l2", "Aula 2", "https://www.youtube.com/watch?v=abc", "c1", false))
+	// detectSource drops the YouTube lesson before the candidate query.
+	txMock.ExpectQuery(`unnest`).
+		WithArgs("t1",
+			pq.Array([]string{"l1"}),
+			pq.Array([]string{"https://memberclass.nyc3.digitaloceanspaces.com/aulas/aula-1.mp4"}),
+			pq.Array([]bool{false})).
+		WillReturnRows(sqlmock.NewRows([]string{"lesson_id"}).AddRow("l1"))
+	txMock.ExpectQuery(`FROM token_usage`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	txMock.ExpectQuery(`estimatedCostCents.*FROM jobs`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	txMock.ExpectQuery(`INSERT INTO jobs.*NOT EXISTS.*FROM videos`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "l1",
+			"https://memberclass.nyc3.digitaloceanspaces.com/aulas/aula-1.mp4").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("j1"))
+
+	if err := f.autoEnqueue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mcMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
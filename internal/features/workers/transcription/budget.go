@@ -0,0 +1,289 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/memberclass-backend-golang/internal/domain/ports"
+)
+
+const (
+	// budgetFallbackLessonSeconds is what a lesson is assumed to last when
+	// Bunny's meta call fails at enqueue time. Errs on the long side so a
+	// flaky Bunny never lets a batch slip under the cap.
+	budgetFallbackLessonSeconds = 30 * 60
+
+	// budgetEstimateTokensPerMinute approximates speech at ~150 words/min
+	// (~200 tokens) plus chunk overlap, for the embedding share of the
+	// estimate. Whisper dominates the cost by two orders of magnitude.
+	budgetEstimateTokensPerMinute = 220
+
+	// budgetMetaConcurrency bounds the parallel Bunny meta calls one
+	// enqueue makes to read video durations.
+	budgetMetaConcurrency = 8
+)
+
+// errBudgetExceeded is returned by the pipelines when the tenant's monthly
+// cap would be crossed. processOne parks the job instead of failing it.
+var errBudgetExceeded = errors.New("tenant monthly AI budget exceeded")
+
+// budgetConfig holds the monthly AI spend caps in cents. Zero means no
+// cap. Per-tenant entries override the default.
+type budgetConfig struct {
+	defaultCents int
+	tenants      map[string]int
+}
+
+// loadBudgetConfig reads the budget env vars. Like loadSTTConfig, bad
+// entries are logged and ignored rather than failing boot:
+//
+//   - TRANSCRIPTION_MONTHLY_BUDGET_CENTS        default cap for every tenant (0 = none)
+//   - TRANSCRIPTION_TENANT_MONTHLY_BUDGET_CENTS comma list of tenantId=cents overrides
+func loadBudgetConfig(log ports.Logger) budgetConfig {
+	cfg := budgetConfig{tenants: map[string]int{}}
+	if v := strings.TrimSpace(os.Getenv("TRANSCRIPTION_MONTHLY_BUDGET_CENTS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.defaultCents = n
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_MONTHLY_BUDGET_CENTS — no default cap", "value", v)
+		}
+	}
+	for _, pair := range strings.Split(os.Getenv("TRANSCRIPTION_TENANT_MONTHLY_BUDGET_CENTS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tenantID, cents, ok := strings.Cut(pair, "=")
+		tenantID = strings.TrimSpace(tenantID)
+		n, err := strconv.Atoi(strings.TrimSpace(cents))
+		if !ok || tenantID == "" || err != nil || n < 0 {
+			log.Warn("transcription: ignoring malformed TRANSCRIPTION_TENANT_MONTHLY_BUDGET_CENTS entry", "entry", pair)
+			continue
+		}
+		cfg.tenants[tenantID] = n
+	}
+	return cfg
+}
+
+// capFor returns the tenant's monthly cap in cents; 0 means unlimited.
+func (c budgetConfig) capFor(tenantID string) int {
+	if n, ok := c.tenants[tenantID]; ok {
+		return n
+	}
+	return c.defaultCents
+}
+
+// budgetExceededError carries the numbers behind a rejected enqueue so the
+// handler can show the admin how far over the batch is.
+type budgetExceededError struct {
+	CapCents       int
+	SpentCents     int
+	ReservedCents  int
+	EstimatedCents int
+}
+
+func (e *budgetExceededError) Error() string {
+	return fmt.Sprintf("orçamento mensal de IA excedido: limite %d¢, gasto %d¢, reservado %d¢, lote estimado %d¢",
+		e.CapCents, e.SpentCents, e.ReservedCents, e.EstimatedCents)
+}
+
+func (e *budgetExceededError) Unwrap() error { return errBudgetExceeded }
+
+// budgetErrorStatus maps a checkBatchBudget error to the enqueue status:
+// 402 for an over-budget batch, 500 when the spend lookup itself failed.
+func budgetErrorStatus(err error) int {
+	if errors.Is(err, errBudgetExceeded) {
+		return http.StatusPaymentRequired
+	}
+	return http.StatusInternalServerError
+}
+
+// budgetLesson is the part of a lesson row the estimate needs.
+type budgetLesson struct {
+	ID       string
+	MediaURL string
+}
+
+// estimateLessonCostCents prices one lesson of `seconds` on the tenant's
+// speech-to-text backend plus embedding.
+func estimateLessonCostCents(stt transcriber, seconds float64) int {
+	tokens := int(seconds / 60 * budgetEstimateTokensPerMinute)
+	return stt.CostCents(seconds) + embedCostCents(tokens)
+}
+
+// estimateBatch returns each lesson's estimated cost, keyed by lesson id.
+// Durations come from Bunny's video meta; direct URLs, tenants without a
+// Bunny access key and failed lookups fall back to
+// budgetFallbackLessonSeconds.
+func (f *Feature) estimateBatch(ctx context.Context, tenantID, accessKey string, lessons []budgetLesson) map[string]int {
+	stt := f.transcriberFor(tenantID)
+	out := make(map[string]int, len(lessons))
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, budgetMetaConcurrency)
+	)
+	for _, l := range lessons {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(l budgetLesson) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			seconds := float64(budgetFallbackLessonSeconds)
+			if libID, guid, err := guidFromEmbedURL(l.MediaURL); err == nil && accessKey != "" {
+				if meta, err := f.fetchBunnyVideoMeta(ctx, libID, guid, accessKey); err == nil && meta.Length > 0 {
+					seconds = meta.Length
+				} else if err != nil {
+					f.log.Warn("transcription.budget.meta_failed", "tenant", tenantID, "lesson", l.ID, "error", err.Error())
+				}
+			}
+			cents := estimateLessonCostCents(stt, seconds)
+			mu.Lock()
+			out[l.ID] = cents
+			mu.Unlock()
+		}(l)
+	}
+	wg.Wait()
+	return out
+}
+
+// monthSpendCents is what the tenant has spent this calendar month (UTC,
+// per token_usage.created_at) plus what its queued jobs are estimated to
+// spend.
+func (f *Feature) monthSpendCents(ctx context.Context, tenantID string) (spent, reserved int, err error) {
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTenantMonthSpend, tenantID).Scan(&spent); err != nil {
+		return 0, 0, fmt.Errorf("select month spend: %w", err)
+	}
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTenantReservedCents, tenantID).Scan(&reserved); err != nil {
+		return 0, 0, fmt.Errorf("select reserved spend: %w", err)
+	}
+	return spent, reserved, nil
+}
+
+// checkBatchBudget estimates a batch and rejects it when spend + queued +
+// batch would cross the tenant's cap. Returns per-lesson estimates (nil
+// when the tenant has no cap, so uncapped tenants skip the Bunny calls).
+func (f *Feature) checkBatchBudget(ctx context.Context, tenantID, accessKey string, lessons []budgetLesson) (map[string]int, error) {
+	limit := f.budgets.capFor(tenantID)
+	if limit <= 0 || len(lessons) == 0 {
+		return nil, nil
+	}
+	estimates := f.estimateBatch(ctx, tenantID, accessKey, lessons)
+	batch := 0
+	for _, c := range estimates {
+		batch += c
+	}
+	spent, reserved, err := f.monthSpendCents(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if spent+reserved+batch > limit {
+		return nil, &budgetExceededError{
+			CapCents:       limit,
+			SpentCents:     spent,
+			ReservedCents:  reserved,
+			EstimatedCents: batch,
+		}
+	}
+	return estimates, nil
+}
+
+// checkJobBudget is the worker-side guard, run before a job spends
+// anything. It counts actual spend plus the estimates of jobs already
+// running for the tenant. Queued jobs are left out, since the enqueue
+// check already accepted them. jobID is the calling job, which is not
+// counted against itself. Callers outside a job pass "".
+func (f *Feature) checkJobBudget(ctx context.Context, tenantID, jobID string, estimateCents int) error {
+	limit := f.budgets.capFor(tenantID)
+	if limit <= 0 {
+		return nil
+	}
+	var spent, inFlight int
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTenantMonthSpend, tenantID).Scan(&spent); err != nil {
+		return fmt.Errorf("select month spend: %w", err)
+	}
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTenantInFlightCents, tenantID, jobID).Scan(&inFlight); err != nil {
+		return fmt.Errorf("select in-flight spend: %w", err)
+	}
+	if !budgetFits(limit, spent+inFlight, estimateCents) {
+		return fmt.Errorf("%w: spent %d¢ + running %d¢ of %d¢ (job estimate %d¢)",
+			errBudgetExceeded, spent, inFlight, limit, estimateCents)
+	}
+	return nil
+}
+
+// budgetFits reports whether a job estimated at estimateCents may start
+// when committed cents of limit are already spent or reserved.
+func budgetFits(limit, committed, estimateCents int) bool {
+	return committed < limit && committed+estimateCents <= limit
+}
+
+// parkJob moves a RUNNING job to OVER_BUDGET without burning an attempt.
+func (f *Feature) parkJob(ctx context.Context, jobID, reason string) error {
+	if _, err := f.transcriptionDB.ExecContext(ctx, sqlParkJobOverBudget, jobID, reason); err != nil {
+		return fmt.Errorf("park job: %w", err)
+	}
+	return nil
+}
+
+// releaseParkedJobs re-queues the OVER_BUDGET jobs whose own estimate
+// fits what is left of their tenant's cap (a new month started, or the cap
+// was raised). The spend, queued and running reservations, and jobs
+// already released in this pass all count, so a job that would only park
+// again stays parked. Runs on the orphan-reset ticker.
+func (f *Feature) releaseParkedJobs(ctx context.Context) (int, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectParkedJobs)
+	if err != nil {
+		return 0, fmt.Errorf("select parked jobs: %w", err)
+	}
+	type parkedJob struct {
+		id, tenantID  string
+		estimateCents int
+	}
+	var parked []parkedJob
+	for rows.Next() {
+		var j parkedJob
+		if err := rows.Scan(&j.id, &j.tenantID, &j.estimateCents); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan parked job: %w", err)
+		}
+		parked = append(parked, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	released := 0
+	committed := map[string]int{}
+	for _, j := range parked {
+		limit := f.budgets.capFor(j.tenantID)
+		if limit > 0 {
+			if _, ok := committed[j.tenantID]; !ok {
+				spent, reserved, err := f.monthSpendCents(ctx, j.tenantID)
+				if err != nil {
+					return released, err
+				}
+				committed[j.tenantID] = spent + reserved
+			}
+			if !budgetFits(limit, committed[j.tenantID], j.estimateCents) {
+				continue
+			}
+		}
+		res, err := f.transcriptionDB.ExecContext(ctx, sqlReleaseParkedJob, j.id)
+		if err != nil {
+			return released, fmt.Errorf("release parked job: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			released++
+			committed[j.tenantID] += j.estimateCents
+		}
+	}
+	return released, nil
+}
@@ -0,0 +1,230 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func TestLoadBudgetConfig(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_MONTHLY_BUDGET_CENTS", "5000")
+	t.Setenv("TRANSCRIPTION_TENANT_MONTHLY_BUDGET_CENTS", "big=100000, free=0, bad, neg=-1,=5")
+	cfg := loadBudgetConfig(logger.NewLogger())
+
+	cases := map[string]int{"big": 100000, "free": 0, "other": 5000, "neg": 5000}
+	for tenant, want := range cases {
+		if got := cfg.capFor(tenant); got != want {
+			t.Errorf("capFor(%q) = %d, want %d", tenant, got, want)
+		}
+	}
+}
+
+// newBunnyMetaServer answers every video meta call with the given length.
+func newBunnyMetaServer(t *testing.T, lengthSecs float64) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(bunnyVideoMeta{GUID: "g", Status: 4, Length: lengthSecs})
+	}))
+}
+
+func budgetProcessLessonsFixture(t *testing.T, capCents int, lengthSecs float64) (*Feature, sqlmock.Sqlmock, sqlmock.Sqlmock) {
+	t.Helper()
+	fx := newTestFixture(t)
+	f, txMock, mcMock := fx.f, fx.txMock, fx.mcMock
+
+	bunny := newBunnyMetaServer(t, lengthSecs)
+	t.Cleanup(bunny.Close)
+	f.bunnyBaseURL = bunny.URL
+	f.httpClient = bunny.Client()
+	f.budgets = budgetConfig{defaultCents: capCents}
+
+	mcMock.ExpectQuery(`FROM "Tenant"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t", "T", true, "lib", "key"))
+	mcMock.ExpectQuery(`FROM "Lesson"`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "name", "slug", "type", "mediaUrl", "thumbnail", "content",
+			"module_id", "module_name", "section_id", "section_name",
+			"course_id", "course_name", "vitrine_id", "vitrine_name",
+		}).
+			AddRow("l1", "Aula 1", "aula-1", nil, "https://iframe.mediadelivery.net/embed/lib/g1", nil, nil,
+				"m1", "Mod", "s1", "Sec", "c1", "Curso", "v1", "Vit").
+			AddRow("l2", "Aula 2", "aula-2", nil, "https://iframe.mediadelivery.net/embed/lib/g2", nil, nil,
+				"m1", "Mod", "s1", "Sec", "c1", "Curso", "v1", "Vit"))
+	return f, txMock, mcMock
+}
+
+func postProcessLessons(f *Feature) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(processLessonsRequest{TenantID: "t"})
+	req := httptest.NewRequest(http.MethodPost, "/tenants/process-lessons", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.ProcessLessonsTenant(w, req)
+	return w
+}
+
+func TestProcessLessonsTenant_RejectsOverBudgetBatch(t *testing.T) {
+	// Two 1h lessons ≈ 2 × (36¢ Whisper + 1¢ embed) = 74¢.
+	f, txMock, _ := budgetProcessLessonsFixture(t, 100, 3600)
+	txMock.ExpectQuery(`FROM token_usage`).WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(20))
+	txMock.ExpectQuery(`estimatedCostCents.*FROM jobs`).WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(10))
+
+	w := postProcessLessons(f)
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("status = %d body=%s", w.Code, w.Body.String())
+	}
+	var body map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body["errorCode"] != "BUDGET_EXCEEDED" || body["estimatedCents"] != float64(74) {
+		t.Fatalf("body = %v", body)
+	}
+	// No INSERT INTO jobs was expected; any would have failed the mock.
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessLessonsTenant_UnderBudgetReservesEstimate(t *testing.T) {
+	// 10 min lessons: 6¢ Whisper + 1¢ embed each.
+	f, txMock, _ := budgetProcessLessonsFixture(t, 1000, 600)
+	txMock.ExpectQuery(`FROM token_usage`).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	txMock.ExpectQuery(`estimatedCostCents.*FROM jobs`).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	for i := 0; i < 2; i++ {
+		txMock.ExpectExec(`INSERT INTO jobs`).
+			WithArgs(sqlmock.AnyArg(), "t", 0, payloadWithEstimate{7}, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	if w := postProcessLessons(f); w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d body=%s", w.Code, w.Body.String())
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// payloadWithEstimate matches a jobs.payload carrying the given estimate.
+type payloadWithEstimate struct{ cents int }
+
+func (m payloadWithEstimate) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var p jobPayload
+	return json.Unmarshal(b, &p) == nil && p.EstimatedCostCents == m.cents
+}
+
+func TestProcessOne_ParksJobOverBudget(t *testing.T) {
+	fx := newTestFixture(t)
+	f, txMock, mcMock := fx.f, fx.txMock, fx.mcMock
+	f.budgets = budgetConfig{tenants: map[string]int{"t": 500}}
+
+	mcMock.ExpectQuery(`FROM "Tenant"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t", "T", true, "lib", "key"))
+	txMock.ExpectQuery(`FROM token_usage`).WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(480))
+	txMock.ExpectQuery(`FROM jobs o`).WithArgs("t", "j1").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	txMock.ExpectExec(`UPDATE jobs.*OVER_BUDGET.*attempts - 1`).
+		WithArgs("j1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(jobPayload{
+		LessonID: "l", TenantID: "t",
+		VideoURL:           "https://iframe.mediadelivery.net/embed/lib/guid",
+		EstimatedCostCents: 37,
+	})
+	f.processOne(context.Background(), claimedJob{ID: "j1", TenantID: "t", Payload: payload, Type: JobTypeVideoProcessing})
+
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckJobBudget(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, budgets: budgetConfig{defaultCents: 100}}
+	spend := func(spent, running int) {
+		mock.ExpectQuery(`FROM token_usage`).WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(spent))
+		mock.ExpectQuery(`FROM jobs o.*o.status = 'RUNNING'`).WithArgs("t", "j1").
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(running))
+	}
+
+	if err := (&Feature{}).checkJobBudget(context.Background(), "t", "j1", 1e6); err != nil {
+		t.Fatalf("uncapped tenant rejected: %v", err)
+	}
+
+	spend(60, 0)
+	if err := f.checkJobBudget(context.Background(), "t", "j1", 40); err != nil {
+		t.Fatalf("spend exactly at cap after job rejected: %v", err)
+	}
+	spend(100, 0)
+	if err := f.checkJobBudget(context.Background(), "t", "j1", 0); !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("err = %v, want errBudgetExceeded", err)
+	}
+	// Another worker's running job already holds 30¢ of the 40¢ left.
+	spend(60, 30)
+	if err := f.checkJobBudget(context.Background(), "t", "j1", 20); !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("in-flight reservation ignored: err = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReleaseParkedJobs_EachAgainstItsOwnEstimate(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, budgets: budgetConfig{
+		defaultCents: 100,
+		tenants:      map[string]int{"uncapped": 0},
+	}}
+
+	mock.ExpectQuery(`FROM jobs\s+WHERE status = 'OVER_BUDGET'`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "estimate"}).
+			AddRow("a1", "still-over", 10).
+			AddRow("b1", "new-month", 50).
+			AddRow("b2", "new-month", 45).
+			AddRow("b3", "new-month", 20).
+			AddRow("c1", "uncapped", 500))
+	mock.ExpectQuery(`FROM token_usage`).WithArgs("still-over").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(150))
+	mock.ExpectQuery(`status IN \('PENDING', 'RUNNING'\)`).WithArgs("still-over").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(`FROM token_usage`).WithArgs("new-month").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(`status IN \('PENDING', 'RUNNING'\)`).WithArgs("new-month").
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(10))
+	// 10 reserved + b1 50 = 60; b2 would make 105 and stays parked; b3 fits.
+	mock.ExpectExec(`UPDATE jobs.*SET status\s+= 'PENDING'.*OVER_BUDGET`).WithArgs("b1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE jobs.*SET status\s+= 'PENDING'.*OVER_BUDGET`).WithArgs("b3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE jobs.*SET status\s+= 'PENDING'.*OVER_BUDGET`).WithArgs("c1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := f.releaseParkedJobs(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("released = %d, err = %v", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sqlCancelJob, "OVER_BUDGET") {
+		t.Fatal("parked jobs must stay cancellable")
+	}
+}
@@ -0,0 +1,171 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/memberclass-backend-golang/internal/domain/dto"
+)
+
+const (
+	captionFormatVTT = "vtt"
+	captionFormatSRT = "srt"
+)
+
+// ---------- 1. HTTP handler ----------
+
+// GetLessonCaptions handles `GET /api/v1/ai/lessons/{lessonId}/captions?tenantId=…&format=vtt|srt`.
+//
+// Renders the lesson's newest stored transcript as a caption file. format
+// defaults to vtt. 404 when the lesson has no transcript for the tenant.
+func (f *Feature) GetLessonCaptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	tenantID := r.URL.Query().Get("tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = captionFormatVTT
+	}
+	if format != captionFormatVTT && format != captionFormatSRT {
+		writeCustomError(w, http.StatusBadRequest, "format deve ser \"vtt\" ou \"srt\"", "INVALID_CAPTION_FORMAT")
+		return
+	}
+
+	segments, err := f.loadLessonSegments(r.Context(), tenantID, lessonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeCustomError(w, http.StatusNotFound, "Transcrição não encontrada para esta aula", "CAPTIONS_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		f.log.Error("transcription.captions.load_failed", "tenant", tenantID, "lesson", lessonID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	body, contentType := renderVTT(segments), "text/vtt; charset=utf-8"
+	if format == captionFormatSRT {
+		body, contentType = renderSRT(segments), "application/x-subrip; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, lessonID, format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(body))
+}
+
+// ---------- 2. Business rule ----------
+
+func (f *Feature) loadLessonSegments(ctx context.Context, tenantID, lessonID string) ([]whisperSegment, error) {
+	var raw []byte
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectLessonSegments, tenantID, lessonID).Scan(&raw); err != nil {
+		return nil, err
+	}
+	var segments []whisperSegment
+	if err := json.Unmarshal(raw, &segments); err != nil {
+		return nil, fmt.Errorf("decode segments: %w", err)
+	}
+	return segments, nil
+}
+
+// renderVTT writes one WebVTT cue per Whisper segment. Empty segments are
+// dropped; they would render as blank cues in the player.
+func renderVTT(segments []whisperSegment) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, s := range segments {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", captionTimestamp(s.Start, '.'), captionTimestamp(cueEnd(s), '.'), text)
+	}
+	return b.String()
+}
+
+// renderSRT is renderVTT's SubRip twin: numbered cues, comma before the
+// milliseconds, no header.
+func renderSRT(segments []whisperSegment) string {
+	var b strings.Builder
+	n := 0
+	for _, s := range segments {
+		text := strings.TrimSpace(s.Text)
+		if text == "" {
+			continue
+		}
+		n++
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", n, captionTimestamp(s.Start, ','), captionTimestamp(cueEnd(s), ','), text)
+	}
+	return b.String()
+}
+
+// cueEnd guards against zero-length (or inverted) segments, which some
+// players reject outright.
+func cueEnd(s whisperSegment) float64 {
+	if s.End <= s.Start {
+		return s.Start + 0.5
+	}
+	return s.End
+}
+
+// captionTimestamp renders seconds as HH:MM:SS<sep>mmm.
+func captionTimestamp(secs float64, sep byte) string {
+	if secs < 0 {
+		secs = 0
+	}
+	ms := int64(secs*1000 + 0.5)
+	h := ms / 3_600_000
+	m := (ms % 3_600_000) / 60_000
+	s := (ms % 60_000) / 1000
+	return fmt.Sprintf("%02d:%02d:%02d%c%03d", h, m, s, sep, ms%1000)
+}
+
+// ---------- 3. Bunny upload ----------
+
+// uploadCaptions pushes the transcript to the Bunny video as a WebVTT
+// track tagged with its language and reports whether the upload happened,
+// logging any failure instead of returning it because the transcript is
+// already committed and the job should still complete.
+func (f *Feature) uploadCaptions(ctx context.Context, libID, guid, accessKey, language string, segments []whisperSegment) bool {
+	if !f.captionsToBunny {
+		return false
+	}
+	if f.bunny == nil {
+		f.log.Warn("transcription.captions.bunny_not_configured", "guid", guid)
+		return false
+	}
+	err := f.bunny.AddCaption(ctx, dto.AddCaptionRequest{
+		GUID:         guid,
+		SrcLang:      language,
+		Label:        captionLabel(language),
+		CaptionsFile: []byte(renderVTT(segments)),
+	}, dto.BunnyParametersAccess{LibraryID: libID, LibraryApiKey: accessKey})
+	if err != nil {
+		f.log.Error("transcription.captions.upload_failed", "guid", guid, "libraryId", libID, "error", err.Error())
+		return false
+	}
+	return true
+}
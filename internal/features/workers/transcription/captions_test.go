@@ -0,0 +1,151 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/memberclass-backend-golang/internal/domain/dto"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+	"github.com/memberclass-backend-golang/internal/mocks"
+	"github.com/stretchr/testify/mock"
+)
+
+var captionSegments = []whisperSegment{
+	{Start: 0, End: 2.5, Text: " oi "},
+	{Start: 2.5, End: 2.5, Text: "mundo"},
+	{Start: 3, End: 4, Text: "   "},
+	{Start: 3725.04, End: 3727, Text: "fim"},
+}
+
+func TestRenderVTT(t *testing.T) {
+	want := "WEBVTT\n\n" +
+		"00:00:00.000 --> 00:00:02.500\noi\n\n" +
+		"00:00:02.500 --> 00:00:03.000\nmundo\n\n" +
+		"01:02:05.040 --> 01:02:07.000\nfim\n\n"
+	if got := renderVTT(captionSegments); got != want {
+		t.Fatalf("renderVTT:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestRenderSRT(t *testing.T) {
+	want := "1\n00:00:00,000 --> 00:00:02,500\noi\n\n" +
+		"2\n00:00:02,500 --> 00:00:03,000\nmundo\n\n" +
+		"3\n01:02:05,040 --> 01:02:07,000\nfim\n\n"
+	if got := renderSRT(captionSegments); got != want {
+		t.Fatalf("renderSRT:\n%q\nwant\n%q", got, want)
+	}
+}
+
+func getCaptions(t *testing.T, path string, expectQuery bool) (*httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	t.Helper()
+	_, mock, r := newJobControlRouter(t)
+	if expectQuery {
+		mock.ExpectQuery(`SELECT t.segments.*FROM transcripts`).
+			WithArgs("t1", "l1").
+			WillReturnRows(sqlmock.NewRows([]string{"segments"}).
+				AddRow([]byte(`[{"start":0,"end":1.2,"text":"oi"}]`)))
+	}
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w, mock
+}
+
+func TestGetLessonCaptions_DefaultsToVTT(t *testing.T) {
+	w, mock := getCaptions(t, "/lessons/l1/captions?tenantId=t1", true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/vtt") {
+		t.Fatalf("content-type = %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "00:00:00.000 --> 00:00:01.200\noi") {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetLessonCaptions_SRT(t *testing.T) {
+	w, _ := getCaptions(t, "/lessons/l1/captions?tenantId=t1&format=srt", true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/x-subrip") {
+		t.Fatalf("content-type = %q", ct)
+	}
+	if !strings.HasPrefix(w.Body.String(), "1\n00:00:00,000 --> 00:00:01,200\noi") {
+		t.Fatalf("body = %q", w.Body.String())
+	}
+}
+
+func TestGetLessonCaptions_Validation(t *testing.T) {
+	cases := map[string]struct {
+		path string
+		want int
+	}{
+		"missing tenant": {"/lessons/l1/captions", http.StatusBadRequest},
+		"bad format":     {"/lessons/l1/captions?tenantId=t1&format=txt", http.StatusBadRequest},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			w, _ := getCaptions(t, c.path, false)
+			if w.Code != c.want {
+				t.Fatalf("status = %d, want %d", w.Code, c.want)
+			}
+		})
+	}
+}
+
+func TestGetLessonCaptions_NotFound(t *testing.T) {
+	_, mock, r := newJobControlRouter(t)
+	mock.ExpectQuery(`SELECT t.segments`).WillReturnError(sql.ErrNoRows)
+	req := httptest.NewRequest(http.MethodGet, "/lessons/l1/captions?tenantId=t2", nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "CAPTIONS_NOT_FOUND") {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUploadCaptions(t *testing.T) {
+	access := dto.BunnyParametersAccess{LibraryID: "383534", LibraryApiKey: "key"}
+
+	t.Run("disabled", func(t *testing.T) {
+		bunny := mocks.NewMockBunnyService(t)
+		f := &Feature{log: logger.NewLogger(), bunny: bunny}
+		if f.uploadCaptions(context.Background(), "383534", "g1", "key", "pt", captionSegments) {
+			t.Fatal("upload reported with captionsToBunny=false")
+		}
+	})
+
+	t.Run("uploads vtt track", func(t *testing.T) {
+		bunny := mocks.NewMockBunnyService(t)
+		bunny.EXPECT().AddCaption(mock.Anything, mock.MatchedBy(func(req dto.AddCaptionRequest) bool {
+			return req.GUID == "g1" && req.SrcLang == "pt" && req.Label == "Português" &&
+				strings.HasPrefix(string(req.CaptionsFile), "WEBVTT")
+		}), access).Return(nil)
+		f := &Feature{log: logger.NewLogger(), bunny: bunny, captionsToBunny: true}
+		if !f.uploadCaptions(context.Background(), "383534", "g1", "key", "pt", captionSegments) {
+			t.Fatal("expected upload")
+		}
+	})
+
+	t.Run("bunny error is swallowed", func(t *testing.T) {
+		bunny := mocks.NewMockBunnyService(t)
+		bunny.EXPECT().AddCaption(mock.Anything, mock.Anything, access).Return(errors.New("401 Unauthorized"))
+		f := &Feature{log: logger.NewLogger(), bunny: bunny, captionsToBunny: true}
+		if f.uploadCaptions(context.Background(), "383534", "g1", "key", "pt", captionSegments) {
+			t.Fatal("failed upload reported as success")
+		}
+	})
+}
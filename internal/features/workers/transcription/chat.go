@@ -0,0 +1,143 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultChatModel answers "ask the course" questions. gpt-4o-mini is
+// plenty for grounded QA over a handful of transcript chunks; override
+// with TRANSCRIPTION_ANSWER_MODEL.
+const defaultChatModel = "gpt-4o-mini"
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCompletion is the part of a chat-completion response the slice
+// uses: the assistant text and the token counts we bill against.
+type chatCompletion struct {
+	Content          string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// chatCompleter is the slice's LLM port. The answer endpoint only talks
+// to this interface so tests (and local runs without OpenAI) can plug in
+// a fake.
+type chatCompleter interface {
+	Complete(ctx context.Context, messages []chatMessage) (*chatCompletion, error)
+	// Model is the configured model id, used to price the budget
+	// estimate before a call.
+	Model() string
+}
+
+// openAIChat is the chatCompleter adapter for /v1/chat/completions.
+type openAIChat struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+func newOpenAIChat(baseURL, apiKey, model string, client *http.Client) *openAIChat {
+	if model == "" {
+		model = defaultChatModel
+	}
+	return &openAIChat{baseURL: baseURL, apiKey: apiKey, model: model, httpClient: client}
+}
+
+func (c *openAIChat) Model() string { return c.model }
+
+type chatCompletionsResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage usage `json:"usage"`
+}
+
+// Complete runs a non-streaming completion at temperature 0 — answers are
+// supposed to restate the course material, not improvise.
+func (c *openAIChat) Complete(ctx context.Context, messages []chatMessage) (*chatCompletion, error) {
+	body, err := json.Marshal(map[string]any{
+		"model":       c.model,
+		"messages":    messages,
+		"temperature": 0,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal chat payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.baseURL+"/v1/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build chat request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai chat http: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("openai chat status=%d body=%s", resp.StatusCode, string(b))
+	}
+
+	var parsed chatCompletionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("openai chat decode: %w", err)
+	}
+	if len(parsed.Choices) == 0 {
+		return nil, fmt.Errorf("openai chat: no choices returned")
+	}
+	model := parsed.Model
+	if model == "" {
+		model = c.model
+	}
+	return &chatCompletion{
+		Content:          parsed.Choices[0].Message.Content,
+		Model:            model,
+		PromptTokens:     parsed.Usage.PromptTokens,
+		CompletionTokens: parsed.Usage.CompletionTokens,
+	}, nil
+}
+
+// chatClient returns the configured LLM, falling back to OpenAI built
+// from the Feature's own fields so hand-built Features (tests) work.
+func (f *Feature) chatClient() chatCompleter {
+	if f.chat != nil {
+		return f.chat
+	}
+	return newOpenAIChat(f.openaiBaseURL, f.openaiAPIKey, defaultChatModel, f.httpClient)
+}
+
+// chatClientFor returns a task's own LLM (summaryChat, quizChat) when it
+// is set and chatClient otherwise.
+func (f *Feature) chatClientFor(task chatCompleter) chatCompleter {
+	if task != nil {
+		return task
+	}
+	return f.chatClient()
+}
+
+// decodeModelJSON unmarshals the JSON object of a model reply into v,
+// tolerating a ```json fence or stray text around it.
+func decodeModelJSON(content string, v any) error {
+	content = strings.TrimSpace(content)
+	if i, j := strings.Index(content, "{"), strings.LastIndex(content, "}"); i >= 0 && j > i {
+		content = content[i : j+1]
+	}
+	return json.Unmarshal([]byte(content), v)
+}
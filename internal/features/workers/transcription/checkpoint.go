@@ -0,0 +1,194 @@
+package transcription
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+)
+
+// Checkpoint kinds stored in job_checkpoints.kind
+// (migrations/transcription/006_job_checkpoints.sql).
+const (
+	checkpointTranscriptPart = "transcript_part"
+	checkpointEmbedBatch     = "embed_batch"
+)
+
+// partCheckpoint is one audio part's speech-to-text response. Parts and
+// Model pin it to the split and backend that produced it: a retry that
+// splits differently or was routed to another backend starts over.
+type partCheckpoint struct {
+	Parts    int             `json:"parts"`
+	Model    string          `json:"model"`
+	Response whisperResponse `json:"response"`
+}
+
+// batchCheckpoint is one embeddings request. Key hashes the model, the
+// dimensions and the batch's texts, so a retry that chunks differently
+// never reuses vectors for the wrong text.
+type batchCheckpoint struct {
+	Key     string      `json:"key"`
+	Tokens  int         `json:"tokens"`
+	Vectors [][]float32 `json:"vectors"`
+}
+
+// jobCheckpoints holds what earlier attempts of a job already paid for,
+// so a retry after a late failure (embedding, commit) skips the Whisper
+// parts and embedding batches it has. Every method is a no-op on a nil
+// receiver, which is what callers without checkpointing pass.
+type jobCheckpoints struct {
+	f       *Feature
+	jobID   string
+	parts   map[int]partCheckpoint
+	batches map[int]batchCheckpoint
+}
+
+// loadCheckpoints reads the job's checkpoints. Best effort: on a read
+// error the job runs from scratch, which is what it did before.
+func (f *Feature) loadCheckpoints(ctx context.Context, jobID string) *jobCheckpoints {
+	cp := &jobCheckpoints{
+		f:       f,
+		jobID:   jobID,
+		parts:   make(map[int]partCheckpoint),
+		batches: make(map[int]batchCheckpoint),
+	}
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectJobCheckpoints, jobID)
+	if err != nil {
+		f.log.Warn("transcription.checkpoint.load_failed", "jobId", jobID, "error", err.Error())
+		return cp
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			kind string
+			idx  int
+			data []byte
+		)
+		if err := rows.Scan(&kind, &idx, &data); err != nil {
+			f.log.Warn("transcription.checkpoint.load_failed", "jobId", jobID, "error", err.Error())
+			return cp
+		}
+		switch kind {
+		case checkpointTranscriptPart:
+			var p partCheckpoint
+			if json.Unmarshal(data, &p) == nil {
+				cp.parts[idx] = p
+			}
+		case checkpointEmbedBatch:
+			var b batchCheckpoint
+			if json.Unmarshal(data, &b) == nil {
+				cp.batches[idx] = b
+			}
+		}
+	}
+	if len(cp.parts)+len(cp.batches) > 0 {
+		f.log.Info("transcription.checkpoint.resuming", "jobId", jobID,
+			"parts", len(cp.parts), "embedBatches", len(cp.batches))
+	}
+	return cp
+}
+
+// completeParts returns the part count when every part of the same split
+// and backend is checkpointed, in which case the audio never needs to be
+// downloaded again; 0 otherwise.
+func (cp *jobCheckpoints) completeParts(model string) int {
+	if cp == nil || len(cp.parts) == 0 {
+		return 0
+	}
+	n := cp.parts[0].Parts
+	if n <= 0 {
+		return 0
+	}
+	for i := 0; i < n; i++ {
+		p, ok := cp.parts[i]
+		if !ok || p.Parts != n || p.Model != model {
+			return 0
+		}
+	}
+	return n
+}
+
+func (cp *jobCheckpoints) part(i, parts int, model string) (*whisperResponse, bool) {
+	if cp == nil {
+		return nil, false
+	}
+	p, ok := cp.parts[i]
+	if !ok || p.Parts != parts || p.Model != model {
+		return nil, false
+	}
+	return &p.Response, true
+}
+
+func (cp *jobCheckpoints) savePart(ctx context.Context, i, parts int, model string, resp *whisperResponse) {
+	if cp == nil {
+		return
+	}
+	cp.save(ctx, checkpointTranscriptPart, i, partCheckpoint{Parts: parts, Model: model, Response: *resp})
+}
+
+func (cp *jobCheckpoints) batch(i int, key string) ([][]float32, int, bool) {
+	if cp == nil {
+		return nil, 0, false
+	}
+	b, ok := cp.batches[i]
+	if !ok || b.Key != key {
+		return nil, 0, false
+	}
+	return b.Vectors, b.Tokens, true
+}
+
+func (cp *jobCheckpoints) saveBatch(ctx context.Context, i int, key string, vecs [][]float32, tokens int) {
+	if cp == nil {
+		return
+	}
+	cp.save(ctx, checkpointEmbedBatch, i, batchCheckpoint{Key: key, Tokens: tokens, Vectors: vecs})
+}
+
+// save upserts one checkpoint. A failed write only means a later retry
+// pays for this step again, so it is logged, not returned.
+func (cp *jobCheckpoints) save(ctx context.Context, kind string, idx int, v any) {
+	data, err := json.Marshal(v)
+	if err == nil {
+		_, err = cp.f.transcriptionDB.ExecContext(ctx, sqlUpsertJobCheckpoint, cp.jobID, kind, idx, data)
+	}
+	if err != nil {
+		cp.f.log.Warn("transcription.checkpoint.save_failed",
+			"jobId", cp.jobID, "kind", kind, "index", idx, "error", err.Error())
+	}
+}
+
+// clear drops the job's checkpoints once it completed. Leftovers (failed
+// or cancelled jobs) are pruned by pruneCheckpoints.
+func (cp *jobCheckpoints) clear(ctx context.Context) {
+	if cp == nil {
+		return
+	}
+	if _, err := cp.f.transcriptionDB.ExecContext(ctx, sqlDeleteJobCheckpoints, cp.jobID); err != nil {
+		cp.f.log.Warn("transcription.checkpoint.clear_failed", "jobId", cp.jobID, "error", err.Error())
+	}
+}
+
+// pruneCheckpoints deletes checkpoints older than checkpointRetention.
+// Runs on the orphan ticker; a FAILED job retried within the window still
+// resumes.
+func (f *Feature) pruneCheckpoints(ctx context.Context) (int, error) {
+	res, err := f.transcriptionDB.ExecContext(ctx, sqlPruneJobCheckpoints, int(checkpointRetention.Seconds()))
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// embedBatchKey identifies an embeddings request by model, dimensions and
+// input texts.
+func embedBatchKey(dims int, texts []string) string {
+	h := sha256.New()
+	h.Write([]byte(embedModel + "\x00" + strconv.Itoa(dims)))
+	for _, t := range texts {
+		h.Write([]byte{0})
+		h.Write([]byte(t))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
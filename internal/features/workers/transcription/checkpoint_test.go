@@ -0,0 +1,152 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func expectNoCheckpoints(mock sqlmock.Sqlmock, jobID string) {
+	mock.ExpectQuery(`FROM job_checkpoints`).WithArgs(jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "idx", "data"}))
+}
+
+func expectSaveCheckpoint(mock sqlmock.Sqlmock, jobID, kind string, idx int) {
+	mock.ExpectExec(`INSERT INTO job_checkpoints`).
+		WithArgs(jobID, kind, idx, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestCompleteParts(t *testing.T) {
+	resp := whisperResponse{Text: "oi"}
+	cp := &jobCheckpoints{parts: map[int]partCheckpoint{
+		0: {Parts: 2, Model: "whisper-1", Response: resp},
+		1: {Parts: 2, Model: "whisper-1", Response: resp},
+	}}
+	if n := cp.completeParts("whisper-1"); n != 2 {
+		t.Fatalf("completeParts = %d, want 2", n)
+	}
+	if n := cp.completeParts("other-model"); n != 0 {
+		t.Fatalf("other backend reused checkpoints: %d", n)
+	}
+	delete(cp.parts, 1)
+	if n := cp.completeParts("whisper-1"); n != 0 {
+		t.Fatalf("partial set reported complete: %d", n)
+	}
+	if _, ok := cp.part(0, 3, "whisper-1"); ok {
+		t.Fatal("part from a different split was reused")
+	}
+	var nilCP *jobCheckpoints
+	if n := nilCP.completeParts("whisper-1"); n != 0 {
+		t.Fatal("nil checkpoints reported parts")
+	}
+}
+
+func TestEmbedBatchKey(t *testing.T) {
+	a := embedBatchKey(1536, []string{"ab", "c"})
+	if a != embedBatchKey(1536, []string{"ab", "c"}) {
+		t.Fatal("key is not stable")
+	}
+	if a == embedBatchKey(1536, []string{"a", "bc"}) || a == embedBatchKey(768, []string{"ab", "c"}) {
+		t.Fatal("key ignores text boundaries or dimensions")
+	}
+}
+
+// A retry whose parts and embed batch are all checkpointed never downloads
+// audio, calls the speech-to-text backend or hits the embeddings API — and
+// still records the full cost, since the failed attempt never did.
+func TestExecuteJob_ResumesFromCheckpoints(t *testing.T) {
+	transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer transcriptionDB.Close()
+	memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer memberclassDB.Close()
+
+	stub := &stubTranscriber{}
+	f := &Feature{
+		transcriptionDB: transcriptionDB,
+		memberclassDB:   memberclassDB,
+		log:             logger.NewLogger(),
+		openaiAPIKey:    "test-key",
+		openaiBaseURL:   "https://openai.invalid",
+		stt: sttConfig{
+			backends: map[string]transcriber{"stub": stub},
+			fallback: "stub",
+		},
+		testHookResolveAudio: func(context.Context, videoSource, string) ([]string, float64, error) {
+			t.Fatal("audio was downloaded again")
+			return nil, 0, nil
+		},
+	}
+
+	part := func(text string, secs float64) []byte {
+		b, _ := json.Marshal(partCheckpoint{Parts: 2, Model: "stub-whisper", Response: whisperResponse{
+			Text: text, Language: "pt", Duration: secs,
+			Segments: []whisperSegment{{Start: 0, End: secs, Text: text}},
+		}})
+		return b
+	}
+	// Segments "oi" (0–3) + "mundo" (3–5) chunk into one chunk.
+	chunks := splitIntoChunks([]whisperSegment{{Start: 0, End: 3, Text: "oi"}, {Start: 3, End: 5, Text: "mundo"}}, 500, 50)
+	batch, _ := json.Marshal(batchCheckpoint{
+		Key:     embedBatchKey(defaultEmbedDims, []string{chunks[0].Text}),
+		Tokens:  7,
+		Vectors: [][]float32{{0.1, 0.2, 0.3}},
+	})
+
+	mcMock.ExpectQuery(`FROM "Tenant"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t", "T", true, "383534", "k"))
+	txMock.ExpectQuery(`FROM job_checkpoints`).WithArgs("job-9").
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "idx", "data"}).
+			AddRow(checkpointTranscriptPart, 0, part("oi", 3)).
+			AddRow(checkpointTranscriptPart, 1, part("mundo", 2)).
+			AddRow(checkpointEmbedBatch, 0, batch))
+	mcMock.ExpectQuery(`SELECT "language"`).WillReturnRows(sqlmock.NewRows([]string{"language"}).AddRow("pt"))
+	expectProgress(txMock, "job-9",
+		progressStage{VideoStatusTranscribing, 20},
+		progressStage{VideoStatusTranscribing, 50},
+		progressStage{VideoStatusTranscribing, 80},
+		progressStage{VideoStatusChunking, 80},
+	)
+	expectEmbedCacheMiss(txMock)
+	expectEmbedCacheStore(txMock)
+	expectProgress(txMock, "job-9", progressStage{VideoStatusGeneratingEmbeddings, 95})
+	txMock.ExpectBegin()
+	txMock.ExpectQuery(`INSERT INTO videos`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("v1"))
+	txMock.ExpectExec(`DELETE FROM chunks`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`DELETE FROM transcripts`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`INSERT INTO transcripts`).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM chunks.*video_id <>`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`UPDATE videos`).WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t", nil, "v1", sqlmock.AnyArg(), 7, 0, 7, 1, 0, 1,
+			"stub-whisper+"+embedModel, "transcribe+embed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+	mcMock.ExpectExec(`UPDATE "Lesson"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`UPDATE jobs.*COMPLETED`).WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM job_checkpoints`).WithArgs("job-9").WillReturnResult(sqlmock.NewResult(0, 3))
+
+	payload, _ := json.Marshal(jobPayload{
+		LessonID: "l", TenantID: "t",
+		VideoURL: "https://iframe.mediadelivery.net/embed/383534/abc",
+	})
+	if err := f.executeJob(context.Background(), "job-9", "t", payload); err != nil {
+		t.Fatalf("executeJob: %v", err)
+	}
+	if stub.calls != 0 {
+		t.Fatalf("speech-to-text called %d times", stub.calls)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mcMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,13 +5,15 @@ import "strings"
 // chunk is the unit we persist to the chunks table. Order matches the
 // transcript order within a video; start/end carry the Whisper timestamps
 // for the FIRST and LAST segments that fed this chunk (so RAG citations
-// can deep-link into the video timeline).
+// can deep-link into the video timeline). PDF chunks set PageNumber
+// instead (see pdf.go).
 type chunk struct {
-	Order     int
-	Text      string
-	StartTime float64
-	EndTime   float64
-	Tokens    int
+	Order      int
+	Text       string
+	StartTime  float64
+	EndTime    float64
+	Tokens     int
+	PageNumber int
 }
 
 // splitIntoChunks groups Whisper segments into chunks of approximately
@@ -19,9 +21,8 @@ type chunk struct {
 // chunks. Splitting respects segment boundaries — we never cut inside a
 // Whisper segment, which keeps the start/end timestamps meaningful.
 //
-// Token count uses a cheap approximation: `wordCount * 1.3`. cl100k_base
-// runs ~1.3 tokens/word for Portuguese; if the over-estimate proves
-// problematic for cost we can swap in tiktoken-go.
+// Token counts come from countTokens, i.e. the embedding model's own BPE
+// (cl100k_base), so a 500-token chunk is 500 tokens to OpenAI too.
 //
 // Behavior on degenerate input:
 //   - len(segments) == 0 returns nil
@@ -47,7 +48,7 @@ func splitIntoChunks(segments []whisperSegment, maxTokens, overlap int) []chunk
 	// per segment.
 	segTokens := make([]int, len(segments))
 	for i, s := range segments {
-		segTokens[i] = approxTokens(s.Text)
+		segTokens[i] = countTokens(s.Text)
 	}
 
 	var out []chunk
@@ -103,9 +104,9 @@ func splitIntoChunks(segments []whisperSegment, maxTokens, overlap int) []chunk
 }
 
 // approxTokens estimates cl100k_base tokens for a string using word count
-// scaled by 1.3 (empirical average for Latin-script text). Deliberately
-// cheap; do NOT use this for billing — token counts in token_usage come
-// from the OpenAI response.
+// scaled by 1.3 (empirical average for Latin-script text). countTokens
+// falls back to it when the BPE ranks are not embedded. Do NOT use this
+// for billing — token counts in token_usage come from the OpenAI response.
 func approxTokens(s string) int {
 	words := len(strings.Fields(s))
 	return (words * 13) / 10
@@ -0,0 +1,555 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Transcript corrections. An admin fixes the text of individual Whisper
+// segments; only the chunks whose time range holds an edited segment are
+// re-chunked and re-embedded, and they are swapped in one transaction
+// together with the transcript and an edit-history row per segment.
+
+const (
+	maxSegmentEditsPerRequest = 500
+	maxTranscriptEditsListed  = 200
+
+	// chunkSpanEpsilon absorbs float rounding between the segment times in
+	// transcripts.segments and the chunk times stored from them.
+	chunkSpanEpsilon = 0.01
+)
+
+// errTranscriptChanged means the transcript or its chunks changed between
+// reading and writing a correction (a concurrent edit or a reprocess).
+var errTranscriptChanged = errors.New("transcrição alterada durante a edição; recarregue e tente novamente")
+
+// ---------- DTOs ----------
+
+// transcriptSegment is a Whisper segment addressed by its position, the
+// key edits refer to.
+type transcriptSegment struct {
+	Index int     `json:"index"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// lessonTranscriptResponse is the body of GET /lessons/{lessonId}/transcript.
+type lessonTranscriptResponse struct {
+	LessonID     string              `json:"lessonId"`
+	VideoID      string              `json:"videoId"`
+	TranscriptID string              `json:"transcriptId"`
+	Language     string              `json:"language,omitempty"`
+	UpdatedAt    time.Time           `json:"updatedAt"`
+	Segments     []transcriptSegment `json:"segments"`
+}
+
+// segmentEdit replaces the text of one segment.
+type segmentEdit struct {
+	Index int    `json:"index"`
+	Text  string `json:"text"`
+}
+
+// editTranscriptRequest is the body of PATCH /lessons/{lessonId}/transcript.
+type editTranscriptRequest struct {
+	TenantID string        `json:"tenantId"`
+	EditedBy string        `json:"editedBy,omitempty"`
+	Edits    []segmentEdit `json:"edits"`
+}
+
+// editTranscriptResponse reports what a correction touched.
+type editTranscriptResponse struct {
+	LessonID       string `json:"lessonId"`
+	VideoID        string `json:"videoId"`
+	TranscriptID   string `json:"transcriptId"`
+	SegmentsEdited int    `json:"segmentsEdited"`
+	ChunksReplaced int    `json:"chunksReplaced"`
+	ChunksInserted int    `json:"chunksInserted"`
+	CostCents      int    `json:"costCents"`
+}
+
+// transcriptEdit is one row of the correction history.
+type transcriptEdit struct {
+	ID           string    `json:"id"`
+	TranscriptID string    `json:"transcriptId"`
+	VideoID      string    `json:"videoId"`
+	SegmentIndex int       `json:"segmentIndex"`
+	StartTime    float64   `json:"startTime"`
+	EndTime      float64   `json:"endTime"`
+	OldText      string    `json:"oldText"`
+	NewText      string    `json:"newText"`
+	EditedBy     string    `json:"editedBy,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// lessonTranscript is the newest transcript of a lesson as loaded for
+// editing.
+type lessonTranscript struct {
+	ID        string
+	VideoID   string
+	Language  string
+	UpdatedAt time.Time
+	LessonID  string
+	CourseID  string
+	Segments  []whisperSegment
+}
+
+// chunkSpan is a stored chunk's position and time range.
+type chunkSpan struct {
+	ID        string
+	Order     int
+	StartTime float64
+	EndTime   float64
+}
+
+// ---------- 1. HTTP handlers ----------
+
+// GetLessonTranscript handles `GET /api/v1/ai/lessons/{lessonId}/transcript?tenantId=…`.
+//
+// Returns the newest transcript's segments with the index edits use.
+func (f *Feature) GetLessonTranscript(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	tenantID := r.URL.Query().Get("tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+
+	t, err := f.loadLessonTranscript(r.Context(), tenantID, lessonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeCustomError(w, http.StatusNotFound, "Transcrição não encontrada para esta aula", "TRANSCRIPT_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		f.log.Error("transcription.corrections.load_failed", "tenant", tenantID, "lesson", lessonID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	segments := make([]transcriptSegment, len(t.Segments))
+	for i, s := range t.Segments {
+		segments[i] = transcriptSegment{Index: i, Start: s.Start, End: s.End, Text: strings.TrimSpace(s.Text)}
+	}
+	writeJSON(w, http.StatusOK, lessonTranscriptResponse{
+		LessonID:     lessonID,
+		VideoID:      t.VideoID,
+		TranscriptID: t.ID,
+		Language:     t.Language,
+		UpdatedAt:    t.UpdatedAt,
+		Segments:     segments,
+	})
+}
+
+// EditLessonTranscript handles `PATCH /api/v1/ai/lessons/{lessonId}/transcript`.
+//
+// Body: { tenantId, editedBy?, edits: [{ index, text }] }
+//
+// Rewrites the edited segments, re-chunks and re-embeds only the chunks
+// covering them and records the edit history. Synchronous: a correction
+// touches a handful of chunks. 409 when the transcript changed meanwhile.
+func (f *Feature) EditLessonTranscript(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	limitBody(w, r)
+	var req editTranscriptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.TenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	if len(req.Edits) == 0 {
+		writeCustomError(w, http.StatusBadRequest, "edits é obrigatório", "MISSING_EDITS")
+		return
+	}
+	if len(req.Edits) > maxSegmentEditsPerRequest {
+		writeCustomError(w, http.StatusBadRequest,
+			fmt.Sprintf("edits excede o limite de %d por chamada", maxSegmentEditsPerRequest),
+			"TOO_MANY_EDITS")
+		return
+	}
+
+	resp, status, err := f.editLessonTranscript(r.Context(), req, lessonID)
+	switch {
+	case errors.Is(err, errBudgetExceeded):
+		writeCustomError(w, http.StatusPaymentRequired, err.Error(), "BUDGET_EXCEEDED")
+	case errors.Is(err, errTranscriptChanged):
+		writeCustomError(w, http.StatusConflict, err.Error(), "TRANSCRIPT_CHANGED")
+	case status == http.StatusBadRequest:
+		writeCustomError(w, status, err.Error(), "INVALID_EDIT")
+	case err != nil:
+		writeError(w, status, http.StatusText(status), err.Error())
+	default:
+		writeJSON(w, status, resp)
+	}
+}
+
+// ListTranscriptEdits handles `GET /api/v1/ai/lessons/{lessonId}/transcript/edits?tenantId=…`.
+//
+// The lesson's correction history, newest first (last 200 segments).
+func (f *Feature) ListTranscriptEdits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	tenantID := r.URL.Query().Get("tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+
+	edits, err := f.listTranscriptEdits(r.Context(), tenantID, lessonID)
+	if err != nil {
+		f.log.Error("transcription.corrections.list_failed", "tenant", tenantID, "lesson", lessonID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"lessonId": lessonID, "edits": edits})
+}
+
+// ---------- 2. Business rule ----------
+
+// editLessonTranscript applies the edits and swaps the affected chunks.
+func (f *Feature) editLessonTranscript(ctx context.Context, req editTranscriptRequest, lessonID string) (*editTranscriptResponse, int, error) {
+	if _, status, err := f.requireAITenant(ctx, req.TenantID); err != nil {
+		return nil, status, err
+	}
+
+	t, err := f.loadLessonTranscript(ctx, req.TenantID, lessonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, http.StatusNotFound, fmt.Errorf("aula sem transcrição armazenada")
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("carregar transcrição: %w", err)
+	}
+
+	edited, changed, err := applySegmentEdits(t.Segments, req.Edits)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	resp := &editTranscriptResponse{
+		LessonID:       lessonID,
+		VideoID:        t.VideoID,
+		TranscriptID:   t.ID,
+		SegmentsEdited: len(changed),
+	}
+	if len(changed) == 0 {
+		return resp, http.StatusOK, nil
+	}
+
+	spans, err := f.loadChunkSpans(ctx, t.VideoID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("listar chunks: %w", err)
+	}
+	replaced, fresh := rechunkEditedRanges(spans, edited, changed)
+
+	tokens := 0
+	for _, c := range fresh {
+		tokens += c.Tokens
+	}
+	if err := f.checkJobBudget(ctx, req.TenantID, "", embedCostCents(tokens)); err != nil {
+		if errors.Is(err, errBudgetExceeded) {
+			return nil, http.StatusPaymentRequired, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	var embedded embedResult
+	if len(fresh) > 0 {
+		if embedded, err = f.embedChunks(ctx, fresh, nil, nil); err != nil {
+			return nil, http.StatusBadGateway, err
+		}
+	}
+
+	if err := f.saveTranscriptEdits(ctx, req, t, edited, changed, replaced, fresh, embedded); err != nil {
+		if errors.Is(err, errTranscriptChanged) {
+			return nil, http.StatusConflict, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+	if len(fresh) > 0 || len(replaced) > 0 {
+		f.refreshLessonEmbedding(ctx, req.TenantID, t.LessonID)
+	}
+	resp.ChunksReplaced = len(replaced)
+	resp.ChunksInserted = len(fresh)
+	resp.CostCents = embedded.CostCents
+	return resp, http.StatusOK, nil
+}
+
+// applySegmentEdits returns a copy of segments with the edits applied and
+// the indexes whose text actually changed, in ascending order.
+func applySegmentEdits(segments []whisperSegment, edits []segmentEdit) ([]whisperSegment, []int, error) {
+	out := make([]whisperSegment, len(segments))
+	copy(out, segments)
+	seen := make(map[int]bool, len(edits))
+	var changed []int
+	for _, e := range edits {
+		if e.Index < 0 || e.Index >= len(segments) {
+			return nil, nil, fmt.Errorf("segmento %d não existe (a transcrição tem %d)", e.Index, len(segments))
+		}
+		if seen[e.Index] {
+			return nil, nil, fmt.Errorf("segmento %d editado mais de uma vez", e.Index)
+		}
+		seen[e.Index] = true
+		text := strings.TrimSpace(e.Text)
+		if text == "" {
+			return nil, nil, fmt.Errorf("segmento %d: texto vazio", e.Index)
+		}
+		if text == strings.TrimSpace(segments[e.Index].Text) {
+			continue
+		}
+		out[e.Index].Text = text
+		changed = append(changed, e.Index)
+	}
+	sort.Ints(changed)
+	return out, changed, nil
+}
+
+// rechunkEditedRanges picks the chunks whose time range holds an edited
+// segment, groups consecutive ones into runs and re-splits each run from
+// the segments it spans. It returns the IDs of the chunks to drop and the
+// chunks replacing them; a replacement's Order is provisional (the run's
+// first order onwards) until the video's chunks are renumbered.
+func rechunkEditedRanges(spans []chunkSpan, segments []whisperSegment, changed []int) ([]string, []chunk) {
+	affected := make([]bool, len(spans))
+	for _, i := range changed {
+		s := segments[i]
+		for j, c := range spans {
+			if s.Start >= c.StartTime-chunkSpanEpsilon && s.End <= c.EndTime+chunkSpanEpsilon {
+				affected[j] = true
+			}
+		}
+	}
+
+	var (
+		replaced []string
+		fresh    []chunk
+	)
+	for j := 0; j < len(spans); {
+		if !affected[j] {
+			j++
+			continue
+		}
+		first := j
+		for j < len(spans) && affected[j] {
+			replaced = append(replaced, spans[j].ID)
+			j++
+		}
+		start, end := spans[first].StartTime, spans[j-1].EndTime
+		var run []whisperSegment
+		for _, s := range segments {
+			if s.Start >= start-chunkSpanEpsilon && s.End <= end+chunkSpanEpsilon {
+				run = append(run, s)
+			}
+		}
+		for k, c := range splitIntoChunks(run, 500, 50) {
+			c.Order = spans[first].Order + k
+			fresh = append(fresh, c)
+		}
+	}
+	return replaced, fresh
+}
+
+// ---------- 3. SQL ----------
+
+func (f *Feature) loadLessonTranscript(ctx context.Context, tenantID, lessonID string) (*lessonTranscript, error) {
+	var (
+		t   lessonTranscript
+		raw []byte
+	)
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectLessonTranscriptForEdit, tenantID, lessonID).
+		Scan(&t.ID, &t.VideoID, &raw, &t.Language, &t.UpdatedAt, &t.LessonID, &t.CourseID); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &t.Segments); err != nil {
+		return nil, fmt.Errorf("decode segments: %w", err)
+	}
+	if t.LessonID == "" {
+		t.LessonID = lessonID
+	}
+	return &t, nil
+}
+
+func (f *Feature) loadChunkSpans(ctx context.Context, videoID string) ([]chunkSpan, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectVideoChunkSpans, videoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []chunkSpan
+	for rows.Next() {
+		var c chunkSpan
+		if err := rows.Scan(&c.ID, &c.Order, &c.StartTime, &c.EndTime); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// saveTranscriptEdits writes the corrected transcript, swaps the chunks,
+// renumbers them and records history and token usage in one transaction.
+func (f *Feature) saveTranscriptEdits(
+	ctx context.Context,
+	req editTranscriptRequest,
+	t *lessonTranscript,
+	edited []whisperSegment,
+	changed []int,
+	replaced []string,
+	fresh []chunk,
+	embedded embedResult,
+) error {
+	segmentsJSON, _ := json.Marshal(edited)
+	texts := make([]string, 0, len(edited))
+	for _, s := range edited {
+		if text := strings.TrimSpace(s.Text); text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	tx, err := f.transcriptionDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, sqlUpdateTranscriptSegments, t.ID, t.UpdatedAt, segmentsJSON, strings.Join(texts, " "))
+	if err != nil {
+		return fmt.Errorf("update transcript: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		return errTranscriptChanged
+	}
+
+	if len(replaced) > 0 {
+		res, err := tx.ExecContext(ctx, sqlDeleteChunksByIDs, t.VideoID, pq.Array(replaced))
+		if err != nil {
+			return fmt.Errorf("delete edited chunks: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n != int64(len(replaced)) {
+			return errTranscriptChanged
+		}
+	}
+	if len(fresh) > 0 {
+		if err := copyChunks(ctx, tx, fresh, embedded.Vectors, chunkRowKeys{
+			VideoID:      t.VideoID,
+			TranscriptID: t.ID,
+			TenantID:     req.TenantID,
+			CourseID:     t.CourseID,
+			LessonID:     t.LessonID,
+		}); err != nil {
+			return err
+		}
+	}
+	if len(replaced) != len(fresh) {
+		if _, err := tx.ExecContext(ctx, sqlRenumberVideoChunks, t.VideoID); err != nil {
+			return fmt.Errorf("renumber chunks: %w", err)
+		}
+	}
+
+	for _, i := range changed {
+		if _, err := tx.ExecContext(ctx, sqlInsertTranscriptEdit,
+			uuid.NewString(), req.TenantID, t.ID, t.VideoID, t.LessonID, i,
+			edited[i].Start, edited[i].End, strings.TrimSpace(t.Segments[i].Text), edited[i].Text,
+			nullableString(req.EditedBy),
+		); err != nil {
+			return fmt.Errorf("insert transcript edit: %w", err)
+		}
+	}
+
+	if len(fresh) > 0 {
+		tokenMeta, _ := json.Marshal(map[string]any{
+			"segmentsEdited":   len(changed),
+			"chunksReplaced":   len(replaced),
+			"chunks":           len(fresh),
+			"embedCacheHits":   embedded.CacheHits,
+			"embedCacheMisses": embedded.CacheMisses,
+		})
+		if _, err := tx.ExecContext(ctx, sqlInsertTokenUsage,
+			uuid.NewString(), req.TenantID, nullableString(t.CourseID), t.VideoID, t.ID,
+			embedded.Tokens, 0, embedded.Tokens, embedded.CostCents, 0, embedded.CostCents,
+			embedModel, "transcript_edit", tokenMeta,
+		); err != nil {
+			return fmt.Errorf("insert token_usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transcript edit tx: %w", err)
+	}
+	return nil
+}
+
+func (f *Feature) listTranscriptEdits(ctx context.Context, tenantID, lessonID string) ([]transcriptEdit, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectTranscriptEdits, tenantID, lessonID, maxTranscriptEditsListed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]transcriptEdit, 0)
+	for rows.Next() {
+		var e transcriptEdit
+		if err := rows.Scan(&e.ID, &e.TranscriptID, &e.VideoID, &e.SegmentIndex, &e.StartTime, &e.EndTime,
+			&e.OldText, &e.NewText, &e.EditedBy, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
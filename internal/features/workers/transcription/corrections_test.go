@@ -0,0 +1,205 @@
+package transcription
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+)
+
+var editSegments = []whisperSegment{
+	{Start: 0, End: 10, Text: " Bem-vindos ao member class."},
+	{Start: 10, End: 20, Text: " Hoje vamos falar de funis."},
+	{Start: 20, End: 30, Text: " O topo atrai."},
+	{Start: 30, End: 40, Text: " O fundo converte."},
+	{Start: 40, End: 50, Text: " Perguntas?"},
+	{Start: 50, End: 60, Text: " Até a próxima."},
+}
+
+func TestApplySegmentEdits(t *testing.T) {
+	got, changed, err := applySegmentEdits(editSegments, []segmentEdit{
+		{Index: 3, Text: " O fundo converte em vendas. "},
+		{Index: 0, Text: "Bem-vindos ao MemberClass."},
+		{Index: 4, Text: "Perguntas?"}, // same text: not an edit
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []int{0, 3}) {
+		t.Fatalf("changed = %v", changed)
+	}
+	if got[3].Text != "O fundo converte em vendas." || got[3].Start != 30 {
+		t.Fatalf("segment 3 = %+v", got[3])
+	}
+	if editSegments[0].Text != " Bem-vindos ao member class." {
+		t.Fatal("input segments were modified")
+	}
+
+	for _, edits := range [][]segmentEdit{
+		{{Index: 6, Text: "x"}},
+		{{Index: -1, Text: "x"}},
+		{{Index: 1, Text: " "}},
+		{{Index: 1, Text: "a"}, {Index: 1, Text: "b"}},
+	} {
+		if _, _, err := applySegmentEdits(editSegments, edits); err == nil {
+			t.Errorf("%+v: expected error", edits)
+		}
+	}
+}
+
+func TestRechunkEditedRanges_OnlyTouchesChunksCoveringEdits(t *testing.T) {
+	spans := []chunkSpan{
+		{ID: "c0", Order: 0, StartTime: 0, EndTime: 20},
+		{ID: "c1", Order: 1, StartTime: 10, EndTime: 40}, // overlaps c0 on segment 1
+		{ID: "c2", Order: 2, StartTime: 40, EndTime: 50},
+		{ID: "c3", Order: 3, StartTime: 50, EndTime: 60},
+	}
+	segments, changed, _ := applySegmentEdits(editSegments, []segmentEdit{
+		{Index: 1, Text: "Hoje vamos falar de funis de vendas."},
+		{Index: 5, Text: "Até a próxima aula."},
+	})
+
+	replaced, fresh := rechunkEditedRanges(spans, segments, changed)
+	// Segment 1 sits in both c0 and c1, so they are re-split together
+	// from segments 0–3; c2 is untouched.
+	if !reflect.DeepEqual(replaced, []string{"c0", "c1", "c3"}) {
+		t.Fatalf("replaced = %v", replaced)
+	}
+	if len(fresh) != 2 {
+		t.Fatalf("fresh = %+v", fresh)
+	}
+	if fresh[0].Order != 0 || fresh[0].StartTime != 0 || fresh[0].EndTime != 40 ||
+		!strings.Contains(fresh[0].Text, "funis de vendas.") || !strings.Contains(fresh[0].Text, "O fundo converte.") {
+		t.Fatalf("first run = %+v", fresh[0])
+	}
+	if fresh[1].Order != 3 || fresh[1].StartTime != 50 || fresh[1].Text != "Até a próxima aula." {
+		t.Fatalf("second run = %+v", fresh[1])
+	}
+}
+
+func expectTranscriptForEdit(fx *testFixture, updatedAt time.Time) {
+	txMock := fx.txMock
+	fx.expectAITenant(true)
+	segments, _ := json.Marshal(editSegments[:3])
+	txMock.ExpectQuery(`FROM transcripts t`).WithArgs("t1", "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "video_id", "segments", "language", "updated_at", "lesson_id", "course_id"}).
+			AddRow("tr1", "v1", segments, "pt", updatedAt, "l1", "c1"))
+	txMock.ExpectQuery(`FROM chunks`).WithArgs("v1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order", "start_time", "end_time"}).
+			AddRow("c0", 0, 0.0, 20.0).
+			AddRow("c1", 1, 20.0, 30.0))
+}
+
+func patchTranscript(r chi.Router, req editTranscriptRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPatch, "/lessons/l1/transcript", bytes.NewReader(body))
+	httpReq.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httpReq)
+	return w
+}
+
+func TestEditLessonTranscript_SwapsOnlyAffectedChunks(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	updatedAt := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	expectTranscriptForEdit(fx, updatedAt)
+	expectEmbedCacheMiss(txMock)
+	expectEmbedCacheStore(txMock)
+	txMock.ExpectBegin()
+	txMock.ExpectExec(`UPDATE transcripts`).
+		WithArgs("tr1", updatedAt, sqlmock.AnyArg(),
+			"Bem-vindos ao member class. Hoje vamos falar de funis. O topo atrai clientes.").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM chunks`).WithArgs("v1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`INSERT INTO transcript_edits`).
+		WithArgs(sqlmock.AnyArg(), "t1", "tr1", "v1", "l1", 2, 20.0, 30.0, "O topo atrai.", "O topo atrai clientes.", "admin@x").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t1", "c1", "v1", "tr1", 4, 0, 4, 1, 0, 1, embedModel, "transcript_edit", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+
+	w := patchTranscript(r, editTranscriptRequest{
+		TenantID: "t1",
+		EditedBy: "admin@x",
+		Edits:    []segmentEdit{{Index: 2, Text: "O topo atrai clientes."}},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var got editTranscriptResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.SegmentsEdited != 1 || got.ChunksReplaced != 1 || got.ChunksInserted != 1 {
+		t.Fatalf("response = %+v", got)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEditLessonTranscript_ConflictWhenTranscriptChanged(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	updatedAt := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	expectTranscriptForEdit(fx, updatedAt)
+	expectEmbedCacheMiss(txMock)
+	expectEmbedCacheStore(txMock)
+	txMock.ExpectBegin()
+	txMock.ExpectExec(`UPDATE transcripts`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectRollback()
+
+	w := patchTranscript(r, editTranscriptRequest{TenantID: "t1", Edits: []segmentEdit{{Index: 0, Text: "Bem-vindos ao MemberClass."}}})
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "TRANSCRIPT_CHANGED") {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEditLessonTranscript_RejectsBadSegmentIndex(t *testing.T) {
+	fx := newTestFixture(t)
+	r := fx.router(MiddlewareSet{})
+	expectTranscriptForEdit(fx, time.Now())
+
+	w := patchTranscript(r, editTranscriptRequest{TenantID: "t1", Edits: []segmentEdit{{Index: 9, Text: "x"}}})
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_EDIT") {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetLessonTranscript_IndexesSegments(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	segments, _ := json.Marshal(editSegments[:2])
+	txMock.ExpectQuery(`FROM transcripts t`).WithArgs("t1", "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "video_id", "segments", "language", "updated_at", "lesson_id", "course_id"}).
+			AddRow("tr1", "v1", segments, "pt", time.Now(), "l1", "c1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/lessons/l1/transcript?tenantId=t1", nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var got lessonTranscriptResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.TranscriptID != "tr1" || len(got.Segments) != 2 || got.Segments[1].Index != 1 || got.Segments[1].Text != "Hoje vamos falar de funis." {
+		t.Fatalf("response = %+v", got)
+	}
+}
@@ -1,6 +1,9 @@
 package transcription
 
-import "math"
+import (
+	"math"
+	"strings"
+)
 
 // OpenAI pricing as of 2026-05. Stored as cents-per-unit so the rounding
 // happens on the unit we report (integer cents). Update both constants and
@@ -15,6 +18,44 @@ const (
 	embedCentsPer1MTokens = 2.0
 )
 
+// chatPrice is a chat model's price in cents per 1M input and output
+// tokens.
+type chatPrice struct {
+	inCentsPer1M  float64
+	outCentsPer1M float64
+}
+
+// chatPrices are the chat models the TRANSCRIPTION_*_MODEL settings may
+// name; deps.go refuses anything else. Dated snapshots such as
+// "gpt-4o-mini-2024-07-18", which is what the API reports back, match
+// their base name.
+var chatPrices = map[string]chatPrice{
+	"gpt-4o-mini":  {inCentsPer1M: 15, outCentsPer1M: 60},
+	"gpt-4o":       {inCentsPer1M: 250, outCentsPer1M: 1000},
+	"gpt-4.1":      {inCentsPer1M: 200, outCentsPer1M: 800},
+	"gpt-4.1-mini": {inCentsPer1M: 40, outCentsPer1M: 160},
+	"gpt-4.1-nano": {inCentsPer1M: 10, outCentsPer1M: 40},
+}
+
+// chatPriceUnknown prices a model missing from chatPrices (a fake in
+// tests, or a snapshot OpenAI renamed) at the most expensive listed rate,
+// so the bill is never under-reported.
+var chatPriceUnknown = chatPrices["gpt-4o"]
+
+// chatPriceFor looks model up in chatPrices, either by its exact name or
+// as a dated snapshot ("<name>-YYYY-MM-DD").
+func chatPriceFor(model string) (chatPrice, bool) {
+	if p, ok := chatPrices[model]; ok {
+		return p, true
+	}
+	for name, p := range chatPrices {
+		if rest, ok := strings.CutPrefix(model, name+"-"); ok && rest != "" && rest[0] >= '0' && rest[0] <= '9' {
+			return p, true
+		}
+	}
+	return chatPrice{}, false
+}
+
 // whisperCostCents returns the cost in integer cents for transcribing
 // `durationSeconds` of audio. Rounds UP so we never under-report the bill.
 func whisperCostCents(durationSeconds float64) int {
@@ -34,3 +75,20 @@ func embedCostCents(tokens int) int {
 	cents := float64(tokens) * (embedCentsPer1MTokens / 1_000_000.0)
 	return int(math.Ceil(cents))
 }
+
+// chatCostCents returns the input and output cost in integer cents for a
+// completion on `model`. Each side rounds UP on its own, matching how
+// token_usage stores input_cost_cents / output_cost_cents separately.
+func chatCostCents(model string, promptTokens, completionTokens int) (inCents, outCents int) {
+	price, ok := chatPriceFor(model)
+	if !ok {
+		price = chatPriceUnknown
+	}
+	if promptTokens > 0 {
+		inCents = int(math.Ceil(float64(promptTokens) * (price.inCentsPer1M / 1_000_000.0)))
+	}
+	if completionTokens > 0 {
+		outCents = int(math.Ceil(float64(completionTokens) * (price.outCentsPer1M / 1_000_000.0)))
+	}
+	return inCents, outCents
+}
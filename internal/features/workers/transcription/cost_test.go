@@ -57,3 +57,31 @@ func TestEmbedCostCents(t *testing.T) {
 		})
 	}
 }
+
+func TestChatCostCents(t *testing.T) {
+	// gpt-4o-mini: 1M input = 15¢, 1M output = 60¢; any non-zero side
+	// rounds up to 1¢.
+	if in, out := chatCostCents("gpt-4o-mini", 1_000_000, 1_000_000); in != 15 || out != 60 {
+		t.Fatalf("1M/1M = %d/%d, want 15/60", in, out)
+	}
+	if in, out := chatCostCents("gpt-4o-mini", 1000, 0); in != 1 || out != 0 {
+		t.Fatalf("1k/0 = %d/%d, want 1/0", in, out)
+	}
+	// Dated snapshots price as their base model, not the shorter name.
+	if in, out := chatCostCents("gpt-4o-mini-2024-07-18", 1_000_000, 1_000_000); in != 15 || out != 60 {
+		t.Fatalf("snapshot = %d/%d, want 15/60", in, out)
+	}
+	if in, out := chatCostCents("gpt-4o", 1_000_000, 1_000_000); in != 250 || out != 1000 {
+		t.Fatalf("gpt-4o = %d/%d, want 250/1000", in, out)
+	}
+	if in, out := chatCostCents("gpt-4.1-nano", 1_000_000, 1_000_000); in != 10 || out != 40 {
+		t.Fatalf("gpt-4.1-nano = %d/%d, want 10/40", in, out)
+	}
+	// Unknown models are never priced below the dearest listed one.
+	if in, out := chatCostCents("mystery-llm", 1_000_000, 1_000_000); in != 250 || out != 1000 {
+		t.Fatalf("unknown = %d/%d, want 250/1000", in, out)
+	}
+	if _, ok := chatPriceFor("gpt-4o-turbo-ish"); ok {
+		t.Fatal("gpt-4o-turbo-ish should not match gpt-4o")
+	}
+}
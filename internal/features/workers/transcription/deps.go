@@ -8,11 +8,16 @@
 //   1. Resolve Bunny playback URL (HLS) from lesson.mediaUrl + tenant creds
 //   2. Extract audio to MP3 16 kHz mono (ffmpeg)
 //   3. Split audio into 10-min windows if total > Whisper 25 MB limit
-//   4. Transcribe each window via OpenAI Whisper API (whisper-1)
-//   5. Chunk transcript (~500 tokens, 50 overlap, aligned to Whisper segments)
+//   4. Transcribe each window via the tenant's speech-to-text backend
+//      (OpenAI whisper-1 by default, or a self-hosted OpenAI-compatible
+//      Whisper server — see transcriber.go)
+//   5. Apply the tenant glossary (glossary.go), then chunk the transcript
+//      (~500 tokens, 50 overlap, aligned to Whisper segments)
 //   6. Embed chunks via OpenAI text-embedding-3-small (batched)
 //   7. UPSERT video + INSERT transcript + INSERT chunks (single tx, Railway pgvector)
 //   8. UPDATE lesson.transcriptionCompleted = true (memberclass DB)
+//   9. Optionally queue a LESSON_SUMMARY job (LLM summary, chapters and
+//      key points — see summary.go)
 //
 // Storage: a dedicated Railway Postgres service created from the
 // "PostgreSQL pgvector" template. The vanilla Railway Postgres image
@@ -33,6 +38,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -57,6 +63,19 @@ const (
 	// safety margin for multipart overhead.
 	whisperMaxAudioBytes = 24 * 1024 * 1024
 
+	// jobCancelPollInterval bounds how long a RUNNING job keeps spending
+	// after it was cancelled through another replica. Cancels that land on
+	// the replica running the job take effect immediately.
+	jobCancelPollInterval = 15 * time.Second
+
+	// checkpointRetention bounds how long a failed job's per-part
+	// checkpoints wait for a retry (see checkpoint.go).
+	checkpointRetention = 7 * 24 * time.Hour
+
+	// defaultWhisperConcurrency is how many audio parts of one job are
+	// transcribed at once. Override with TRANSCRIPTION_WHISPER_CONCURRENCY.
+	defaultWhisperConcurrency = 3
+
 	// embedBatchSize keeps a single embeddings request under ~1 MB and far
 	// below OpenAI's per-call cap (2048 inputs).
 	embedBatchSize = 96
@@ -77,8 +96,71 @@ type Feature struct {
 	bunnyAccountAPIKey string // account-level key (BUNNY_API_KEY); resolves CDN hostname per library
 	httpClient         *http.Client
 
+	// openaiLimiter caps requests per minute across every OpenAI Whisper
+	// and embeddings call in the process (TRANSCRIPTION_OPENAI_RPM); nil
+	// means unlimited. whisperConcurrency bounds parallel parts per job.
+	openaiLimiter      *rpmLimiter
+	whisperConcurrency int
+
+	// stt routes each job to a speech-to-text backend (see transcriber.go).
+	// Zero value is fine: transcriberFor falls back to OpenAI built from
+	// the fields above.
+	stt sttConfig
+
+	// chat answers POST /answer (see answer.go); nil falls back to OpenAI
+	// gpt-4o-mini via chatClient. answerMinSimilarity is the refusal
+	// threshold on the best retrieved chunk.
+	chat                chatCompleter
+	answerMinSimilarity float64
+
+	// summaryChat writes lesson summaries (see summary.go); nil falls back
+	// to chatClient. summariesEnabled queues a LESSON_SUMMARY job after
+	// every completed transcription (TRANSCRIPTION_SUMMARIES_ENABLED).
+	summaryChat      chatCompleter
+	summariesEnabled bool
+
+	// quizChat writes quiz drafts (see quiz.go); nil falls back to
+	// chatClient.
+	quizChat chatCompleter
+
+	// pdfText extracts the text of each page of a PDF (see pdf.go); nil
+	// falls back to extractPdfPages, which needs poppler's pdftotext.
+	pdfText func(ctx context.Context, pdfURL string) ([]string, error)
+
+	// forcedLanguages overrides Tenant.language as Whisper's decoding
+	// language per tenant (TRANSCRIPTION_TENANT_FORCE_LANGUAGE, see
+	// language.go).
+	forcedLanguages map[string]string
+
+	// budgets caps each tenant's monthly AI spend (see budget.go). Zero
+	// value means no caps.
+	budgets budgetConfig
+
+	// trimSilence cuts long silences from each audio part and normalizes
+	// its loudness before Whisper (TRANSCRIPTION_TRIM_SILENCE, see
+	// audio_condition.go). On by default.
+	trimSilence bool
+
+	// captionsToBunny uploads every fresh transcript to the Bunny video as
+	// a WebVTT track (TRANSCRIPTION_UPLOAD_CAPTIONS). Off by default.
+	captionsToBunny bool
+
 	pollInterval time.Duration
 	workers      int
+	// tenantMaxRunning caps one tenant's RUNNING jobs at claim time
+	// (TRANSCRIPTION_TENANT_MAX_RUNNING). Zero means no cap; the claim is
+	// round-robin across tenants either way.
+	tenantMaxRunning int
+
+	// autoEnqueueSchedule / autoEnqueueTenantLimit drive the scheduler job
+	// that enqueues new or changed video lessons (see autoenqueue.go).
+	// Empty schedule means the job is not registered.
+	autoEnqueueSchedule    string
+	autoEnqueueTenantLimit int
+
+	// streamPollInterval is how often the SSE progress streams re-read the
+	// jobs table (see progress.go). Zero means defaultStreamPollInterval.
+	streamPollInterval time.Duration
 
 	// embedDims is the dimension width of chunks.embedding on the
 	// Railway pgvector DB. Populated from the column's typmod at Start()
@@ -94,7 +176,14 @@ type Feature struct {
 	cancel  context.CancelFunc
 	done    chan struct{}
 
-	// testHookResolveAudio, when non-nil, replaces the Bunny meta + HLS
+	// inflight maps jobID → cancel func for every job this process is
+	// executing, so the cancel endpoints can stop a RUNNING job right away
+	// (see job_control.go). Guarded by inflightMu, not mu — it churns on
+	// every job and must not contend with Start/Stop.
+	inflightMu sync.Mutex
+	inflight   map[string]context.CancelFunc
+
+	// testHookResolveAudio, when non-nil, replaces the source resolver's
 	// download + ffmpeg split chain with a caller-supplied resolver that
 	// produces local audio files. Production keeps this nil.
 	testHookResolveAudio resolveAudioFunc
@@ -112,6 +201,9 @@ func New(
 	if _, err := exec.LookPath("ffmpeg"); err != nil {
 		log.Warn("transcription: ffmpeg not found in PATH — pipeline will refuse to run jobs", "error", err.Error())
 	}
+	if _, err := exec.LookPath("pdftotext"); err != nil {
+		log.Warn("transcription: pdftotext not found in PATH — PDF lessons will not be indexed for search", "error", err.Error())
+	}
 
 	poll := defaultPollInterval
 	if v := os.Getenv("TRANSCRIPTION_POLL_INTERVAL_SECONDS"); v != "" {
@@ -125,12 +217,94 @@ func New(
 			workers = n
 		}
 	}
+	tenantMaxRunning := 0
+	if v := os.Getenv("TRANSCRIPTION_TENANT_MAX_RUNNING"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			tenantMaxRunning = n
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_TENANT_MAX_RUNNING — tenants not capped", "value", v)
+		}
+	}
+
+	if _, err := embedEncoding(); err != nil {
+		log.Warn("transcription: tokenizer ranks not embedded — chunk sizes use the word heuristic", "error", err.Error())
+	}
 
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
 		log.Warn("transcription: OPENAI_API_KEY not set — pipeline will refuse to run jobs")
 	}
 
+	httpClient := &http.Client{Timeout: 5 * time.Minute}
+
+	// The default only applies when the variable is unset: 0 turns the
+	// threshold off, and negative or >1 values are rejected here.
+	answerMinSim := defaultAnswerMinSimilarity
+	if v := os.Getenv("TRANSCRIPTION_ANSWER_MIN_SIMILARITY"); v != "" {
+		if x, err := strconv.ParseFloat(v, 64); err == nil && x >= 0 && x <= 1 {
+			answerMinSim = x
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_ANSWER_MIN_SIMILARITY — using default", "value", v)
+		}
+	}
+	answerModel := chatModelFromEnv(log, "TRANSCRIPTION_ANSWER_MODEL")
+	summaryModel := chatModelFromEnv(log, "TRANSCRIPTION_SUMMARY_MODEL")
+	quizModel := chatModelFromEnv(log, "TRANSCRIPTION_QUIZ_MODEL")
+
+	captionsToBunny := false
+	if v := os.Getenv("TRANSCRIPTION_UPLOAD_CAPTIONS"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			captionsToBunny = b
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_UPLOAD_CAPTIONS — captions upload disabled", "value", v)
+		}
+	}
+
+	trimSilence := true
+	if v := os.Getenv("TRANSCRIPTION_TRIM_SILENCE"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			trimSilence = b
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_TRIM_SILENCE — silence trimming enabled", "value", v)
+		}
+	}
+
+	summariesEnabled := false
+	if v := os.Getenv("TRANSCRIPTION_SUMMARIES_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			summariesEnabled = b
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_SUMMARIES_ENABLED — lesson summaries disabled", "value", v)
+		}
+	}
+
+	whisperConcurrency := defaultWhisperConcurrency
+	if v := os.Getenv("TRANSCRIPTION_WHISPER_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			whisperConcurrency = n
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_WHISPER_CONCURRENCY — using default", "value", v)
+		}
+	}
+	openaiRPM := 0
+	if v := os.Getenv("TRANSCRIPTION_OPENAI_RPM"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			openaiRPM = n
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_OPENAI_RPM — OpenAI calls not rate limited", "value", v)
+		}
+	}
+	openaiLimiter := newRPMLimiter(openaiRPM)
+
+	autoLimit := defaultAutoEnqueueTenantLimit
+	if v := os.Getenv("TRANSCRIPTION_AUTO_ENQUEUE_TENANT_LIMIT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			autoLimit = n
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_AUTO_ENQUEUE_TENANT_LIMIT — using default", "value", v)
+		}
+	}
+
 	return &Feature{
 		transcriptionDB: transcriptionDB,
 		memberclassDB:   memberclassDB,
@@ -140,10 +314,40 @@ func New(
 		openaiBaseURL:      defaultOpenAIBase,
 		bunnyBaseURL:       defaultBunnyBaseURL,
 		bunnyAccountAPIKey: os.Getenv("BUNNY_API_KEY"),
-		httpClient:         &http.Client{Timeout: 5 * time.Minute},
+		httpClient:         httpClient,
+		openaiLimiter:      openaiLimiter,
+		whisperConcurrency: whisperConcurrency,
+		stt:                loadSTTConfig(log, defaultOpenAIBase, apiKey, httpClient, openaiLimiter),
+		chat:               newOpenAIChat(defaultOpenAIBase, apiKey, answerModel, httpClient),
+		answerMinSimilarity: answerMinSim,
+		summaryChat:        newOpenAIChat(defaultOpenAIBase, apiKey, summaryModel, httpClient),
+		summariesEnabled:   summariesEnabled,
+		quizChat:           newOpenAIChat(defaultOpenAIBase, apiKey, quizModel, httpClient),
+		captionsToBunny:    captionsToBunny,
+		trimSilence:        trimSilence,
+		budgets:            loadBudgetConfig(log),
+		forcedLanguages:    loadForcedLanguages(log),
+		autoEnqueueSchedule:    os.Getenv("TRANSCRIPTION_AUTO_ENQUEUE_SCHEDULE"),
+		autoEnqueueTenantLimit: autoLimit,
 		pollInterval:    poll,
 		workers:         workers,
+		tenantMaxRunning: tenantMaxRunning,
+	}
+}
+
+// chatModelFromEnv reads a chat model setting. A model without an entry
+// in chatPrices is refused, because its spend could not be priced; the
+// slice falls back to defaultChatModel.
+func chatModelFromEnv(log ports.Logger, name string) string {
+	model := strings.TrimSpace(os.Getenv(name))
+	if model == "" {
+		return ""
+	}
+	if _, ok := chatPriceFor(model); !ok {
+		log.Warn("transcription: "+name+" names a model with no price in cost.go — using "+defaultChatModel, "model", model)
+		return ""
 	}
+	return model
 }
 
 // MiddlewareSet carries the chi-compatible middlewares the slice's routes
@@ -152,6 +356,9 @@ type MiddlewareSet struct {
 	// AuthInternal validates x-internal-api-key against INTERNAL_AI_API_KEY,
 	// mirroring the legacy /api/v1/ai/* gate.
 	AuthInternal func(http.Handler) http.Handler
+	// AuthExternal validates mc-api-key and puts the tenant in the request
+	// context. The student routes are only mounted when it is set.
+	AuthExternal func(http.Handler) http.Handler
 	// RateLimitTenant gates by tenant API key extracted earlier in the chain.
 	RateLimitTenant func(http.Handler) http.Handler
 }
@@ -44,6 +44,32 @@ func TestNew_HonorsEnvOverrides(t *testing.T) {
 	}
 }
 
+func TestNew_AnswerSettings(t *testing.T) {
+	log := logger.NewLogger()
+
+	t.Setenv("TRANSCRIPTION_ANSWER_MIN_SIMILARITY", "")
+	if f := New(nil, nil, log, nil); f.answerMinSimilarity != defaultAnswerMinSimilarity {
+		t.Fatalf("unset: min similarity = %v, want default", f.answerMinSimilarity)
+	}
+	t.Setenv("TRANSCRIPTION_ANSWER_MIN_SIMILARITY", "0")
+	if f := New(nil, nil, log, nil); f.answerMinSimilarity != 0 {
+		t.Fatalf("0 must disable the threshold, got %v", f.answerMinSimilarity)
+	}
+	t.Setenv("TRANSCRIPTION_ANSWER_MIN_SIMILARITY", "-0.2")
+	if f := New(nil, nil, log, nil); f.answerMinSimilarity != defaultAnswerMinSimilarity {
+		t.Fatalf("negative accepted: %v", f.answerMinSimilarity)
+	}
+
+	t.Setenv("TRANSCRIPTION_ANSWER_MODEL", "gpt-4.1-mini")
+	if f := New(nil, nil, log, nil); f.chat.Model() != "gpt-4.1-mini" {
+		t.Fatalf("answer model = %q", f.chat.Model())
+	}
+	t.Setenv("TRANSCRIPTION_ANSWER_MODEL", "some-unpriced-model")
+	if f := New(nil, nil, log, nil); f.chat.Model() != defaultChatModel {
+		t.Fatalf("unpriced model accepted: %q", f.chat.Model())
+	}
+}
+
 func TestPreflight_FailsWithoutDB(t *testing.T) {
 	f := &Feature{openaiAPIKey: "k"}
 	if err := f.preflight(); err == nil {
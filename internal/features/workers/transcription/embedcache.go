@@ -0,0 +1,93 @@
+package transcription
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Embedding cache: vectors keyed by (embedModel, dims, sha256(text)) in the
+// transcription DB's embedding_cache table. Re-processing a lesson whose
+// transcript did not change — or re-embedding at the same model/dims —
+// re-chunks into the same texts, so embedChunks only sends the misses to
+// OpenAI. The cache is best effort: a failed lookup is treated as all
+// misses and a failed store is logged, neither fails the job.
+
+// embedCacheKey is the hex sha256 of one chunk text.
+func embedCacheKey(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookupEmbeddings returns the cached vectors for `hashes`, keyed by hash.
+func (f *Feature) lookupEmbeddings(ctx context.Context, dims int, hashes []string) map[string][]float32 {
+	out := make(map[string][]float32)
+	if len(hashes) == 0 {
+		return out
+	}
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectCachedEmbeddings, embedModel, dims, pq.Array(hashes))
+	if err != nil {
+		f.log.Warn("transcription.embed_cache.lookup_failed", "error", err.Error())
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash, literal string
+		if err := rows.Scan(&hash, &literal); err != nil {
+			f.log.Warn("transcription.embed_cache.lookup_failed", "error", err.Error())
+			return map[string][]float32{}
+		}
+		vec, err := parsePgvector(literal)
+		if err != nil || len(vec) != dims {
+			// A corrupt or mis-sized row is only a miss, never a chunk
+			// vector.
+			continue
+		}
+		out[hash] = vec
+	}
+	if err := rows.Err(); err != nil {
+		f.log.Warn("transcription.embed_cache.lookup_failed", "error", err.Error())
+		return map[string][]float32{}
+	}
+	return out
+}
+
+// storeEmbeddings caches one batch of freshly embedded texts.
+func (f *Feature) storeEmbeddings(ctx context.Context, dims int, hashes []string, vecs [][]float32) {
+	literals := make([]string, len(vecs))
+	for i, v := range vecs {
+		literals[i] = pgvectorString(v)
+	}
+	if _, err := f.transcriptionDB.ExecContext(ctx, sqlInsertCachedEmbeddings,
+		embedModel, dims, pq.Array(hashes), pq.Array(literals),
+	); err != nil {
+		f.log.Warn("transcription.embed_cache.store_failed", "count", len(hashes), "error", err.Error())
+	}
+}
+
+// parsePgvector decodes pgvector's text output, `[v1,v2,...]`.
+func parsePgvector(s string) ([]float32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("pgvector literal %.20q: missing brackets", s)
+	}
+	body := s[1 : len(s)-1]
+	if body == "" {
+		return []float32{}, nil
+	}
+	fields := strings.Split(body, ",")
+	out := make([]float32, len(fields))
+	for i, fld := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(fld), 32)
+		if err != nil {
+			return nil, fmt.Errorf("pgvector element %d: %w", i, err)
+		}
+		out[i] = float32(x)
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func expectEmbedCacheMiss(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`FROM embedding_cache`).
+		WillReturnRows(sqlmock.NewRows([]string{"text_sha256", "embedding"}))
+}
+
+func expectEmbedCacheStore(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`INSERT INTO embedding_cache`).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestEmbedChunks_SendsOnlyCacheMisses(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+
+	var sent [][]string
+	openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		sent = append(sent, body.Input)
+		data := make([]embedding, len(body.Input))
+		for i := range data {
+			data[i] = embedding{Index: i, Embedding: []float32{0.5, 0.5}}
+		}
+		_ = json.NewEncoder(w).Encode(embeddingsResponse{Data: data, Usage: usage{TotalTokens: 3}})
+	}))
+	defer openai.Close()
+
+	f := &Feature{
+		transcriptionDB: db,
+		log:             logger.NewLogger(),
+		openaiAPIKey:    "k",
+		openaiBaseURL:   openai.URL,
+		httpClient:      openai.Client(),
+		embedDims:       2,
+	}
+	chunks := []chunk{{Text: "a"}, {Text: "b"}, {Text: "a"}}
+
+	mock.ExpectQuery(`FROM embedding_cache`).
+		WithArgs(embedModel, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"text_sha256", "embedding"}).
+			AddRow(embedCacheKey("b"), "[0.25,-1]"))
+	mock.ExpectExec(`INSERT INTO embedding_cache`).
+		WithArgs(embedModel, 2, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	res, err := f.embedChunks(context.Background(), chunks, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "a" goes out once for both chunks; "b" never does.
+	if !reflect.DeepEqual(sent, [][]string{{"a"}}) {
+		t.Fatalf("sent = %q", sent)
+	}
+	want := [][]float32{{0.5, 0.5}, {0.25, -1}, {0.5, 0.5}}
+	if !reflect.DeepEqual(res.Vectors, want) {
+		t.Fatalf("vectors = %v", res.Vectors)
+	}
+	if res.CacheHits != 1 || res.CacheMisses != 2 || res.Tokens != 3 {
+		t.Fatalf("result = %+v", res)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEmbedChunks_AllCachedSkipsOpenAI(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{
+		transcriptionDB: db,
+		log:             logger.NewLogger(),
+		openaiBaseURL:   "https://openai.invalid",
+		httpClient:      http.DefaultClient,
+		embedDims:       2,
+	}
+	mock.ExpectQuery(`FROM embedding_cache`).
+		WillReturnRows(sqlmock.NewRows([]string{"text_sha256", "embedding"}).
+			AddRow(embedCacheKey("a"), "[1,2]").
+			AddRow(embedCacheKey("b"), "[1,2,3]")) // wrong width: a miss
+
+	if _, err := f.embedChunks(context.Background(), []chunk{{Text: "a"}}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(`FROM embedding_cache`).
+		WillReturnRows(sqlmock.NewRows([]string{"text_sha256", "embedding"}).
+			AddRow(embedCacheKey("b"), "[1,2,3]"))
+	if _, err := f.embedChunks(context.Background(), []chunk{{Text: "b"}}, nil, nil); err == nil {
+		t.Fatal("mis-sized cache row should have been a miss sent to OpenAI")
+	}
+}
+
+func TestParsePgvector(t *testing.T) {
+	got, err := parsePgvector("[0.5,-2,1e-05]")
+	if err != nil || !reflect.DeepEqual(got, []float32{0.5, -2, 1e-05}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	if v, err := parsePgvector(pgvectorString([]float32{0.1, 0.2})); err != nil || v[0] != 0.1 || v[1] != 0.2 {
+		t.Fatalf("round trip = %v, %v", v, err)
+	}
+	for _, bad := range []string{"", "0.5,1", "[a,b]"} {
+		if _, err := parsePgvector(bad); err == nil {
+			t.Errorf("parsePgvector(%q): expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package transcription
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+// testFixture is the Feature most slice tests start from: both databases
+// mocked with the regexp matcher and a fake OpenAI that only serves
+// /v1/embeddings, answering every input with one [0.1, 0.2] vector and 4
+// tokens. Tests set whatever else they need (chat clients, budgets, ...)
+// on f before using it.
+type testFixture struct {
+	f      *Feature
+	txMock sqlmock.Sqlmock // DB_TRANSCRIPTION_DSN
+	mcMock sqlmock.Sqlmock // DB_DSN
+	// embedCalls counts the requests the fake OpenAI received.
+	embedCalls int
+}
+
+func newTestFixture(t *testing.T) *testFixture {
+	t.Helper()
+	setEnvKey(t, "k")
+	transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	t.Cleanup(func() { transcriptionDB.Close() })
+	memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	t.Cleanup(func() { memberclassDB.Close() })
+
+	fx := &testFixture{txMock: txMock, mcMock: mcMock}
+	openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("unexpected OpenAI path: %s", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		fx.embedCalls++
+		_ = json.NewEncoder(w).Encode(embeddingsResponse{
+			Data:  []embedding{{Index: 0, Embedding: []float32{0.1, 0.2}}},
+			Usage: usage{TotalTokens: 4},
+		})
+	}))
+	t.Cleanup(openai.Close)
+
+	fx.f = &Feature{
+		transcriptionDB: transcriptionDB,
+		memberclassDB:   memberclassDB,
+		log:             logger.NewLogger(),
+		openaiAPIKey:    "k",
+		openaiBaseURL:   openai.URL,
+		httpClient:      openai.Client(),
+	}
+	return fx
+}
+
+// router mounts the slice with the given middlewares.
+func (fx *testFixture) router(mw MiddlewareSet) chi.Router {
+	r := chi.NewRouter()
+	fx.f.Register(r, mw)
+	return r
+}
+
+// expectAITenant answers the tenant lookup of t1 (see loadTenant).
+func (fx *testFixture) expectAITenant(enabled bool) {
+	fx.mcMock.ExpectQuery(`FROM "Tenant"`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t1", "T", enabled, nil, nil))
+}
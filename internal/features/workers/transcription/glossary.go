@@ -0,0 +1,278 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Tenant glossary: find/replace rules for the brand names and jargon
+// Whisper keeps mangling ("member class" → "MemberClass"). The pipeline
+// applies them to every fresh transcript before chunking, so search,
+// captions and summaries all see the corrected text.
+
+const (
+	maxGlossaryRules    = 200
+	maxGlossaryTermRune = 100
+)
+
+// glossaryRule replaces whole-word occurrences of Find with Replace.
+// Matching ignores case unless CaseSensitive is set.
+type glossaryRule struct {
+	Find          string `json:"find"`
+	Replace       string `json:"replace"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+}
+
+// glossary is a tenant's rules compiled for matching, applied in order.
+type glossary struct {
+	rules    []glossaryRule
+	patterns []*regexp.Regexp
+}
+
+// ---------- DTOs ----------
+
+// glossaryResponse is the body of GET/PUT /tenants/{tenantId}/transcript-glossary.
+type glossaryResponse struct {
+	TenantID  string         `json:"tenantId"`
+	Rules     []glossaryRule `json:"rules"`
+	UpdatedAt *time.Time     `json:"updatedAt,omitempty"`
+}
+
+// putGlossaryRequest replaces the whole rule list.
+type putGlossaryRequest struct {
+	Rules []glossaryRule `json:"rules"`
+}
+
+// ---------- 1. HTTP handlers ----------
+
+// GetTranscriptGlossary handles `GET /api/v1/ai/tenants/{tenantId}/transcript-glossary`.
+//
+// A tenant without a glossary gets an empty rule list.
+func (f *Feature) GetTranscriptGlossary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+	tenantID := chi.URLParam(r, "tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+
+	resp := glossaryResponse{TenantID: tenantID, Rules: []glossaryRule{}}
+	var (
+		raw       []byte
+		updatedAt time.Time
+	)
+	err := f.transcriptionDB.QueryRowContext(r.Context(), sqlSelectTranscriptGlossary, tenantID).Scan(&raw, &updatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		f.log.Error("transcription.glossary.load_failed", "tenant", tenantID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	if err == nil {
+		if err := json.Unmarshal(raw, &resp.Rules); err != nil {
+			writeError(w, http.StatusInternalServerError, "Internal Server Error", "decode glossary")
+			return
+		}
+		resp.UpdatedAt = &updatedAt
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PutTranscriptGlossary handles `PUT /api/v1/ai/tenants/{tenantId}/transcript-glossary`.
+//
+// Body: { rules: [{ find, replace, caseSensitive? }] } — replaces the
+// tenant's rules. They apply to transcriptions that run afterwards;
+// existing transcripts are corrected through the transcript endpoint.
+func (f *Feature) PutTranscriptGlossary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+	tenantID := chi.URLParam(r, "tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+
+	limitBody(w, r)
+	var req putGlossaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	rules, err := normalizeGlossaryRules(req.Rules)
+	if err != nil {
+		writeCustomError(w, http.StatusBadRequest, err.Error(), "INVALID_GLOSSARY")
+		return
+	}
+
+	raw, _ := json.Marshal(rules)
+	var updatedAt time.Time
+	if err := f.transcriptionDB.QueryRowContext(r.Context(), sqlUpsertTranscriptGlossary, tenantID, raw).Scan(&updatedAt); err != nil {
+		f.log.Error("transcription.glossary.save_failed", "tenant", tenantID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, glossaryResponse{TenantID: tenantID, Rules: rules, UpdatedAt: &updatedAt})
+}
+
+// ---------- 2. Business rule ----------
+
+// normalizeGlossaryRules trims the terms and rejects empty, oversized or
+// duplicate finds.
+func normalizeGlossaryRules(in []glossaryRule) ([]glossaryRule, error) {
+	if len(in) > maxGlossaryRules {
+		return nil, fmt.Errorf("rules excede o limite de %d regras", maxGlossaryRules)
+	}
+	out := make([]glossaryRule, 0, len(in))
+	seen := make(map[string]bool, len(in))
+	for i, rule := range in {
+		rule.Find = strings.TrimSpace(rule.Find)
+		rule.Replace = strings.TrimSpace(rule.Replace)
+		if rule.Find == "" {
+			return nil, fmt.Errorf("regra %d: find é obrigatório", i+1)
+		}
+		if utf8.RuneCountInString(rule.Find) > maxGlossaryTermRune || utf8.RuneCountInString(rule.Replace) > maxGlossaryTermRune {
+			return nil, fmt.Errorf("regra %d: termos limitados a %d caracteres", i+1, maxGlossaryTermRune)
+		}
+		key := rule.Find
+		if !rule.CaseSensitive {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("regra %d: find %q repetido", i+1, rule.Find)
+		}
+		seen[key] = true
+		out = append(out, rule)
+	}
+	return out, nil
+}
+
+// newGlossary compiles the rules. Finds are literal text, never regexps.
+func newGlossary(rules []glossaryRule) *glossary {
+	g := &glossary{}
+	for _, rule := range rules {
+		if rule.Find == "" {
+			continue
+		}
+		expr := regexp.QuoteMeta(rule.Find)
+		if !rule.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		g.rules = append(g.rules, rule)
+		g.patterns = append(g.patterns, regexp.MustCompile(expr))
+	}
+	return g
+}
+
+// apply runs every rule over s. A match only counts as a whole word: the
+// runes around it must not be letters or digits, so "IA" never rewrites
+// the middle of "média". (regexp's \b is ASCII-only and would.)
+func (g *glossary) apply(s string) string {
+	if g == nil {
+		return s
+	}
+	for i, re := range g.patterns {
+		matches := re.FindAllStringIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			if !wordBoundary(s, m[0], m[1]) {
+				continue
+			}
+			b.WriteString(s[last:m[0]])
+			b.WriteString(g.rules[i].Replace)
+			last = m[1]
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
+
+// applySegments corrects the segments in place.
+func (g *glossary) applySegments(segments []whisperSegment) {
+	if g == nil {
+		return
+	}
+	for i := range segments {
+		segments[i].Text = g.apply(segments[i].Text)
+	}
+}
+
+func wordBoundary(s string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(s[:start])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	if end < len(s) {
+		r, _ := utf8.DecodeRuneInString(s[end:])
+		if isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// loadGlossary returns the tenant's compiled glossary, or nil when it has
+// none. Best effort: a read failure is logged and the transcript is
+// stored as Whisper returned it.
+func (f *Feature) loadGlossary(ctx context.Context, tenantID string) *glossary {
+	var (
+		raw       []byte
+		updatedAt time.Time
+	)
+	err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTranscriptGlossary, tenantID).Scan(&raw, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		f.log.Warn("transcription.glossary.load_failed", "tenant", tenantID, "error", err.Error())
+		return nil
+	}
+	var rules []glossaryRule
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		f.log.Warn("transcription.glossary.decode_failed", "tenant", tenantID, "error", err.Error())
+		return nil
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return newGlossary(rules)
+}
@@ -0,0 +1,96 @@
+package transcription
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestGlossaryApply_WholeWordsOnly(t *testing.T) {
+	g := newGlossary([]glossaryRule{
+		{Find: "member class", Replace: "MemberClass"},
+		{Find: "IA", Replace: "IA", CaseSensitive: true},
+		{Find: "ia", Replace: "IA"},
+		{Find: "c++", Replace: "C++"},
+	})
+	cases := map[string]string{
+		"Bem-vindos ao Member Class!": "Bem-vindos ao MemberClass!",
+		"a ia da plataforma":          "a IA da plataforma",
+		"a média e a mídia não mudam": "a média e a mídia não mudam",
+		"iniciamos em c++ hoje":       "iniciamos em C++ hoje",
+		"memberclassy member classes": "memberclassy member classes",
+		"member class, member class.": "MemberClass, MemberClass.",
+	}
+	for in, want := range cases {
+		if got := g.apply(in); got != want {
+			t.Errorf("apply(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	var none *glossary
+	if got := none.apply("sem regras"); got != "sem regras" {
+		t.Fatalf("nil glossary changed text: %q", got)
+	}
+}
+
+func TestNormalizeGlossaryRules(t *testing.T) {
+	got, err := normalizeGlossaryRules([]glossaryRule{{Find: " member class ", Replace: " MemberClass "}})
+	if err != nil || got[0].Find != "member class" || got[0].Replace != "MemberClass" {
+		t.Fatalf("rules = %+v, %v", got, err)
+	}
+	for _, rules := range [][]glossaryRule{
+		{{Find: " ", Replace: "x"}},
+		{{Find: "Pix", Replace: "PIX"}, {Find: "pix", Replace: "PIX"}},
+		{{Find: string(make([]byte, maxGlossaryTermRune+1)), Replace: "x"}},
+	} {
+		if _, err := normalizeGlossaryRules(rules); err == nil {
+			t.Errorf("%+v: expected error", rules)
+		}
+	}
+	// Case-sensitive rules may differ only by case.
+	if _, err := normalizeGlossaryRules([]glossaryRule{
+		{Find: "Pix", Replace: "PIX", CaseSensitive: true},
+		{Find: "pix", Replace: "PIX", CaseSensitive: true},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranscriptGlossaryEndpoints(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	updated := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	txMock.ExpectQuery(`INSERT INTO transcript_glossaries`).
+		WithArgs("t1", []byte(`[{"find":"member class","replace":"MemberClass"}]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(updated))
+	body, _ := json.Marshal(putGlossaryRequest{Rules: []glossaryRule{{Find: "member class", Replace: "MemberClass"}}})
+	req := httptest.NewRequest(http.MethodPut, "/tenants/t1/transcript-glossary", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("put status = %d body = %s", w.Code, w.Body.String())
+	}
+
+	// A tenant that never saved rules gets an empty list.
+	txMock.ExpectQuery(`FROM transcript_glossaries`).WithArgs("t2").
+		WillReturnRows(sqlmock.NewRows([]string{"rules", "updated_at"}))
+	req = httptest.NewRequest(http.MethodGet, "/tenants/t2/transcript-glossary", nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var got glossaryResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if w.Code != http.StatusOK || got.Rules == nil || len(got.Rules) != 0 || got.UpdatedAt != nil {
+		t.Fatalf("get status = %d body = %s", w.Code, w.Body.String())
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -199,7 +199,7 @@ func TestGetJobStatus_NotFound(t *testing.T) {
 		log:             logger.NewLogger(),
 	}
 	mock.ExpectQuery(`FROM jobs`).WithArgs("missing").WillReturnRows(sqlmock.NewRows([]string{
-		"id", "tenant_id", "status", "attempts", "error", "started_at", "completed_at", "payload", "result",
+		"id", "tenant_id", "status", "attempts", "error", "started_at", "completed_at", "payload", "result", "progress",
 	}))
 
 	r := chi.NewRouter()
@@ -0,0 +1,306 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxJobPriority bounds what SetTenantJobsPriority accepts; enqueues use 0.
+const maxJobPriority = 100
+
+// ---------- DTOs ----------
+
+type jobControlResponse struct {
+	JobID   string `json:"jobId"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// tenantJobsPriorityRequest is the body of POST
+// /tenants/{tenantId}/jobs/priority. Type narrows it to one job type.
+type tenantJobsPriorityRequest struct {
+	Priority *int   `json:"priority"`
+	Type     string `json:"type,omitempty"`
+}
+
+type tenantJobsControlResponse struct {
+	TenantID string   `json:"tenantId"`
+	JobIDs   []string `json:"jobIds"`
+	Count    int      `json:"count"`
+	Message  string   `json:"message"`
+}
+
+// ---------- 1. HTTP handlers ----------
+
+// CancelJob handles `POST /api/v1/ai/jobs/{jobId}/cancel`.
+//
+// PENDING jobs never get claimed again. RUNNING jobs are stopped
+// cooperatively: the job's context is cancelled (immediately on this
+// replica, within jobCancelPollInterval on any other) and the pipeline
+// unwinds without marking the job FAILED. Work already committed by the
+// time the cancel lands is not rolled back.
+func (f *Feature) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := f.jobControlPreamble(w, r)
+	if !ok {
+		return
+	}
+	f.transitionJob(w, r, jobID, sqlCancelJob, JobStatusCancelled,
+		"Job cancelado", "Job não pode ser cancelado", "JOB_NOT_CANCELLABLE")
+}
+
+// RetryJob handles `POST /api/v1/ai/jobs/{jobId}/retry`. Only FAILED jobs
+// qualify; they go back to PENDING with attempts reset to 0 so the full
+// max_attempts budget applies again.
+func (f *Feature) RetryJob(w http.ResponseWriter, r *http.Request) {
+	jobID, ok := f.jobControlPreamble(w, r)
+	if !ok {
+		return
+	}
+	f.transitionJob(w, r, jobID, sqlRetryFailedJob, JobStatusPending,
+		"Job reenfileirado", "Somente jobs FAILED podem ser reenfileirados", "JOB_NOT_RETRYABLE")
+}
+
+// CancelTenantJobs handles `POST /api/v1/ai/tenants/{tenantId}/jobs/cancel`.
+// Cancels every PENDING and RUNNING job of the tenant in one statement;
+// the ids that changed state come back so the admin UI can show them.
+func (f *Feature) CancelTenantJobs(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := f.tenantJobsPreamble(w, r)
+	if !ok {
+		return
+	}
+	ids, err := f.bulkTransition(r.Context(), sqlCancelTenantJobs, tenantID)
+	if err != nil {
+		f.log.Error("transcription.job_control.bulk_cancel_failed", "tenant", tenantID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	for _, id := range ids {
+		f.cancelInflight(id)
+	}
+	writeJSON(w, http.StatusOK, tenantJobsControlResponse{
+		TenantID: tenantID,
+		JobIDs:   ids,
+		Count:    len(ids),
+		Message:  fmt.Sprintf("%d job(s) cancelado(s)", len(ids)),
+	})
+}
+
+// RetryTenantFailedJobs handles `POST /api/v1/ai/tenants/{tenantId}/jobs/retry-failed`.
+// Re-queues every FAILED job of the tenant with a fresh attempts budget.
+func (f *Feature) RetryTenantFailedJobs(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := f.tenantJobsPreamble(w, r)
+	if !ok {
+		return
+	}
+	ids, err := f.bulkTransition(r.Context(), sqlRetryTenantFailedJobs, tenantID)
+	if err != nil {
+		f.log.Error("transcription.job_control.bulk_retry_failed", "tenant", tenantID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, tenantJobsControlResponse{
+		TenantID: tenantID,
+		JobIDs:   ids,
+		Count:    len(ids),
+		Message:  fmt.Sprintf("%d job(s) reenfileirado(s)", len(ids)),
+	})
+}
+
+// SetTenantJobsPriority handles `POST /api/v1/ai/tenants/{tenantId}/jobs/priority`.
+//
+// Body: { priority, type? }. Sets the priority of every PENDING job of the
+// tenant; the claim serves higher priorities first and only round-robins
+// tenants within a level, so this lets support push a tenant's batch
+// ahead (or back to 0 once it is no longer urgent).
+func (f *Feature) SetTenantJobsPriority(w http.ResponseWriter, r *http.Request) {
+	tenantID, ok := f.tenantJobsPreamble(w, r)
+	if !ok {
+		return
+	}
+	limitBody(w, r)
+	var req tenantJobsPriorityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.Priority == nil || *req.Priority < 0 || *req.Priority > maxJobPriority {
+		writeCustomError(w, http.StatusBadRequest,
+			fmt.Sprintf("priority deve estar entre 0 e %d", maxJobPriority), "INVALID_PRIORITY")
+		return
+	}
+	switch req.Type {
+	case "", JobTypeVideoProcessing, JobTypeEmbeddingGeneration, JobTypeLessonSummary, JobTypePdfEmbedding:
+	default:
+		writeCustomError(w, http.StatusBadRequest, "type inválido", "INVALID_JOB_TYPE")
+		return
+	}
+
+	ids, err := f.bulkTransition(r.Context(), sqlSetTenantJobsPriority, tenantID, *req.Priority, req.Type)
+	if err != nil {
+		f.log.Error("transcription.job_control.bulk_priority_failed", "tenant", tenantID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	f.log.Info("transcription.job_control.priority_set", "tenant", tenantID, "priority", *req.Priority, "count", len(ids))
+	writeJSON(w, http.StatusOK, tenantJobsControlResponse{
+		TenantID: tenantID,
+		JobIDs:   ids,
+		Count:    len(ids),
+		Message:  fmt.Sprintf("%d job(s) com prioridade %d", len(ids), *req.Priority),
+	})
+}
+
+func (f *Feature) jobControlPreamble(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return "", false
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return "", false
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return "", false
+	}
+	jobID := chi.URLParam(r, "jobId")
+	if jobID == "" {
+		writeCustomError(w, http.StatusBadRequest, "jobId é obrigatório", "MISSING_JOB_ID")
+		return "", false
+	}
+	return jobID, true
+}
+
+func (f *Feature) tenantJobsPreamble(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return "", false
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return "", false
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return "", false
+	}
+	tenantID := chi.URLParam(r, "tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return "", false
+	}
+	return tenantID, true
+}
+
+// ---------- 2. Business rule ----------
+
+// transitionJob runs a single-row state transition (cancel or retry). The
+// UPDATE's WHERE clause encodes which source states are legal; when it
+// matches nothing we re-read the row to tell 404 from 409.
+func (f *Feature) transitionJob(w http.ResponseWriter, r *http.Request, jobID, query, newStatus, okMsg, conflictMsg, conflictCode string) {
+	ctx := r.Context()
+	var id string
+	err := f.transcriptionDB.QueryRowContext(ctx, query, jobID).Scan(&id)
+	if err == nil {
+		if newStatus == JobStatusCancelled {
+			f.cancelInflight(jobID)
+		}
+		f.log.Info("transcription.job_control.transitioned", "jobId", jobID, "status", newStatus)
+		writeJSON(w, http.StatusOK, jobControlResponse{JobID: jobID, Status: newStatus, Message: okMsg})
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		f.log.Error("transcription.job_control.update_failed", "jobId", jobID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	var current string
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectJobState, jobID).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeCustomError(w, http.StatusNotFound, "Job não encontrado", "JOB_NOT_FOUND")
+			return
+		}
+		f.log.Error("transcription.job_control.state_failed", "jobId", jobID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeCustomError(w, http.StatusConflict,
+		fmt.Sprintf("%s (status=%s)", conflictMsg, current), conflictCode)
+}
+
+func (f *Feature) bulkTransition(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ---------- 3. In-flight registry ----------
+
+func (f *Feature) trackInflight(jobID string, cancel context.CancelFunc) {
+	f.inflightMu.Lock()
+	defer f.inflightMu.Unlock()
+	if f.inflight == nil {
+		f.inflight = make(map[string]context.CancelFunc)
+	}
+	f.inflight[jobID] = cancel
+}
+
+func (f *Feature) untrackInflight(jobID string) {
+	f.inflightMu.Lock()
+	defer f.inflightMu.Unlock()
+	delete(f.inflight, jobID)
+}
+
+// cancelInflight cancels the job's context if this process is running it.
+// Returns false when the job runs elsewhere (or not at all) — the other
+// replica's watchCancellation picks the CANCELLED status up on its own.
+func (f *Feature) cancelInflight(jobID string) bool {
+	f.inflightMu.Lock()
+	cancel, ok := f.inflight[jobID]
+	f.inflightMu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// watchCancellation polls the job's status while it runs and cancels its
+// context once the row reads CANCELLED. This is the cross-replica half of
+// cooperative cancel; probe errors are ignored (the next tick retries).
+func (f *Feature) watchCancellation(ctx context.Context, jobID string, cancel context.CancelFunc) {
+	t := time.NewTicker(jobCancelPollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			var status string
+			if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectJobState, jobID).Scan(&status); err != nil {
+				continue
+			}
+			if status == JobStatusCancelled {
+				f.log.Info("transcription.worker.cancel_observed", "jobId", jobID)
+				cancel()
+				return
+			}
+		}
+	}
+}
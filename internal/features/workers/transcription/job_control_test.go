@@ -0,0 +1,177 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func newJobControlRouter(t *testing.T) (*Feature, sqlmock.Sqlmock, chi.Router) {
+	t.Helper()
+	setEnvKey(t, "k")
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	t.Cleanup(func() { db.Close() })
+	f := &Feature{transcriptionDB: db, openaiAPIKey: "x", log: logger.NewLogger()}
+	r := chi.NewRouter()
+	f.Register(r, MiddlewareSet{})
+	return f, mock, r
+}
+
+func postJobControl(r chi.Router, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCancelJob_RequiresKey(t *testing.T) {
+	_, _, r := newJobControlRouter(t)
+	req := httptest.NewRequest(http.MethodPost, "/jobs/j1/cancel", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+}
+
+func TestCancelJob_SignalsInflightContext(t *testing.T) {
+	f, mock, r := newJobControlRouter(t)
+	mock.ExpectQuery(`UPDATE jobs.*CANCELLED.*RETURNING id`).
+		WithArgs("j1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("j1"))
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.trackInflight("j1", cancel)
+
+	w := postJobControl(r, "/jobs/j1/cancel")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var resp jobControlResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Status != JobStatusCancelled {
+		t.Fatalf("status field = %q", resp.Status)
+	}
+	if jobCtx.Err() == nil {
+		t.Fatal("in-flight job context was not cancelled")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCancelJob_NotFoundAndTerminal(t *testing.T) {
+	_, mock, r := newJobControlRouter(t)
+
+	mock.ExpectQuery(`UPDATE jobs.*CANCELLED`).WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT status FROM jobs`).WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+	if w := postJobControl(r, "/jobs/missing/cancel"); w.Code != http.StatusNotFound {
+		t.Fatalf("missing: status = %d", w.Code)
+	}
+
+	mock.ExpectQuery(`UPDATE jobs.*CANCELLED`).WithArgs("done").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT status FROM jobs`).WithArgs("done").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(JobStatusCompleted))
+	w := postJobControl(r, "/jobs/done/cancel")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("terminal: status = %d", w.Code)
+	}
+	var body map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body["errorCode"] != "JOB_NOT_CANCELLABLE" {
+		t.Fatalf("errorCode = %v", body["errorCode"])
+	}
+}
+
+func TestRetryJob_OnlyFailed(t *testing.T) {
+	_, mock, r := newJobControlRouter(t)
+
+	mock.ExpectQuery(`UPDATE jobs.*attempts\s+= 0.*status = 'FAILED'`).WithArgs("f1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("f1"))
+	if w := postJobControl(r, "/jobs/f1/retry"); w.Code != http.StatusOK {
+		t.Fatalf("failed job: status = %d body = %s", w.Code, w.Body.String())
+	}
+
+	mock.ExpectQuery(`UPDATE jobs.*status = 'FAILED'`).WithArgs("r1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT status FROM jobs`).WithArgs("r1").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(JobStatusRunning))
+	if w := postJobControl(r, "/jobs/r1/retry"); w.Code != http.StatusConflict {
+		t.Fatalf("running job: status = %d", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCancelTenantJobs_ReturnsIDs(t *testing.T) {
+	f, mock, r := newJobControlRouter(t)
+	mock.ExpectQuery(`UPDATE jobs.*CANCELLED.*tenant_id = \$1`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a").AddRow("b"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f.trackInflight("b", cancel)
+
+	w := postJobControl(r, "/tenants/t1/jobs/cancel")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var resp tenantJobsControlResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Count != 2 || len(resp.JobIDs) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if ctx.Err() == nil {
+		t.Fatal("running job b was not signalled")
+	}
+}
+
+func postTenantPriority(r chi.Router, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/tenants/t1/jobs/priority", strings.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetTenantJobsPriority_UpdatesPending(t *testing.T) {
+	_, mock, r := newJobControlRouter(t)
+	mock.ExpectQuery(`UPDATE jobs\s+SET priority = \$2.*status = 'PENDING'`).
+		WithArgs("t1", 10, JobTypeVideoProcessing).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a").AddRow("b"))
+
+	w := postTenantPriority(r, `{"priority":10,"type":"VIDEO_PROCESSING"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var resp tenantJobsControlResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Count != 2 || resp.TenantID != "t1" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetTenantJobsPriority_Validation(t *testing.T) {
+	_, _, r := newJobControlRouter(t)
+	for _, body := range []string{`{}`, `{"priority":-1}`, `{"priority":101}`, `{"priority":5,"type":"FOO"}`} {
+		if w := postTenantPriority(r, body); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", body, w.Code)
+		}
+	}
+}
@@ -20,6 +20,7 @@ type jobStatusResponse struct {
 	CompletedAt *time.Time      `json:"completedAt,omitempty"`
 	Payload     json.RawMessage `json:"payload,omitempty"`
 	Result      json.RawMessage `json:"result,omitempty"`
+	Progress    json.RawMessage `json:"progress,omitempty"`
 }
 
 // GetJobStatus handles `GET /api/v1/ai/jobs/{jobId}`. Returns the row
@@ -51,11 +52,12 @@ func (f *Feature) GetJobStatus(w http.ResponseWriter, r *http.Request) {
 		completedAt  sql.NullTime
 		payload      []byte
 		result       []byte
+		progress     []byte
 	)
 	row := f.transcriptionDB.QueryRowContext(r.Context(), sqlGetJobStatus, jobID)
 	if err := row.Scan(
 		&resp.JobID, &resp.TenantID, &resp.Status, &resp.Attempts,
-		&jobErr, &startedAt, &completedAt, &payload, &result,
+		&jobErr, &startedAt, &completedAt, &payload, &result, &progress,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeCustomError(w, http.StatusNotFound, "Job não encontrado", "JOB_NOT_FOUND")
@@ -83,6 +85,9 @@ func (f *Feature) GetJobStatus(w http.ResponseWriter, r *http.Request) {
 	if len(result) > 0 {
 		resp.Result = result
 	}
+	if len(progress) > 0 {
+		resp.Progress = progress
+	}
 
 	writeJSON(w, http.StatusOK, resp)
 }
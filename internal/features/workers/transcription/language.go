@@ -0,0 +1,168 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+
+	"github.com/memberclass-backend-golang/internal/domain/ports"
+)
+
+// defaultTranscriptLanguage is what Whisper decodes in for tenants with
+// neither a forced language nor Tenant.language. Legacy lessons are all
+// pt-BR.
+const defaultTranscriptLanguage = "pt"
+
+// whisperLanguageCodes maps the language names Whisper's verbose_json
+// reports (OpenAI returns "portuguese", not "pt") to ISO-639-1. Only
+// languages our tenants actually sell in are listed; anything else is
+// stored as whatever 2-letter code a self-hosted server sent, or dropped.
+var whisperLanguageCodes = map[string]string{
+	"portuguese": "pt",
+	"spanish":    "es",
+	"english":    "en",
+	"french":     "fr",
+	"italian":    "it",
+	"german":     "de",
+	"dutch":      "nl",
+	"catalan":    "ca",
+	"galician":   "gl",
+	"japanese":   "ja",
+	"chinese":    "zh",
+	"korean":     "ko",
+	"russian":    "ru",
+	"arabic":     "ar",
+	"hebrew":     "he",
+	"hindi":      "hi",
+	"polish":     "pl",
+	"turkish":    "tr",
+	"ukrainian":  "uk",
+}
+
+// captionLabels names the caption track in the Bunny player menu.
+var captionLabels = map[string]string{
+	"pt": "Português",
+	"es": "Español",
+	"en": "English",
+	"fr": "Français",
+	"it": "Italiano",
+	"de": "Deutsch",
+}
+
+// normalizeLanguage turns whatever we get — a Whisper language name, an
+// ISO code, or a tenant locale like "pt-BR" / "es_ES" — into a lowercase
+// ISO-639-1 code. Returns "" when the input is empty or unrecognised.
+func normalizeLanguage(raw string) string {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	if s == "" {
+		return ""
+	}
+	if code, ok := whisperLanguageCodes[s]; ok {
+		return code
+	}
+	if i := strings.IndexAny(s, "-_"); i > 0 {
+		s = s[:i]
+	}
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'z' || s[1] < 'a' || s[1] > 'z' {
+		return ""
+	}
+	return s
+}
+
+// captionLabel returns the player label for a caption track in `lang`,
+// falling back to the code itself.
+func captionLabel(lang string) string {
+	if l, ok := captionLabels[lang]; ok {
+		return l
+	}
+	return lang
+}
+
+// loadForcedLanguages reads TRANSCRIPTION_TENANT_FORCE_LANGUAGE, a comma
+// list of tenantId=code that overrides Tenant.language for those tenants
+// (e.g. a tenant whose admin UI is in Portuguese but whose catalogue is
+// Spanish). Bad entries are logged and ignored, like the other tenant
+// override lists.
+func loadForcedLanguages(log ports.Logger) map[string]string {
+	forced := map[string]string{}
+	for _, pair := range strings.Split(os.Getenv("TRANSCRIPTION_TENANT_FORCE_LANGUAGE"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tenantID, raw, ok := strings.Cut(pair, "=")
+		tenantID, code := strings.TrimSpace(tenantID), normalizeLanguage(raw)
+		if !ok || tenantID == "" || code == "" {
+			log.Warn("transcription: ignoring malformed TRANSCRIPTION_TENANT_FORCE_LANGUAGE entry", "entry", pair)
+			continue
+		}
+		forced[tenantID] = code
+	}
+	return forced
+}
+
+// Where transcriptionLanguage took the language from, recorded in
+// transcripts.metadata.
+const (
+	languageSourceForced  = "forced"
+	languageSourceTenant  = "tenant"
+	languageSourceDefault = "default"
+)
+
+// transcriptionLanguage is the language Whisper decodes the tenant's
+// lessons in: the TRANSCRIPTION_TENANT_FORCE_LANGUAGE entry, then
+// Tenant.language, then defaultTranscriptLanguage. It is always sent,
+// because Whisper's own guess on short or accented audio drifts (pt-BR
+// lessons coming back as Spanish).
+func (f *Feature) transcriptionLanguage(ctx context.Context, tenantID string) (code, source string) {
+	if code := f.forcedLanguages[tenantID]; code != "" {
+		return code, languageSourceForced
+	}
+	if code := f.tenantLanguage(ctx, tenantID); code != "" {
+		return code, languageSourceTenant
+	}
+	return defaultTranscriptLanguage, languageSourceDefault
+}
+
+// tenantLanguage reads Tenant.language and normalizes it. Best effort: a
+// failed lookup returns "".
+func (f *Feature) tenantLanguage(ctx context.Context, tenantID string) string {
+	var lang sql.NullString
+	if err := f.memberclassDB.QueryRowContext(ctx, sqlSelectTenantLanguage, tenantID).Scan(&lang); err != nil {
+		f.log.Warn("transcription.language.tenant_lookup_failed", "tenant", tenantID, "error", err.Error())
+		return ""
+	}
+	return normalizeLanguage(lang.String)
+}
+
+// languageTally accumulates detected audio seconds per language across
+// the parts of one lesson, so a long video whose first window is a
+// music intro still gets the language spoken for most of it.
+type languageTally map[string]float64
+
+func (t languageTally) add(rawLanguage string, seconds float64) {
+	if code := normalizeLanguage(rawLanguage); code != "" {
+		t[code] += seconds
+	}
+}
+
+// pick returns the language with the most seconds, then the hint (the
+// language that was sent), then defaultTranscriptLanguage. Ties break alphabetically so the result is
+// deterministic.
+func (t languageTally) pick(hint string) string {
+	best, bestSecs := "", -1.0
+	for code, secs := range t {
+		if secs > bestSecs || (secs == bestSecs && code < best) {
+			best, bestSecs = code, secs
+		}
+	}
+	switch {
+	case best != "":
+		return best
+	case hint != "":
+		return hint
+	default:
+		return defaultTranscriptLanguage
+	}
+}
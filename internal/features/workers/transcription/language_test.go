@@ -0,0 +1,52 @@
+package transcription
+
+import (
+	"testing"
+
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	cases := map[string]string{
+		"portuguese": "pt",
+		"Spanish":    "es",
+		"en":         "en",
+		"pt-BR":      "pt",
+		"es_ES":      "es",
+		" EN ":       "en",
+		"":           "",
+		"klingon":    "",
+		"p1":         "",
+	}
+	for in, want := range cases {
+		if got := normalizeLanguage(in); got != want {
+			t.Errorf("normalizeLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLanguageTally_Pick(t *testing.T) {
+	tally := languageTally{}
+	tally.add("english", 30)
+	tally.add("spanish", 600)
+	tally.add("es", 600)
+	tally.add("", 100)
+	if got := tally.pick("pt"); got != "es" {
+		t.Fatalf("pick = %q, want es (most seconds)", got)
+	}
+
+	if got := (languageTally{}).pick("en"); got != "en" {
+		t.Fatalf("pick with no detection = %q, want hint", got)
+	}
+	if got := (languageTally{}).pick(""); got != defaultTranscriptLanguage {
+		t.Fatalf("pick with nothing = %q, want default", got)
+	}
+}
+
+func TestLoadForcedLanguages(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_TENANT_FORCE_LANGUAGE", " t1=es , t2=pt-BR,broken,t3=klingon,=en")
+	got := loadForcedLanguages(logger.NewLogger())
+	if len(got) != 2 || got["t1"] != "es" || got["t2"] != "pt" {
+		t.Fatalf("forced = %v", got)
+	}
+}
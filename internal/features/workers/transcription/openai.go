@@ -62,6 +62,18 @@ type whisperResponse struct {
 	Language string           `json:"language"`
 	Duration float64          `json:"duration"`
 	Segments []whisperSegment `json:"segments"`
+	// SourceDuration is not part of the API response: it is set when the
+	// part was silence-trimmed before upload (audio_condition.go) to the
+	// part's length before trimming. Duration stays what was billed.
+	SourceDuration float64 `json:"sourceDuration,omitempty"`
+}
+
+// sourceLength is how much of the video the part covers.
+func (r *whisperResponse) sourceLength() float64 {
+	if r.SourceDuration > 0 {
+		return r.SourceDuration
+	}
+	return r.Duration
 }
 
 // ---------- client methods ----------
@@ -70,10 +82,10 @@ type whisperResponse struct {
 // input (positions matching `inputs`) and the total token count reported
 // by the API (used for cost tracking).
 func (f *Feature) embedBatch(ctx context.Context, inputs []string) ([][]float32, int, error) {
-	dims := f.embedDims
-	if dims <= 0 {
-		dims = defaultEmbedDims
+	if err := f.openaiLimiter.wait(ctx); err != nil {
+		return nil, 0, err
 	}
+	dims := f.embedDimsOrDefault()
 	body, err := json.Marshal(map[string]any{
 		"model":      embedModel,
 		"input":      inputs,
@@ -126,11 +138,47 @@ func (f *Feature) embedBatch(ctx context.Context, inputs []string) ([][]float32,
 	return vecs, parsed.Usage.TotalTokens, nil
 }
 
-// transcribeAudio uploads `audio` (an MP3/M4A reader) to /v1/audio/transcriptions
+// openAITranscriber is the transcriber adapter for OpenAI's hosted
+// whisper-1. It is the default backend; see transcriber.go for how a
+// tenant gets routed elsewhere.
+type openAITranscriber struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	limiter    *rpmLimiter // shared with embedBatch; nil = unlimited
+}
+
+func newOpenAITranscriber(baseURL, apiKey string, client *http.Client, limiter *rpmLimiter) *openAITranscriber {
+	return &openAITranscriber{baseURL: baseURL, apiKey: apiKey, httpClient: client, limiter: limiter}
+}
+
+func (t *openAITranscriber) Name() string  { return transcriberOpenAI }
+func (t *openAITranscriber) Model() string { return whisperModel }
+
+// CostCents bills at OpenAI's whisper-1 per-minute rate (see cost.go).
+func (t *openAITranscriber) CostCents(durationSeconds float64) int {
+	return whisperCostCents(durationSeconds)
+}
+
+// Transcribe uploads `audio` (an MP3/M4A reader) to /v1/audio/transcriptions
 // using whisper-1 in verbose_json mode (so we get segments with timestamps).
 // The `filename` is required by OpenAI's multipart contract; the extension
 // drives format auto-detection on their side.
-func (f *Feature) transcribeAudio(ctx context.Context, audio io.Reader, filename string) (*whisperResponse, error) {
+func (t *openAITranscriber) Transcribe(ctx context.Context, audio io.Reader, filename, language string) (*whisperResponse, error) {
+	if err := t.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	return postTranscription(ctx, t.httpClient, "openai whisper",
+		t.baseURL+"/v1/audio/transcriptions", t.apiKey, whisperModel, audio, filename, language)
+}
+
+// postTranscription speaks the OpenAI /v1/audio/transcriptions multipart
+// contract. Shared by every adapter that talks that wire format (OpenAI
+// itself and the OpenAI-compatible self-hosted servers); `label` prefixes
+// error messages so logs say which backend failed. An empty apiKey skips
+// the Authorization header — self-hosted servers usually run without one.
+// An empty language omits the field so the server detects it.
+func postTranscription(ctx context.Context, client *http.Client, label, endpoint, apiKey, model string, audio io.Reader, filename, language string) (*whisperResponse, error) {
 	var buf bytes.Buffer
 	mw := multipart.NewWriter(&buf)
 
@@ -141,16 +189,19 @@ func (f *Feature) transcribeAudio(ctx context.Context, audio io.Reader, filename
 	if _, err := io.Copy(fw, audio); err != nil {
 		return nil, fmt.Errorf("multipart copy audio: %w", err)
 	}
-	if err := mw.WriteField("model", whisperModel); err != nil {
+	if err := mw.WriteField("model", model); err != nil {
 		return nil, err
 	}
 	if err := mw.WriteField("response_format", "verbose_json"); err != nil {
 		return nil, err
 	}
-	// Force Portuguese; the legacy lessons are all pt-BR and Whisper's
-	// language guess on short / accented audio occasionally drifts to ES.
-	if err := mw.WriteField("language", "pt"); err != nil {
-		return nil, err
+	// Whisper decodes in the given language no matter what is spoken; the
+	// pipeline always sends the tenant's, since its unguided guess on
+	// short / accented audio occasionally drifts (pt-BR to ES).
+	if language != "" {
+		if err := mw.WriteField("language", language); err != nil {
+			return nil, err
+		}
 	}
 	if err := mw.WriteField("timestamp_granularities[]", "segment"); err != nil {
 		return nil, err
@@ -159,28 +210,38 @@ func (f *Feature) transcribeAudio(ctx context.Context, audio io.Reader, filename
 		return nil, fmt.Errorf("multipart close: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
-		f.openaiBaseURL+"/v1/audio/transcriptions", &buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &buf)
 	if err != nil {
-		return nil, fmt.Errorf("build whisper request: %w", err)
+		return nil, fmt.Errorf("build %s request: %w", label, err)
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
 	}
-	req.Header.Set("Authorization", "Bearer "+f.openaiAPIKey)
 	req.Header.Set("Content-Type", mw.FormDataContentType())
 
-	resp, err := f.httpClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("openai whisper http: %w", err)
+		return nil, fmt.Errorf("%s http: %w", label, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("openai whisper status=%d body=%s", resp.StatusCode, string(b))
+		return nil, fmt.Errorf("%s status=%d body=%s", label, resp.StatusCode, string(b))
 	}
 
 	var parsed whisperResponse
 	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-		return nil, fmt.Errorf("openai whisper decode: %w", err)
+		return nil, fmt.Errorf("%s decode: %w", label, err)
 	}
 	return &parsed, nil
 }
+
+// embedDimsOrDefault is the probed chunks.embedding width, or
+// defaultEmbedDims before the probe ran.
+func (f *Feature) embedDimsOrDefault() int {
+	if f.embedDims <= 0 {
+		return defaultEmbedDims
+	}
+	return f.embedDims
+}
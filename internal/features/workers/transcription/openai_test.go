@@ -121,7 +121,7 @@ func TestTranscribeAudio_HappyPath(t *testing.T) {
 		})
 	})
 
-	resp, err := f.transcribeAudio(context.Background(), strings.NewReader("FAKE-MP3-DATA"), "audio.mp3")
+	resp, err := f.transcriberFor("").Transcribe(context.Background(), strings.NewReader("FAKE-MP3-DATA"), "audio.mp3", "pt")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -135,7 +135,7 @@ func TestTranscribeAudio_PropagatesHTTPError(t *testing.T) {
 		_, _ = io.Copy(io.Discard, r.Body)
 		http.Error(w, "audio too large", http.StatusRequestEntityTooLarge)
 	})
-	_, err := f.transcribeAudio(context.Background(), strings.NewReader("data"), "x.mp3")
+	_, err := f.transcriberFor("").Transcribe(context.Background(), strings.NewReader("data"), "x.mp3", "")
 	if err == nil {
 		t.Fatal("want error on 413")
 	}
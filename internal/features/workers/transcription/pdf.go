@@ -0,0 +1,341 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PDF lessons in the search corpus: when the PDF processor converts a
+// lesson it calls IndexLessonPdf, which only queues a PDF_EMBEDDING job.
+// The job downloads the lesson's current PDF, extracts the text of each
+// page (poppler's pdftotext), keeps it in pdf_pages, then chunks and
+// embeds the pages into the same chunks table as the transcripts. PDF
+// chunks carry a page number instead of timestamps, so a search hit can
+// deep-link to the page.
+
+// maxPdfDownloadBytes caps the file pulled for text extraction.
+const maxPdfDownloadBytes = 200 << 20
+
+// ---------- DTOs ----------
+
+// pdfEmbeddingJobPayload is jobs.payload for PDF_EMBEDDING. One job = one
+// lesson; the PDF URL is read at execution time, so a job still pending
+// when the lesson's file is replaced indexes the new one.
+type pdfEmbeddingJobPayload struct {
+	TenantID string `json:"tenantId"`
+	LessonID string `json:"lessonId"`
+	CourseID string `json:"courseId,omitempty"`
+}
+
+// pdfEmbeddingJobResult is jobs.result for PDF_EMBEDDING.
+type pdfEmbeddingJobResult struct {
+	LessonID    string `json:"lessonId"`
+	Pages       int    `json:"pages"`
+	ChunksCount int    `json:"chunksCount"`
+	CostCents   int    `json:"costCents"`
+}
+
+// ---------- 2. Business rule ----------
+
+// IndexLessonPdf implements pdf_processor.PdfTextIndexer: queue a
+// PDF_EMBEDDING job for the lesson when its tenant has AI enabled.
+// Without a transcription DB (the RAG stack is optional) it is a no-op.
+func (f *Feature) IndexLessonPdf(ctx context.Context, lessonID string) error {
+	if f.transcriptionDB == nil || f.memberclassDB == nil {
+		return nil
+	}
+	lesson, err := f.loadPdfLesson(ctx, lessonID)
+	if err != nil {
+		return err
+	}
+
+	tenant, err := f.loadTenant(ctx, lesson.TenantID)
+	if err != nil {
+		return fmt.Errorf("select tenant: %w", err)
+	}
+	if !tenant.AIEnabled {
+		return nil
+	}
+
+	payload, err := json.Marshal(pdfEmbeddingJobPayload{TenantID: lesson.TenantID, LessonID: lessonID, CourseID: lesson.CourseID})
+	if err != nil {
+		return fmt.Errorf("marshal pdf embedding payload: %w", err)
+	}
+	var jobID string
+	err = f.transcriptionDB.QueryRowContext(ctx, sqlInsertPdfEmbeddingJob,
+		uuid.NewString(), lesson.TenantID, 0, payload, 3, lessonID,
+	).Scan(&jobID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("enqueue pdf embedding: %w", err)
+	}
+	f.log.Info("transcription.pdf.enqueued", "tenant", lesson.TenantID, "lessonId", lessonID, "jobId", jobID)
+	return nil
+}
+
+// pdfLesson is a lesson's place in the catalog plus its current media URL.
+type pdfLesson struct {
+	TenantID string
+	CourseID string
+	MediaURL string
+}
+
+func (f *Feature) loadPdfLesson(ctx context.Context, lessonID string) (pdfLesson, error) {
+	var l pdfLesson
+	if err := f.memberclassDB.QueryRowContext(ctx, sqlSelectLessonPdf, lessonID).
+		Scan(&l.TenantID, &l.CourseID, &l.MediaURL); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pdfLesson{}, fmt.Errorf("lesson %s not found", lessonID)
+		}
+		return pdfLesson{}, fmt.Errorf("select lesson: %w", err)
+	}
+	return l, nil
+}
+
+// extractPdfPages downloads the PDF and runs pdftotext over it. pdftotext
+// ends every page with a form feed, so pages[i] is the text of page i+1.
+func (f *Feature) extractPdfPages(ctx context.Context, pdfURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pdfURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build pdf request: %w", err)
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download pdf: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download pdf: status %d", resp.StatusCode)
+	}
+
+	tmp, err := os.CreateTemp("", "lesson-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("create temp pdf: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxPdfDownloadBytes+1))
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("write temp pdf: %w", err)
+	}
+	if n > maxPdfDownloadBytes {
+		return nil, fmt.Errorf("pdf exceeds %d bytes", maxPdfDownloadBytes)
+	}
+
+	cmd := exec.CommandContext(ctx, "pdftotext", "-q", "-enc", "UTF-8", tmp.Name(), "-")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("pdftotext: %w", err)
+	}
+	return splitPdfText(string(out)), nil
+}
+
+// splitPdfText splits pdftotext output into pages. The trailing form feed
+// after the last page would otherwise add an empty extra page.
+func splitPdfText(out string) []string {
+	out = strings.TrimSuffix(out, "\f")
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\f")
+}
+
+// storePdfPages replaces the lesson's stored page text and returns the
+// pages that have any, keyed by page number.
+func (f *Feature) storePdfPages(ctx context.Context, tenantID, lessonID, courseID, pdfURL string, pages []string) (map[int]string, []int, error) {
+	tx, err := f.transcriptionDB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, sqlDeletePdfPages, tenantID, lessonID); err != nil {
+		return nil, nil, fmt.Errorf("delete prior pdf pages: %w", err)
+	}
+	stored := map[int]string{}
+	var pageNumbers []int
+	for i, text := range pages {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			// Scanned pages have no text layer; nothing to search.
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, sqlInsertPdfPage,
+			tenantID, lessonID, nullableString(courseID), i+1, text, nullableString(pdfURL),
+		); err != nil {
+			return nil, nil, fmt.Errorf("insert pdf page %d: %w", i+1, err)
+		}
+		stored[i+1] = text
+		pageNumbers = append(pageNumbers, i+1)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit pdf pages: %w", err)
+	}
+	f.log.Info("transcription.pdf.pages_stored", "tenant", tenantID, "lessonId", lessonID, "pages", len(pageNumbers))
+	return stored, pageNumbers, nil
+}
+
+// splitPdfPages chunks every page on its own, so a chunk never spans two
+// pages and its page number is exact. Non-empty lines play the part of
+// Whisper segments: splitIntoChunks never cuts inside one. Order runs
+// across the whole document.
+func splitPdfPages(pages map[int]string, pageNumbers []int) []chunk {
+	var out []chunk
+	for _, n := range pageNumbers {
+		var segments []whisperSegment
+		for _, line := range strings.Split(pages[n], "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				segments = append(segments, whisperSegment{Text: line})
+			}
+		}
+		for _, c := range splitIntoChunks(segments, 500, 50) {
+			c.Order = len(out)
+			c.StartTime, c.EndTime = 0, 0
+			c.PageNumber = n
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ---------- 3. Worker ----------
+
+// executePdfEmbeddingJob runs a PDF_EMBEDDING job: extract and store the
+// pages of the lesson's current PDF, chunk and embed them and swap the
+// lesson's PDF chunks in one transaction. Video chunks of the same lesson
+// are left alone.
+func (f *Feature) executePdfEmbeddingJob(ctx context.Context, jobID, tenantID string, rawPayload []byte) error {
+	if err := f.preflight(); err != nil {
+		return err
+	}
+
+	var p pdfEmbeddingJobPayload
+	if err := json.Unmarshal(rawPayload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	if p.LessonID == "" {
+		return fmt.Errorf("payload missing lessonId")
+	}
+
+	if err := f.requireJobTenantAI(ctx, tenantID); err != nil {
+		return err
+	}
+
+	lesson, err := f.loadPdfLesson(ctx, p.LessonID)
+	if err != nil {
+		return err
+	}
+	if lesson.TenantID != tenantID {
+		return fmt.Errorf("lesson %s does not belong to tenant %s", p.LessonID, tenantID)
+	}
+	if !strings.HasSuffix(lesson.MediaURL, ".pdf") {
+		return fmt.Errorf("lesson %s has no PDF media URL", p.LessonID)
+	}
+	courseID := lesson.CourseID
+	if courseID == "" {
+		courseID = p.CourseID
+	}
+
+	extract := f.extractPdfPages
+	if f.pdfText != nil {
+		extract = f.pdfText
+	}
+	rawPages, err := extract(ctx, lesson.MediaURL)
+	if err != nil {
+		return err
+	}
+	pages, pageNumbers, err := f.storePdfPages(ctx, tenantID, p.LessonID, courseID, lesson.MediaURL, rawPages)
+	if err != nil {
+		return err
+	}
+	if len(pageNumbers) == 0 {
+		return fmt.Errorf("lesson %s pdf has no text layer", p.LessonID)
+	}
+
+	f.reportProgress(ctx, jobID, jobProgress{Stage: VideoStatusChunking, Percent: progressChunkingPct})
+	chunks := splitPdfPages(pages, pageNumbers)
+	if len(chunks) == 0 {
+		return fmt.Errorf("chunker produced 0 chunks (pages=%d)", len(pageNumbers))
+	}
+	estimateTokens := 0
+	for _, c := range chunks {
+		estimateTokens += c.Tokens
+	}
+	if err := f.checkJobBudget(ctx, tenantID, jobID, embedCostCents(estimateTokens)); err != nil {
+		return err
+	}
+	embedded, err := f.embedChunks(ctx, chunks, nil, func(done, total int) {
+		f.reportProgress(ctx, jobID, embedProgress(done, total))
+	})
+	if err != nil {
+		return err
+	}
+	embedTokens, costCents := embedded.Tokens, embedded.CostCents
+
+	tx, err := f.transcriptionDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, sqlDeletePdfChunks, tenantID, p.LessonID); err != nil {
+		return fmt.Errorf("delete prior pdf chunks: %w", err)
+	}
+	if err := copyChunks(ctx, tx, chunks, embedded.Vectors, chunkRowKeys{
+		TenantID: tenantID,
+		CourseID: courseID,
+		LessonID: p.LessonID,
+	}); err != nil {
+		return err
+	}
+
+	tokenMeta, _ := json.Marshal(map[string]any{
+		"chunks":           len(chunks),
+		"pages":            len(pageNumbers),
+		"lessonId":         p.LessonID,
+		"jobId":            jobID,
+		"embedCacheHits":   embedded.CacheHits,
+		"embedCacheMisses": embedded.CacheMisses,
+	})
+	if _, err := tx.ExecContext(ctx, sqlInsertTokenUsage,
+		uuid.NewString(), tenantID, nullableString(courseID), nil, nil,
+		embedTokens, 0, embedTokens, costCents, 0, costCents,
+		embedModel, "pdf_embed", tokenMeta,
+	); err != nil {
+		return fmt.Errorf("insert token_usage: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit pdf embedding tx: %w", err)
+	}
+
+	result, _ := json.Marshal(pdfEmbeddingJobResult{
+		LessonID:    p.LessonID,
+		Pages:       len(pageNumbers),
+		ChunksCount: len(chunks),
+		CostCents:   costCents,
+	})
+	if err := f.markJobCompleted(ctx, jobID, result); err != nil {
+		if !errors.Is(err, errJobCancelled) {
+			f.log.Error("transcription.pdf.mark_job_failed",
+				"error", err.Error(), "jobId", jobID, "lessonId", p.LessonID)
+		}
+		return err
+	}
+	f.refreshLessonEmbedding(ctx, tenantID, p.LessonID)
+	return nil
+}
@@ -0,0 +1,129 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSplitPdfText(t *testing.T) {
+	got := splitPdfText("Capítulo 1\nFunis\f\fCapítulo 2\f")
+	if want := []string{"Capítulo 1\nFunis", "", "Capítulo 2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("pages = %q, want %q", got, want)
+	}
+	if got := splitPdfText(""); got != nil {
+		t.Fatalf("empty output = %q", got)
+	}
+}
+
+func TestSplitPdfPages_OneChunkSetPerPage(t *testing.T) {
+	pages := map[int]string{
+		1: "Funis de venda\n\n  O topo atrai.  \n",
+		3: "O fundo converte.",
+	}
+	got := splitPdfPages(pages, []int{1, 3})
+	if len(got) != 2 {
+		t.Fatalf("chunks = %+v", got)
+	}
+	if got[0].PageNumber != 1 || got[0].Order != 0 || got[0].Text != "Funis de venda O topo atrai." {
+		t.Fatalf("first chunk = %+v", got[0])
+	}
+	if got[1].PageNumber != 3 || got[1].Order != 1 || got[1].StartTime != 0 || got[1].EndTime != 0 {
+		t.Fatalf("second chunk = %+v", got[1])
+	}
+}
+
+func expectPdfLesson(mcMock sqlmock.Sqlmock, mediaURL string) {
+	mcMock.ExpectQuery(`FROM "Lesson" l`).WithArgs("l1").
+		WillReturnRows(sqlmock.NewRows([]string{"tenantId", "id", "mediaUrl"}).AddRow("t1", "c1", mediaURL))
+}
+
+func TestIndexLessonPdf_OnlyEnqueues(t *testing.T) {
+	fx := newTestFixture(t)
+	f, txMock, mcMock := fx.f, fx.txMock, fx.mcMock
+	f.pdfText = func(context.Context, string) ([]string, error) {
+		t.Fatal("IndexLessonPdf must not download the PDF")
+		return nil, nil
+	}
+
+	expectPdfLesson(mcMock, "https://cdn/x.pdf")
+	fx.expectAITenant(true)
+	payload, _ := json.Marshal(pdfEmbeddingJobPayload{TenantID: "t1", LessonID: "l1", CourseID: "c1"})
+	txMock.ExpectQuery(`INSERT INTO jobs.*PDF_EMBEDDING.*NOT EXISTS`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, payload, 3, "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("job-1"))
+
+	if err := f.IndexLessonPdf(context.Background(), "l1"); err != nil {
+		t.Fatal(err)
+	}
+	// AI disabled: nothing is queued.
+	expectPdfLesson(mcMock, "https://cdn/x.pdf")
+	fx.expectAITenant(false)
+	if err := f.IndexLessonPdf(context.Background(), "l1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecutePdfEmbeddingJob_ExtractsAndSwapsOnlyPdfChunks(t *testing.T) {
+	fx := newTestFixture(t)
+	f, txMock, mcMock := fx.f, fx.txMock, fx.mcMock
+	var extracted string
+	f.pdfText = func(_ context.Context, pdfURL string) ([]string, error) {
+		extracted = pdfURL
+		return []string{"  \n", "", " O fundo converte. "}, nil
+	}
+
+	fx.expectAITenant(true)
+	expectPdfLesson(mcMock, "https://cdn/v2.pdf")
+	txMock.ExpectBegin()
+	txMock.ExpectExec(`DELETE FROM pdf_pages`).WithArgs("t1", "l1").WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`INSERT INTO pdf_pages`).
+		WithArgs("t1", "l1", "c1", 3, "O fundo converte.", "https://cdn/v2.pdf").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+	expectProgress(txMock, "job-1", progressStage{VideoStatusChunking, 80})
+	expectEmbedCacheMiss(txMock)
+	expectEmbedCacheStore(txMock)
+	expectProgress(txMock, "job-1", progressStage{VideoStatusGeneratingEmbeddings, 95})
+	txMock.ExpectBegin()
+	txMock.ExpectExec(`DELETE FROM chunks.*source_type = 'PDF'`).WithArgs("t1", "l1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
+	prep.ExpectExec().WithArgs(
+		sqlmock.AnyArg(), nil, nil, "t1", "c1", "l1", "O fundo converte.", 0, nil, nil,
+		sqlmock.AnyArg(), embedModel, "openai", "{}", sqlmock.AnyArg(), sqlmock.AnyArg(), ChunkSourcePDF, 3,
+	).WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t1", "c1", nil, nil, 4, 0, 4, 1, 0, 1, embedModel, "pdf_embed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+	txMock.ExpectExec(`UPDATE jobs.*SET status.*COMPLETED`).WithArgs("job-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(pdfEmbeddingJobPayload{TenantID: "t1", LessonID: "l1"})
+	if err := f.executePdfEmbeddingJob(context.Background(), "job-1", "t1", payload); err != nil {
+		t.Fatalf("executePdfEmbeddingJob: %v", err)
+	}
+	// The job reads the lesson's current URL, not one captured at enqueue.
+	if extracted != "https://cdn/v2.pdf" {
+		t.Fatalf("extracted %q", extracted)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildAnswerMessages_CitesPdfPage(t *testing.T) {
+	msgs := buildAnswerMessages("onde?", []searchHit{{LessonID: "l1", SourceType: ChunkSourcePDF, PageNumber: 4, Text: "tabela de preços"}})
+	if !strings.Contains(msgs[1].Content, "[1] (aula l1, pág. 4)\ntabela de preços") {
+		t.Fatalf("prompt = %q", msgs[1].Content)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +25,9 @@ type jobPayload struct {
 	VideoURL string `json:"videoUrl"`         // lesson.mediaUrl (Bunny embed URL)
 	CourseID string `json:"courseId,omitempty"`
 	Title    string `json:"title,omitempty"`
+	// EstimatedCostCents is the enqueue-time estimate, set only when the
+	// tenant has a budget cap. It reserves budget while the job is queued.
+	EstimatedCostCents int `json:"estimatedCostCents,omitempty"`
 }
 
 // jobResult is written to jobs.result on COMPLETED. Useful for the GET
@@ -34,12 +38,18 @@ type jobResult struct {
 	ChunksCount  int     `json:"chunksCount"`
 	DurationSecs float64 `json:"durationSeconds"`
 	CostCents    int     `json:"costCents"`
+
+	CaptionsUploaded bool `json:"captionsUploaded,omitempty"`
+
+	// Superseded is set when a re-embed job found its video replaced by a
+	// newer recording of the lesson and did nothing.
+	Superseded bool `json:"superseded,omitempty"`
 }
 
-// resolveAudioFunc lets tests bypass Bunny + ffmpeg by injecting an audio
-// resolver that returns local file paths. Production leaves it nil and the
-// real Bunny meta + HLS download + ffmpeg split runs.
-type resolveAudioFunc func(ctx context.Context, libID, guid, accessKey, tmpDir string) (parts []string, duration float64, err error)
+// resolveAudioFunc lets tests bypass the source resolvers + ffmpeg by
+// injecting an audio resolver that returns local file paths. Production
+// leaves it nil and the lesson's source resolver runs (see source.go).
+type resolveAudioFunc func(ctx context.Context, src videoSource, tmpDir string) (parts []string, duration float64, err error)
 
 // executeJob runs the full pipeline for a single TRANSCRIPTION job that
 // the worker pool has already claimed (status=RUNNING). On success it
@@ -76,56 +86,84 @@ func (f *Feature) executeJob(ctx context.Context, jobID, tenantID string, rawPay
 	if !aiEnabled.Valid || !aiEnabled.Bool {
 		return fmt.Errorf("tenant %s has aiEnabled=false", tenantID)
 	}
-	if !bunnyLibID.Valid || bunnyLibID.String == "" || !bunnyAPIKey.Valid || bunnyAPIKey.String == "" {
-		return fmt.Errorf("tenant %s missing Bunny credentials", tenantID)
+	// Budget guard before anything billable runs; errBudgetExceeded makes
+	// processOne park the job instead of failing it.
+	if err := f.checkJobBudget(ctx, tenantID, jobID, p.EstimatedCostCents); err != nil {
+		return err
 	}
 
-	// 2. Extract libraryId + guid from the iframe URL. We trust the URL
-	// over the tenant's library id; if they disagree it's a config error
-	// the operator needs to fix manually.
-	libID, guid, err := guidFromEmbedURL(p.VideoURL)
+	// 2. Pick the source resolver for the media URL. Bunny needs the
+	// tenant's library credentials; for Bunny we trust the URL over the
+	// tenant's library id — if they disagree it's a config error the
+	// operator needs to fix manually.
+	resolver, src, err := f.detectSource(p.VideoURL)
 	if err != nil {
-		return fmt.Errorf("parse media URL: %w", err)
+		return fmt.Errorf("media URL: %w", err)
 	}
-	if libID != bunnyLibID.String {
-		f.log.Warn("transcription.pipeline.bunny_library_mismatch",
-			"tenant", tenantID, "tenantLibraryId", bunnyLibID.String, "urlLibraryId", libID)
+	if src.Type == SourceTypeBunnyCDN {
+		if !bunnyLibID.Valid || bunnyLibID.String == "" || !bunnyAPIKey.Valid || bunnyAPIKey.String == "" {
+			return fmt.Errorf("tenant %s missing Bunny credentials", tenantID)
+		}
+		if src.LibraryID != bunnyLibID.String {
+			f.log.Warn("transcription.pipeline.bunny_library_mismatch",
+				"tenant", tenantID, "tenantLibraryId", bunnyLibID.String, "urlLibraryId", src.LibraryID)
+		}
+		src.AccessKey = bunnyAPIKey.String
 	}
 
-	// 3. Resolve playable audio. Production: validate via Bunny meta then
-	// pull HLS through ffmpeg. Tests inject testHookResolveAudio to skip
-	// the network round-trip.
+	// 3. Resolve playable audio through the source resolver (Bunny meta +
+	// HLS, or the direct file, via ffmpeg). Tests inject
+	// testHookResolveAudio to skip the network round-trip. A retry whose
+	// every part is checkpointed skips the download altogether.
+	stt := f.transcriberFor(tenantID)
+	ckpt := f.loadCheckpoints(ctx, jobID)
+
 	tmpDir, err := os.MkdirTemp("", "tx_"+jobID+"_")
 	if err != nil {
 		return fmt.Errorf("mktemp: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	parts, duration, err := f.resolveAudio(ctx, libID, guid, bunnyAPIKey.String, tmpDir)
-	if err != nil {
-		return fmt.Errorf("resolve audio: %w", err)
+	var (
+		parts    []string
+		duration float64
+	)
+	if n := ckpt.completeParts(stt.Model()); n > 0 {
+		parts = make([]string, n)
+	} else {
+		f.reportProgress(ctx, jobID, jobProgress{
+			Stage: VideoStatusDownloading, Percent: progressDownloadingPct,
+			Message: "baixando vídeo e extraindo áudio",
+		})
+		parts, duration, err = f.resolveAudio(ctx, resolver, src, tmpDir)
+		if err != nil {
+			return fmt.Errorf("resolve audio: %w", err)
+		}
 	}
 	if len(parts) == 0 {
 		return fmt.Errorf("no audio parts produced")
 	}
 
-	// 4. Transcribe each part; concatenate text + segments with timestamp
-	// offsets so a chunked-audio lesson still produces a single coherent
-	// transcript.
+	// 4. Transcribe the parts concurrently (see transcribeParts), then
+	// concatenate text + segments in part order with timestamp offsets so
+	// a chunked-audio lesson still produces a single coherent transcript.
+	// Silence-trimmed parts come back already on the video's timeline and
+	// offset the next part by their untrimmed length; cost follows the
+	// billed (trimmed) duration.
+	//
+	// Every part is decoded in the tenant's language (see
+	// transcriptionLanguage); the transcript stores what Whisper reports.
+	sentLang, langSource := f.transcriptionLanguage(ctx, tenantID)
+	responses, err := f.transcribeParts(ctx, jobID, stt, parts, sentLang, ckpt)
+	if err != nil {
+		return err
+	}
+	langs := languageTally{}
 	var allSegments []whisperSegment
 	var transcriptText strings.Builder
-	var elapsed float64
+	var elapsed, billed float64
 	costCents := 0
-	for i, part := range parts {
-		fh, err := os.Open(part)
-		if err != nil {
-			return fmt.Errorf("open part %d (%s): %w", i, part, err)
-		}
-		resp, err := f.transcribeAudio(ctx, fh, filepath.Base(part))
-		_ = fh.Close()
-		if err != nil {
-			return fmt.Errorf("whisper part %d: %w", i, err)
-		}
+	for _, resp := range responses {
 		for _, s := range resp.Segments {
 			allSegments = append(allSegments, whisperSegment{
 				Start: s.Start + elapsed,
@@ -135,41 +173,38 @@ func (f *Feature) executeJob(ctx context.Context, jobID, tenantID string, rawPay
 		}
 		transcriptText.WriteString(strings.TrimSpace(resp.Text))
 		transcriptText.WriteString(" ")
-		elapsed += resp.Duration
-		costCents += whisperCostCents(resp.Duration)
+		elapsed += resp.sourceLength()
+		billed += resp.Duration
+		costCents += stt.CostCents(resp.Duration)
+		langs.add(resp.Language, resp.Duration)
 	}
+	language := langs.pick(sentLang)
 	if duration == 0 {
 		duration = elapsed
 	}
 	if transcriptText.Len() == 0 {
 		return fmt.Errorf("whisper returned empty transcript")
 	}
+	// Tenant glossary (glossary.go): fix the brand names and jargon
+	// Whisper mangles before anything is chunked or stored.
+	terms := f.loadGlossary(ctx, tenantID)
+	terms.applySegments(allSegments)
+	fullText := terms.apply(strings.TrimSpace(transcriptText.String()))
 
 	// 5. Chunk + embed.
+	f.reportProgress(ctx, jobID, jobProgress{Stage: VideoStatusChunking, Percent: progressChunkingPct})
 	chunks := splitIntoChunks(allSegments, 500, 50)
 	if len(chunks) == 0 {
 		return fmt.Errorf("chunker produced 0 chunks (segments=%d)", len(allSegments))
 	}
-	embeddings := make([][]float32, len(chunks))
-	for start := 0; start < len(chunks); start += embedBatchSize {
-		end := start + embedBatchSize
-		if end > len(chunks) {
-			end = len(chunks)
-		}
-		texts := make([]string, end-start)
-		for i := range texts {
-			texts[i] = chunks[start+i].Text
-		}
-		vecs, tokens, err := f.embedBatch(ctx, texts)
-		if err != nil {
-			return fmt.Errorf("embed batch [%d:%d]: %w", start, end, err)
-		}
-		if len(vecs) != end-start {
-			return fmt.Errorf("embed batch [%d:%d]: returned %d vectors, want %d", start, end, len(vecs), end-start)
-		}
-		copy(embeddings[start:end], vecs)
-		costCents += embedCostCents(tokens)
+	embedded, err := f.embedChunks(ctx, chunks, ckpt, func(done, total int) {
+		f.reportProgress(ctx, jobID, embedProgress(done, total))
+	})
+	if err != nil {
+		return err
 	}
+	embeddings, embedTokens := embedded.Vectors, embedded.Tokens
+	costCents += embedded.CostCents
 
 	// 6. Persist on the transcription DB in a single transaction so the
 	// chunks/transcript/video rows always land together or not at all.
@@ -183,11 +218,12 @@ func (f *Feature) executeJob(ctx context.Context, jobID, tenantID string, rawPay
 	videoMetadata, _ := json.Marshal(map[string]any{
 		"jobId":         jobID,
 		"embeddingModel": embedModel,
-		"transcriber":   whisperModel,
+		"transcriber":   stt.Model(),
+		"sttProvider":   stt.Name(),
 	})
 	if err := tx.QueryRowContext(ctx, sqlUpsertVideo,
 		videoID, tenantID, p.CourseID, p.LessonID, p.Title,
-		SourceTypeBunnyCDN, p.VideoURL, VideoStatusGeneratingEmbeddings, duration, videoMetadata,
+		src.Type, p.VideoURL, VideoStatusGeneratingEmbeddings, duration, videoMetadata,
 	).Scan(&videoID); err != nil {
 		return fmt.Errorf("upsert video: %w", err)
 	}
@@ -204,52 +240,28 @@ func (f *Feature) executeJob(ctx context.Context, jobID, tenantID string, rawPay
 
 	transcriptID := uuid.NewString()
 	segmentsJSON, _ := json.Marshal(allSegments)
-	transcriptMeta, _ := json.Marshal(map[string]any{"jobId": jobID})
+	transcriptMeta, _ := json.Marshal(map[string]any{"jobId": jobID, "requestedLanguage": sentLang, "languageSource": langSource, "billedSecs": billed})
 	if _, err := tx.ExecContext(ctx, sqlInsertTranscript,
 		transcriptID, videoID, tenantID, p.LessonID,
-		strings.TrimSpace(transcriptText.String()),
-		"pt", whisperModel, nil, segmentsJSON, elapsed, transcriptMeta,
+		fullText,
+		language, stt.Model(), nil, segmentsJSON, elapsed, transcriptMeta,
 	); err != nil {
 		return fmt.Errorf("insert transcript: %w", err)
 	}
 
-	// Bulk-insert chunks. lib/pq's CopyIn is the fastest path for
-	// thousands of rows with vectors; the column order MUST match
-	// chunksColumns exactly.
-	stmt, err := tx.PrepareContext(ctx, pq.CopyInSchema("public", chunksTable, chunksColumns...))
-	if err != nil {
-		return fmt.Errorf("prepare copyin: %w", err)
-	}
-	now := time.Now()
-	for i, c := range chunks {
-		if _, err := stmt.ExecContext(ctx,
-			uuid.NewString(),
-			videoID,
-			transcriptID,
-			tenantID,
-			nullableString(p.CourseID),
-			p.LessonID,
-			c.Text,
-			c.Order,
-			c.StartTime,
-			c.EndTime,
-			pgvectorString(embeddings[i]),
-			embedModel,
-			"openai",
-			"{}",
-			now,
-			now,
-		); err != nil {
-			_ = stmt.Close()
-			return fmt.Errorf("copy chunk %d: %w", i, err)
-		}
-	}
-	if _, err := stmt.ExecContext(ctx); err != nil {
-		_ = stmt.Close()
-		return fmt.Errorf("flush copyin: %w", err)
+	if err := copyChunks(ctx, tx, chunks, embeddings, chunkRowKeys{
+		VideoID:      videoID,
+		TranscriptID: transcriptID,
+		TenantID:     tenantID,
+		CourseID:     p.CourseID,
+		LessonID:     p.LessonID,
+	}); err != nil {
+		return err
 	}
-	if err := stmt.Close(); err != nil {
-		return fmt.Errorf("close copyin: %w", err)
+	// A lesson whose mediaUrl changed gets a new videos row; retire the
+	// chunks of the recording it replaced.
+	if _, err := tx.ExecContext(ctx, sqlDeleteSupersededChunks, tenantID, p.LessonID, videoID); err != nil {
+		return fmt.Errorf("delete superseded chunks: %w", err)
 	}
 
 	if _, err := tx.ExecContext(ctx, sqlUpdateVideoStatus, videoID, VideoStatusCompleted, ""); err != nil {
@@ -257,13 +269,20 @@ func (f *Feature) executeJob(ctx context.Context, jobID, tenantID string, rawPay
 	}
 
 	tokenMeta, _ := json.Marshal(map[string]any{
-		"chunks":   len(chunks),
-		"duration": elapsed,
+		"chunks":           len(chunks),
+		"duration":         elapsed,
+		"billedSecs":       billed,
+		"sttProvider":      stt.Name(),
+		"embedCacheHits":   embedded.CacheHits,
+		"embedCacheMisses": embedded.CacheMisses,
 	})
+	// prompt/total tokens carry the embedding tokens (Whisper bills by the
+	// minute, recorded as metadata.billedSecs: the audio actually sent,
+	// after silence trimming); the usage report reads both.
 	if _, err := tx.ExecContext(ctx, sqlInsertTokenUsage,
 		uuid.NewString(), tenantID, nullableString(p.CourseID), videoID, transcriptID,
-		0, 0, 0, costCents, 0, costCents,
-		whisperModel+"+"+embedModel, "transcribe+embed", tokenMeta,
+		embedTokens, 0, embedTokens, costCents, 0, costCents,
+		stt.Model()+"+"+embedModel, "transcribe+embed", tokenMeta,
 	); err != nil {
 		return fmt.Errorf("insert token_usage: %w", err)
 	}
@@ -280,60 +299,328 @@ func (f *Feature) executeJob(ctx context.Context, jobID, tenantID string, rawPay
 			"error", err.Error(), "lessonId", p.LessonID, "videoId", videoID)
 	}
 
-	// 8. Final: mark the job COMPLETED with a result blob the GET
+	// 8. Optionally push the transcript to the Bunny player as closed
+	// captions (TRANSCRIPTION_UPLOAD_CAPTIONS). Best effort, like step 7;
+	// other sources have no player to push to.
+	captionsUploaded := false
+	if src.Type == SourceTypeBunnyCDN {
+		captionsUploaded = f.uploadCaptions(ctx, src.LibraryID, src.GUID, src.AccessKey, language, allSegments)
+	}
+
+	// 9. Final: mark the job COMPLETED with a result blob the GET
 	// /jobs/{id} handler can serialize back to the caller.
 	result, _ := json.Marshal(jobResult{
-		VideoID:      videoID,
-		TranscriptID: transcriptID,
-		ChunksCount:  len(chunks),
-		DurationSecs: elapsed,
-		CostCents:    costCents,
+		VideoID:          videoID,
+		TranscriptID:     transcriptID,
+		ChunksCount:      len(chunks),
+		DurationSecs:     elapsed,
+		CostCents:        costCents,
+		CaptionsUploaded: captionsUploaded,
 	})
-	if _, err := f.transcriptionDB.ExecContext(ctx, sqlMarkJobCompleted, jobID, result); err != nil {
-		f.log.Error("transcription.pipeline.mark_job_failed",
-			"error", err.Error(), "jobId", jobID, "videoId", videoID)
-		return fmt.Errorf("mark job completed: %w", err)
+	if err := f.markJobCompleted(ctx, jobID, result); err != nil {
+		if !errors.Is(err, errJobCancelled) {
+			f.log.Error("transcription.pipeline.mark_job_failed",
+				"error", err.Error(), "jobId", jobID, "videoId", videoID)
+		}
+		return err
 	}
+	ckpt.clear(ctx)
+
+	// 10. Refresh the lesson-level embedding behind related lessons
+	// (related.go), then queue the LLM summary as its own job
+	// (summary.go), so a slow or failing chat call never holds up or fails
+	// the transcription.
+	f.refreshLessonEmbedding(ctx, tenantID, p.LessonID)
+	f.queueSummaryAfterTranscription(ctx, summaryJobPayload{
+		TenantID: tenantID,
+		VideoID:  videoID,
+		LessonID: p.LessonID,
+		CourseID: p.CourseID,
+	})
 	return nil
 }
 
-// resolveAudio either delegates to the test hook or runs the real Bunny
-// validation + HLS download + ffmpeg pipeline.
-func (f *Feature) resolveAudio(ctx context.Context, libID, guid, accessKey, tmpDir string) ([]string, float64, error) {
-	if f.testHookResolveAudio != nil {
-		return f.testHookResolveAudio(ctx, libID, guid, accessKey, tmpDir)
-	}
+// transcribeParts sends up to f.whisperConcurrency parts at a time to the
+// speech-to-text backend and returns the responses in part order.
+// Checkpointed parts are reused, fresh ones checkpointed as they land.
+// The first failure cancels the parts still in flight; the OpenAI adapter
+// also waits on the shared per-process rate limiter before each call.
+func (f *Feature) transcribeParts(ctx context.Context, jobID string, stt transcriber, parts []string, language string, ckpt *jobCheckpoints) ([]*whisperResponse, error) {
+	concurrency := f.whisperConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultWhisperConcurrency
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*whisperResponse, len(parts))
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+		firstErr error
+	)
+	f.reportProgress(ctx, jobID, transcribeProgress(0, len(parts)))
+
+	// A single writer owns the checkpoint and progress writes, so the DB
+	// round trips never run under mu and the part counter only goes up.
+	type landedPart struct {
+		index int
+		resp  *whisperResponse
+		fresh bool
+	}
+	landed := make(chan landedPart, len(parts))
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		done := 0
+		for l := range landed {
+			if l.fresh {
+				ckpt.savePart(ctx, l.index, len(parts), stt.Model(), l.resp)
+			}
+			done++
+			f.reportProgress(ctx, jobID, transcribeProgress(done, len(parts)))
+		}
+	}()
+
+	for i, part := range parts {
+		if resp, ok := ckpt.part(i, len(parts), stt.Model()); ok {
+			mu.Lock()
+			responses[i] = resp
+			mu.Unlock()
+			landed <- landedPart{index: i, resp: resp}
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break // a part failed (or the job was cancelled); don't start more
+		}
+		wg.Add(1)
+		go func(i int, part string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := f.transcribeConditionedPart(ctx, stt, part, language)
+			mu.Lock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s part %d: %w", stt.Name(), i, err)
+					cancel()
+				}
+				mu.Unlock()
+				return
+			}
+			responses[i] = resp
+			mu.Unlock()
+			landed <- landedPart{index: i, resp: resp, fresh: true}
+		}(i, part)
+	}
+	wg.Wait()
+	close(landed)
+	<-writerDone
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
 
-	meta, err := f.fetchBunnyVideoMeta(ctx, libID, guid, accessKey)
+// transcribeConditionedPart trims the part's silences and normalizes its
+// loudness before transcribing it when f.trimSilence is on, then maps the
+// segments back to the part's own timeline. A failed ffmpeg pass only
+// costs the savings: the untouched part is transcribed instead.
+func (f *Feature) transcribeConditionedPart(ctx context.Context, stt transcriber, part, language string) (*whisperResponse, error) {
+	if !f.trimSilence {
+		return transcribePart(ctx, stt, part, language)
+	}
+	conditioned := strings.TrimSuffix(part, filepath.Ext(part)) + "-conditioned.mp3"
+	remap, err := conditionAudio(ctx, part, conditioned)
 	if err != nil {
-		return nil, 0, err
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		f.log.Warn("transcription.pipeline.condition_failed", "part", filepath.Base(part), "error", err.Error())
+		return transcribePart(ctx, stt, part, language)
 	}
-	playback, err := f.resolveBunnyPlayback(ctx, libID)
+	defer os.Remove(conditioned)
+	resp, err := transcribePart(ctx, stt, conditioned, language)
 	if err != nil {
-		return nil, 0, fmt.Errorf("resolve CDN playback for library %s: %w", libID, err)
+		return nil, err
 	}
-	// 1h TTL covers download of a 4-hour aula at minimum bitrate; tokens
-	// only need to be valid long enough for ffmpeg to finish pulling.
-	hlsURL := buildHLSURL(playback, guid, time.Now(), time.Hour)
+	remap.apply(resp)
+	return resp, nil
+}
 
-	full := filepath.Join(tmpDir, "audio.mp3")
-	if _, err := extractAudioMP3(ctx, hlsURL, full); err != nil {
-		return nil, 0, err
+func transcribePart(ctx context.Context, stt transcriber, part, language string) (*whisperResponse, error) {
+	fh, err := os.Open(part)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", part, err)
 	}
+	defer fh.Close()
+	return stt.Transcribe(ctx, fh, filepath.Base(part), language)
+}
 
-	info, err := os.Stat(full)
-	if err != nil {
-		return nil, 0, err
+// embedResult is what embedChunks produced: one vector per chunk, the
+// embedding tokens and cost of this run, and how many chunks came from
+// the embedding cache.
+type embedResult struct {
+	Vectors     [][]float32
+	Tokens      int
+	CostCents   int
+	CacheHits   int
+	CacheMisses int
+}
+
+// embedChunks embeds every chunk and returns one vector per chunk plus
+// the summed tokens and cost. Chunk texts found in the embedding cache
+// are reused for free; the remaining distinct texts go out in
+// embedBatchSize requests and are cached. Cost is rounded per batch, the
+// same granularity OpenAI bills at. Batches found in ckpt (nil disables
+// checkpointing) are reused and still counted, since that spend was never
+// recorded by the failed attempt. onBatch, when non-nil, is called after
+// each request batch with (batches done, batches total).
+func (f *Feature) embedChunks(ctx context.Context, chunks []chunk, ckpt *jobCheckpoints, onBatch func(done, total int)) (embedResult, error) {
+	dims := f.embedDimsOrDefault()
+	res := embedResult{Vectors: make([][]float32, len(chunks))}
+
+	hashes := make([]string, len(chunks))
+	for i, c := range chunks {
+		hashes[i] = embedCacheKey(c.Text)
 	}
-	if info.Size() <= whisperMaxAudioBytes {
-		return []string{full}, meta.Length, nil
+	cached := f.lookupEmbeddings(ctx, dims, hashes)
+
+	// Distinct uncached texts, in chunk order.
+	var missHashes, missTexts []string
+	seen := make(map[string]bool)
+	for i, c := range chunks {
+		if _, ok := cached[hashes[i]]; ok || seen[hashes[i]] {
+			continue
+		}
+		seen[hashes[i]] = true
+		missHashes = append(missHashes, hashes[i])
+		missTexts = append(missTexts, c.Text)
 	}
 
-	parts, err := splitAudioByDuration(ctx, full, tmpDir, 600)
+	fresh := make(map[string][]float32, len(missTexts))
+	batches, done := (len(missTexts)+embedBatchSize-1)/embedBatchSize, 0
+	for start := 0; start < len(missTexts); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(missTexts) {
+			end = len(missTexts)
+		}
+		texts := missTexts[start:end]
+		key := embedBatchKey(dims, texts)
+		vecs, tokens, ok := ckpt.batch(done, key)
+		if !ok {
+			var err error
+			vecs, tokens, err = f.embedBatch(ctx, texts)
+			if err != nil {
+				return embedResult{}, fmt.Errorf("embed batch [%d:%d]: %w", start, end, err)
+			}
+			if len(vecs) == end-start {
+				ckpt.saveBatch(ctx, done, key, vecs, tokens)
+			}
+		}
+		if len(vecs) != end-start {
+			return embedResult{}, fmt.Errorf("embed batch [%d:%d]: returned %d vectors, want %d", start, end, len(vecs), end-start)
+		}
+		f.storeEmbeddings(ctx, dims, missHashes[start:end], vecs)
+		for i, v := range vecs {
+			fresh[missHashes[start+i]] = v
+		}
+		res.Tokens += tokens
+		res.CostCents += embedCostCents(tokens)
+		done++
+		if onBatch != nil {
+			onBatch(done, batches)
+		}
+	}
+
+	for i := range chunks {
+		if v, ok := cached[hashes[i]]; ok {
+			res.Vectors[i] = v
+			res.CacheHits++
+			continue
+		}
+		res.Vectors[i] = fresh[hashes[i]]
+	}
+	res.CacheMisses = len(chunks) - res.CacheHits
+	return res, nil
+}
+
+// chunkRowKeys are the foreign keys every chunk row of one video shares.
+type chunkRowKeys struct {
+	VideoID      string
+	TranscriptID string
+	TenantID     string
+	CourseID     string
+	LessonID     string
+}
+
+// copyChunks bulk-inserts chunks inside tx. lib/pq's CopyIn is the fastest
+// path for thousands of rows with vectors; the column order MUST match
+// chunksColumns exactly. A chunk with a PageNumber is stored as a PDF
+// chunk: no video, transcript or timestamps.
+func copyChunks(ctx context.Context, tx *sql.Tx, chunks []chunk, embeddings [][]float32, k chunkRowKeys) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyInSchema("public", chunksTable, chunksColumns...))
 	if err != nil {
-		return nil, 0, err
+		return fmt.Errorf("prepare copyin: %w", err)
+	}
+	now := time.Now()
+	for i, c := range chunks {
+		var (
+			sourceType             = ChunkSourceVideo
+			startTime, endTime any = c.StartTime, c.EndTime
+			pageNumber         any
+		)
+		if c.PageNumber > 0 {
+			sourceType, startTime, endTime, pageNumber = ChunkSourcePDF, nil, nil, c.PageNumber
+		}
+		if _, err := stmt.ExecContext(ctx,
+			uuid.NewString(),
+			nullableString(k.VideoID),
+			nullableString(k.TranscriptID),
+			k.TenantID,
+			nullableString(k.CourseID),
+			k.LessonID,
+			c.Text,
+			c.Order,
+			startTime,
+			endTime,
+			pgvectorString(embeddings[i]),
+			embedModel,
+			"openai",
+			"{}",
+			now,
+			now,
+			sourceType,
+			pageNumber,
+		); err != nil {
+			_ = stmt.Close()
+			return fmt.Errorf("copy chunk %d: %w", i, err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return fmt.Errorf("flush copyin: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("close copyin: %w", err)
+	}
+	return nil
+}
+
+// resolveAudio produces the job's audio parts and duration through the
+// source resolver, or the test hook when set.
+func (f *Feature) resolveAudio(ctx context.Context, resolver sourceResolver, src videoSource, tmpDir string) ([]string, float64, error) {
+	if f.testHookResolveAudio != nil {
+		return f.testHookResolveAudio(ctx, src, tmpDir)
 	}
-	return parts, meta.Length, nil
+	return resolver.Resolve(ctx, src, tmpDir)
 }
 
 // pgvectorString encodes a float32 slice in the literal `[v1,v2,...]`
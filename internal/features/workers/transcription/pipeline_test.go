@@ -2,25 +2,47 @@ package transcription
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
 )
 
+// usageMetaBilledSecs matches a token_usage.metadata carrying the given
+// billed seconds.
+type usageMetaBilledSecs struct{ secs float64 }
+
+func (m usageMetaBilledSecs) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var meta struct {
+		BilledSecs float64 `json:"billedSecs"`
+	}
+	return json.Unmarshal(b, &meta) == nil && meta.BilledSecs == m.secs
+}
+
 // fakeAudio writes a tiny non-empty MP3 fixture and returns it as the only
 // audio part. Bypasses Bunny + ffmpeg entirely so the test can exercise
 // the SQL + OpenAI portion of the pipeline.
 func fakeAudio(t *testing.T) resolveAudioFunc {
 	t.Helper()
-	return func(ctx context.Context, libID, guid, accessKey, tmpDir string) ([]string, float64, error) {
+	return func(ctx context.Context, src videoSource, tmpDir string) ([]string, float64, error) {
 		part := filepath.Join(tmpDir, "fake.mp3")
 		if err := os.WriteFile(part, []byte("ID3FAKE"), 0o600); err != nil {
 			t.Fatal(err)
@@ -94,6 +116,26 @@ func TestExecuteJob_HappyPath(t *testing.T) {
 		WithArgs(tenantID).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
 			AddRow(tenantID, "Tenant Foo", true, "383534", "tenant-bunny-key"))
+	mcMock.ExpectQuery(`SELECT "language"`).
+		WithArgs(tenantID).
+		WillReturnRows(sqlmock.NewRows([]string{"language"}).AddRow("pt-BR"))
+
+	// First attempt: no checkpoints. Progress and a checkpoint per part
+	// and embed batch interleave as the pipeline runs.
+	expectNoCheckpoints(txMock, jobID)
+	expectProgress(txMock, jobID,
+		progressStage{VideoStatusDownloading, 5},
+		progressStage{VideoStatusTranscribing, 20},
+	)
+	expectSaveCheckpoint(txMock, jobID, checkpointTranscriptPart, 0)
+	expectProgress(txMock, jobID,
+		progressStage{VideoStatusTranscribing, 80},
+		progressStage{VideoStatusChunking, 80},
+	)
+	expectEmbedCacheMiss(txMock)
+	expectSaveCheckpoint(txMock, jobID, checkpointEmbedBatch, 0)
+	expectEmbedCacheStore(txMock)
+	expectProgress(txMock, jobID, progressStage{VideoStatusGeneratingEmbeddings, 95})
 
 	// Transcription DB: BEGIN
 	txMock.ExpectBegin()
@@ -110,8 +152,14 @@ func TestExecuteJob_HappyPath(t *testing.T) {
 	prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
 	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
 	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM chunks.*video_id <>`).WithArgs(tenantID, lessonID, "video-uuid-1").WillReturnResult(sqlmock.NewResult(0, 0))
 	txMock.ExpectExec(`UPDATE videos`).WillReturnResult(sqlmock.NewResult(0, 1))
-	txMock.ExpectExec(`INSERT INTO token_usage`).WillReturnResult(sqlmock.NewResult(0, 1))
+	// Minutes in the usage report come from the seconds billed by Whisper.
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			sqlmock.AnyArg(), sqlmock.AnyArg(), "transcribe+embed", usageMetaBilledSecs{secs: 5}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	txMock.ExpectCommit()
 
 	// Memberclass UPDATE Lesson
@@ -121,6 +169,7 @@ func TestExecuteJob_HappyPath(t *testing.T) {
 
 	// Mark job COMPLETED
 	txMock.ExpectExec(`UPDATE jobs.*SET status.*COMPLETED`).WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM job_checkpoints`).WithArgs(jobID).WillReturnResult(sqlmock.NewResult(0, 2))
 
 	payload, _ := json.Marshal(jobPayload{
 		LessonID: lessonID,
@@ -140,6 +189,97 @@ func TestExecuteJob_HappyPath(t *testing.T) {
 	}
 }
 
+// stubTranscriber is a local stand-in for the speech-to-text port so the
+// pipeline can run without any Whisper server at all.
+type stubTranscriber struct {
+	calls int
+}
+
+func (s *stubTranscriber) Name() string          { return "stub" }
+func (s *stubTranscriber) Model() string         { return "stub-whisper" }
+func (s *stubTranscriber) CostCents(float64) int { return 0 }
+func (s *stubTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename, language string) (*whisperResponse, error) {
+	s.calls++
+	return &whisperResponse{
+		Text:     "oi mundo",
+		Duration: 5,
+		Segments: []whisperSegment{{Start: 0, End: 5, Text: "oi mundo"}},
+	}, nil
+}
+
+func TestExecuteJob_UsesTenantTranscriber(t *testing.T) {
+	transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer transcriptionDB.Close()
+	memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer memberclassDB.Close()
+
+	// Only embeddings reach the fake OpenAI; a Whisper call here means the
+	// tenant override was ignored.
+	openai := newFakeOpenAIForPipeline(t)
+	defer openai.Close()
+
+	stub := &stubTranscriber{}
+	f := &Feature{
+		transcriptionDB: transcriptionDB,
+		memberclassDB:   memberclassDB,
+		log:             logger.NewLogger(),
+		openaiAPIKey:    "test-key",
+		openaiBaseURL:   openai.URL,
+		httpClient:      openai.Client(),
+		stt: sttConfig{
+			backends: map[string]transcriber{
+				transcriberOpenAI: newOpenAITranscriber("https://whisper.invalid", "k", openai.Client(), nil),
+				"stub":            stub,
+			},
+			fallback: transcriberOpenAI,
+			tenants:  map[string]string{"t": "stub"},
+		},
+		testHookResolveAudio: fakeAudio(t),
+	}
+
+	mcMock.ExpectQuery(`FROM "Tenant"`).
+		WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t", "n", true, "lib", "key"))
+	mcMock.ExpectQuery(`SELECT "language"`).
+		WithArgs("t").
+		WillReturnRows(sqlmock.NewRows([]string{"language"}).AddRow(nil))
+	txMock.ExpectBegin()
+	txMock.ExpectQuery(`INSERT INTO videos`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("v1"))
+	txMock.ExpectExec(`DELETE FROM chunks`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`DELETE FROM transcripts`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`INSERT INTO transcripts`).
+		WithArgs(sqlmock.AnyArg(), "v1", "t", "l", "oi mundo", "pt", "stub-whisper",
+			nil, sqlmock.AnyArg(), 5.0, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM chunks.*video_id <>`).WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`UPDATE videos`).WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`INSERT INTO token_usage`).WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+	mcMock.ExpectExec(`UPDATE "Lesson"`).WithArgs("l").WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`UPDATE jobs.*COMPLETED`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(jobPayload{
+		LessonID: "l", TenantID: "t",
+		VideoURL: "https://iframe.mediadelivery.net/embed/lib/guid",
+	})
+	if err := f.executeJob(context.Background(), "j", "t", payload); err != nil {
+		t.Fatalf("executeJob failed: %v", err)
+	}
+	if stub.calls != 1 {
+		t.Fatalf("stub transcriber calls = %d, want 1", stub.calls)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mcMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestExecuteJob_FailsWhenTenantAINotEnabled(t *testing.T) {
 	transcriptionDB, _, _ := sqlmock.New()
 	defer transcriptionDB.Close()
@@ -196,3 +336,181 @@ func TestPgvectorString(t *testing.T) {
 	}
 }
 
+func TestExecuteJob_SendsTenantLanguage(t *testing.T) {
+	cases := []struct {
+		name           string
+		tenantLanguage any
+		forced         map[string]string
+		wantSent       string
+	}{
+		{name: "tenant language", tenantLanguage: "es_ES", wantSent: "es"},
+		{name: "forced overrides tenant", tenantLanguage: "pt-BR", forced: map[string]string{"t": "en"}, wantSent: "en"},
+		{name: "default when tenant has none", tenantLanguage: nil, wantSent: "pt"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			defer transcriptionDB.Close()
+			memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+			defer memberclassDB.Close()
+
+			openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/v1/audio/transcriptions":
+					if err := r.ParseMultipartForm(1 << 20); err != nil {
+						t.Errorf("parse multipart: %v", err)
+					}
+					if got := r.FormValue("language"); got != tc.wantSent {
+						t.Errorf("language sent = %q, want %q", got, tc.wantSent)
+					}
+					// Whisper reports the language it was told to decode in.
+					_ = json.NewEncoder(w).Encode(whisperResponse{
+						Text: "hello world", Language: r.FormValue("language"), Duration: 5,
+						Segments: []whisperSegment{{Start: 0, End: 5, Text: "hello world"}},
+					})
+				case "/v1/embeddings":
+					_ = json.NewEncoder(w).Encode(embeddingsResponse{
+						Data:  []embedding{{Index: 0, Embedding: []float32{0.1}}},
+						Usage: usage{TotalTokens: 2},
+					})
+				}
+			}))
+			defer openai.Close()
+
+			f := &Feature{
+				transcriptionDB:      transcriptionDB,
+				memberclassDB:        memberclassDB,
+				log:                  logger.NewLogger(),
+				openaiAPIKey:         "test-key",
+				openaiBaseURL:        openai.URL,
+				httpClient:           openai.Client(),
+				forcedLanguages:      tc.forced,
+				testHookResolveAudio: fakeAudio(t),
+			}
+
+			mcMock.ExpectQuery(`FROM "Tenant"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+					AddRow("t", "n", true, "lib", "key"))
+			if tc.forced == nil {
+				mcMock.ExpectQuery(`SELECT "language"`).
+					WillReturnRows(sqlmock.NewRows([]string{"language"}).AddRow(tc.tenantLanguage))
+			}
+			txMock.ExpectBegin()
+			txMock.ExpectQuery(`INSERT INTO videos`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("v1"))
+			txMock.ExpectExec(`DELETE FROM chunks`).WillReturnResult(sqlmock.NewResult(0, 0))
+			txMock.ExpectExec(`DELETE FROM transcripts`).WillReturnResult(sqlmock.NewResult(0, 0))
+			txMock.ExpectExec(`INSERT INTO transcripts`).
+				WithArgs(sqlmock.AnyArg(), "v1", "t", "l", "hello world", tc.wantSent, whisperModel,
+					nil, sqlmock.AnyArg(), 5.0, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
+			prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+			txMock.ExpectExec(`DELETE FROM chunks.*video_id <>`).WillReturnResult(sqlmock.NewResult(0, 0))
+			txMock.ExpectExec(`UPDATE videos`).WillReturnResult(sqlmock.NewResult(0, 1))
+			txMock.ExpectExec(`INSERT INTO token_usage`).WillReturnResult(sqlmock.NewResult(0, 1))
+			txMock.ExpectCommit()
+			mcMock.ExpectExec(`UPDATE "Lesson"`).WillReturnResult(sqlmock.NewResult(0, 1))
+			txMock.ExpectExec(`UPDATE jobs.*COMPLETED`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+			payload, _ := json.Marshal(jobPayload{
+				LessonID: "l", TenantID: "t",
+				VideoURL: "https://iframe.mediadelivery.net/embed/lib/guid",
+			})
+			if err := f.executeJob(context.Background(), "j", "t", payload); err != nil {
+				t.Fatalf("executeJob failed: %v", err)
+			}
+			if err := txMock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+			if err := mcMock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// slowTranscriber answers each part with its own index after a delay and
+// records the peak number of concurrent calls.
+type slowTranscriber struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	failPart string
+}
+
+func (s *slowTranscriber) Name() string          { return "slow" }
+func (s *slowTranscriber) Model() string         { return "slow-whisper" }
+func (s *slowTranscriber) CostCents(float64) int { return 0 }
+func (s *slowTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename, language string) (*whisperResponse, error) {
+	s.mu.Lock()
+	s.inFlight++
+	if s.inFlight > s.peak {
+		s.peak = s.inFlight
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+	if filename == s.failPart {
+		return nil, errors.New("boom")
+	}
+	select {
+	case <-time.After(20 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	b, _ := io.ReadAll(audio)
+	return &whisperResponse{Text: string(b), Duration: 600}, nil
+}
+
+func writeParts(t *testing.T, n int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = filepath.Join(dir, fmt.Sprintf("part_%03d.mp3", i))
+		if err := os.WriteFile(parts[i], []byte(strconv.Itoa(i)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return parts
+}
+
+// newPartsFeature has a DB that accepts nothing: progress writes fail and
+// are logged, which is all transcribeParts needs.
+func newPartsFeature(t *testing.T, concurrency int) *Feature {
+	t.Helper()
+	db, _, _ := sqlmock.New()
+	t.Cleanup(func() { db.Close() })
+	return &Feature{transcriptionDB: db, log: logger.NewLogger(), whisperConcurrency: concurrency}
+}
+
+func TestTranscribeParts_BoundedConcurrencyInOrder(t *testing.T) {
+	f := newPartsFeature(t, 3)
+	stt := &slowTranscriber{}
+	resps, err := f.transcribeParts(context.Background(), "", stt, writeParts(t, 7), "pt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range resps {
+		if r.Text != strconv.Itoa(i) {
+			t.Fatalf("part %d came back as %q", i, r.Text)
+		}
+	}
+	if stt.peak != 3 {
+		t.Fatalf("peak concurrency = %d, want 3", stt.peak)
+	}
+}
+
+func TestTranscribeParts_FirstErrorWins(t *testing.T) {
+	f := newPartsFeature(t, 2)
+	stt := &slowTranscriber{failPart: "part_001.mp3"}
+	_, err := f.transcribeParts(context.Background(), "", stt, writeParts(t, 5), "", nil)
+	if err == nil || !strings.Contains(err.Error(), "slow part 1: boom") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package transcription
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -18,9 +19,9 @@ import (
 //   - lessonIds populated: enqueue only those (used when the admin
 //     picked specific rows). Each id is validated against the tenant
 //     and surfaced in `enqueued` or `skipped` with a reason.
-//   - lessonIds empty: enqueue ALL Bunny-backed, unprocessed lessons
-//     for the tenant. Admin UI uses this for "transcrever todas as
-//     pendentes" buttons.
+//   - lessonIds empty: enqueue ALL unprocessed video lessons (Bunny or
+//     a direct MP4/HLS URL, see source.go) for the tenant. Admin UI uses
+//     this for "transcrever todas as pendentes" buttons.
 type processLessonsRequest struct {
 	TenantID  string   `json:"tenantId"`
 	LessonIDs []string `json:"lessonIds,omitempty"`
@@ -56,8 +57,8 @@ type processLessonsResponse struct {
 //   - For each lessonId, looks up the row (joined through Vitrine for
 //     tenant ownership) and either enqueues a VIDEO_PROCESSING job on
 //     the Railway pgvector jobs table OR records a `skipped` entry
-//     with a reason (wrong tenant, already transcribed, non-Bunny URL,
-//     unknown id).
+//     with a reason (wrong tenant, already transcribed, unsupported
+//     video provider, unknown id).
 //   - Returns 202 with the split lists so the admin UI can surface a
 //     summary instead of guessing.
 func (f *Feature) ProcessLessonsTenant(w http.ResponseWriter, r *http.Request) {
@@ -100,6 +101,19 @@ func (f *Feature) ProcessLessonsTenant(w http.ResponseWriter, r *http.Request) {
 	} else {
 		resp, status, err = f.enqueueSelectedLessons(r.Context(), req.TenantID, req.LessonIDs)
 	}
+	var overBudget *budgetExceededError
+	if errors.As(err, &overBudget) {
+		writeJSON(w, http.StatusPaymentRequired, map[string]any{
+			"ok":             false,
+			"error":          err.Error(),
+			"errorCode":      "BUDGET_EXCEEDED",
+			"capCents":       overBudget.CapCents,
+			"spentCents":     overBudget.SpentCents,
+			"reservedCents":  overBudget.ReservedCents,
+			"estimatedCents": overBudget.EstimatedCents,
+		})
+		return
+	}
 	if err != nil {
 		writeError(w, status, http.StatusText(status), err.Error())
 		return
@@ -109,23 +123,18 @@ func (f *Feature) ProcessLessonsTenant(w http.ResponseWriter, r *http.Request) {
 
 // ---------- 2. Business rule ----------
 
-// enqueueAllUnprocessed inserts one VIDEO_PROCESSING job per Bunny-
-// backed lesson under the tenant whose transcriptionCompleted flag is
-// still false. Used by the admin UI's "transcribe all pending" action.
+// enqueueAllUnprocessed inserts one VIDEO_PROCESSING job per video
+// lesson under the tenant whose transcriptionCompleted flag is still
+// false. Lessons of a known but unsupported provider (YouTube, Vimeo,
+// Panda) come back as skipped with the reason; URLs that are not videos
+// at all are left out. Used by the admin UI's "transcribe all pending"
+// action.
 // Returns the same response shape as the selected-lessons path so the
 // frontend can render a single summary table either way.
 func (f *Feature) enqueueAllUnprocessed(ctx context.Context, tenantID string) (*processLessonsResponse, int, error) {
-	var (
-		tID, tName              string
-		aiEnabled               bool
-		bunnyLibID, bunnyAPIKey *string
-	)
-	row := f.memberclassDB.QueryRowContext(ctx, sqlSelectTenantBunnyCreds, tenantID)
-	if err := row.Scan(&tID, &tName, &aiEnabled, &bunnyLibID, &bunnyAPIKey); err != nil {
-		return nil, http.StatusNotFound, fmt.Errorf("tenant não encontrado")
-	}
-	if !aiEnabled {
-		return nil, http.StatusForbidden, fmt.Errorf("IA não está habilitada para este tenant")
+	tenant, status, err := f.requireAITenant(ctx, tenantID)
+	if err != nil {
+		return nil, status, err
 	}
 
 	rows, err := f.memberclassDB.QueryContext(ctx, sqlSelectUnprocessedLessons, tenantID)
@@ -140,7 +149,10 @@ func (f *Feature) enqueueAllUnprocessed(ctx context.Context, tenantID string) (*
 		MediaURL string
 		CourseID string
 	}
-	var lessons []lessonRow
+	var (
+		lessons []lessonRow
+		skipped = make([]skippedLesson, 0)
+	)
 	for rows.Next() {
 		var (
 			l                                                                                  lessonRow
@@ -156,6 +168,13 @@ func (f *Feature) enqueueAllUnprocessed(ctx context.Context, tenantID string) (*
 		); err != nil {
 			return nil, http.StatusInternalServerError, fmt.Errorf("scan lesson: %w", err)
 		}
+		if _, _, err := f.detectSource(l.MediaURL); err != nil {
+			var unsupported *unsupportedSourceError
+			if errors.As(err, &unsupported) && unsupported.Provider != "" {
+				skipped = append(skipped, skippedLesson{LessonID: l.ID, Reason: unsupported.Reason})
+			}
+			continue
+		}
 		lessons = append(lessons, l)
 	}
 	if err := rows.Err(); err != nil {
@@ -165,20 +184,30 @@ func (f *Feature) enqueueAllUnprocessed(ctx context.Context, tenantID string) (*
 	resp := &processLessonsResponse{
 		TenantID: tenantID,
 		Enqueued: make([]enqueuedLesson, 0, len(lessons)),
-		Skipped:  make([]skippedLesson, 0),
+		Skipped:  skipped,
 	}
 	if len(lessons) == 0 {
 		resp.Message = "Nenhuma lesson pendente encontrada para este tenant"
 		return resp, http.StatusOK, nil
 	}
 
+	batch := make([]budgetLesson, 0, len(lessons))
+	for _, l := range lessons {
+		batch = append(batch, budgetLesson{ID: l.ID, MediaURL: l.MediaURL})
+	}
+	estimates, err := f.checkBatchBudget(ctx, tenantID, tenant.BunnyAPIKey, batch)
+	if err != nil {
+		return nil, budgetErrorStatus(err), err
+	}
+
 	for _, l := range lessons {
 		payload, err := json.Marshal(jobPayload{
-			LessonID: l.ID,
-			TenantID: tenantID,
-			VideoURL: l.MediaURL,
-			CourseID: l.CourseID,
-			Title:    l.Name,
+			LessonID:           l.ID,
+			TenantID:           tenantID,
+			VideoURL:           l.MediaURL,
+			CourseID:           l.CourseID,
+			Title:              l.Name,
+			EstimatedCostCents: estimates[l.ID],
 		})
 		if err != nil {
 			resp.Skipped = append(resp.Skipped, skippedLesson{LessonID: l.ID, Reason: "erro interno ao serializar payload"})
@@ -207,19 +236,11 @@ func (f *Feature) enqueueAllUnprocessed(ctx context.Context, tenantID string) (*
 }
 
 func (f *Feature) enqueueSelectedLessons(ctx context.Context, tenantID string, lessonIDs []string) (*processLessonsResponse, int, error) {
-	// Validate the tenant has aiEnabled. The same query the pipeline
-	// runs is reused so error codes stay consistent.
-	var (
-		tID, tName              string
-		aiEnabled               bool
-		bunnyLibID, bunnyAPIKey *string
-	)
-	row := f.memberclassDB.QueryRowContext(ctx, sqlSelectTenantBunnyCreds, tenantID)
-	if err := row.Scan(&tID, &tName, &aiEnabled, &bunnyLibID, &bunnyAPIKey); err != nil {
-		return nil, http.StatusNotFound, fmt.Errorf("tenant não encontrado")
-	}
-	if !aiEnabled {
-		return nil, http.StatusForbidden, fmt.Errorf("IA não está habilitada para este tenant")
+	// Validate the tenant has aiEnabled. The guard is shared with the
+	// other admin endpoints so error codes stay consistent.
+	tenant, status, err := f.requireAITenant(ctx, tenantID)
+	if err != nil {
+		return nil, status, err
 	}
 
 	// Pull lessons that match the id set AND belong to this tenant
@@ -266,12 +287,18 @@ func (f *Feature) enqueueSelectedLessons(ctx context.Context, tenantID string, l
 		Skipped:  make([]skippedLesson, 0),
 	}
 
+	// Only lessons that would actually be enqueued count against the
+	// budget; the budget check runs on that set before any insert.
+	var (
+		eligible []lessonRow
+		batch    []budgetLesson
+	)
 	for _, id := range lessonIDs {
 		l, ok := found[id]
 		if !ok {
 			resp.Skipped = append(resp.Skipped, skippedLesson{
 				LessonID: id,
-				Reason:   "lesson não encontrada para este tenant ou mediaUrl não é Bunny",
+				Reason:   "lesson não encontrada para este tenant ou sem mediaUrl",
 			})
 			continue
 		}
@@ -282,13 +309,27 @@ func (f *Feature) enqueueSelectedLessons(ctx context.Context, tenantID string, l
 			})
 			continue
 		}
+		if _, _, err := f.detectSource(l.MediaURL); err != nil {
+			resp.Skipped = append(resp.Skipped, skippedLesson{LessonID: id, Reason: err.Error()})
+			continue
+		}
+		eligible = append(eligible, l)
+		batch = append(batch, budgetLesson{ID: l.ID, MediaURL: l.MediaURL})
+	}
+	estimates, err := f.checkBatchBudget(ctx, tenantID, tenant.BunnyAPIKey, batch)
+	if err != nil {
+		return nil, budgetErrorStatus(err), err
+	}
 
+	for _, l := range eligible {
+		id := l.ID
 		payload, err := json.Marshal(jobPayload{
-			LessonID: l.ID,
-			TenantID: tenantID,
-			VideoURL: l.MediaURL,
-			CourseID: l.CourseID,
-			Title:    l.Name,
+			LessonID:           l.ID,
+			TenantID:           tenantID,
+			VideoURL:           l.MediaURL,
+			CourseID:           l.CourseID,
+			Title:              l.Name,
+			EstimatedCostCents: estimates[l.ID],
 		})
 		if err != nil {
 			f.log.Error("transcription.enqueue.marshal_failed", "lesson", l.ID, "error", err.Error())
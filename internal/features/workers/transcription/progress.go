@@ -0,0 +1,422 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ---------- DTOs ----------
+
+// jobProgress is the live state of a RUNNING job, stored in jobs.progress
+// (migrations/transcription/005_job_progress.sql). Stage reuses the
+// VideoStatus* names. It is left in place when the job fails so operators
+// can see which stage broke; the next attempt overwrites it.
+type jobProgress struct {
+	Stage   string `json:"stage"`
+	Percent int    `json:"percent"`
+	Part    int    `json:"part,omitempty"`
+	Parts   int    `json:"parts,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// jobEvent is the data of a `job` SSE event: one job's row as the stream
+// last read it.
+type jobEvent struct {
+	JobID    string          `json:"jobId"`
+	LessonID string          `json:"lessonId,omitempty"`
+	Status   string          `json:"status"`
+	Attempts int             `json:"attempts"`
+	Error    string          `json:"error,omitempty"`
+	Progress json.RawMessage `json:"progress,omitempty"`
+}
+
+// batchSummary is the data of a `summary` SSE event on the tenant stream.
+// Percent averages every job in the stream (finished jobs count as 100).
+type batchSummary struct {
+	TenantID   string `json:"tenantId"`
+	Total      int    `json:"total"`
+	Pending    int    `json:"pending"`
+	Running    int    `json:"running"`
+	OverBudget int    `json:"overBudget"`
+	Completed  int    `json:"completed"`
+	Failed     int    `json:"failed"`
+	Cancelled  int    `json:"cancelled"`
+	Percent    int    `json:"percent"`
+}
+
+// Progress bands per stage. Download + audio extraction is a single
+// ffmpeg pass, so it only reports its start.
+const (
+	progressDownloadingPct    = 5
+	progressTranscribeFrom    = 20
+	progressTranscribeTo      = 80
+	progressChunkingPct       = 80
+	progressEmbedFrom         = 85
+	progressEmbedTo           = 95
+	defaultStreamPollInterval = 2 * time.Second
+	// streamHeartbeatInterval keeps proxies from closing an idle stream
+	// while a long Whisper part is running.
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+// ---------- 1. Pipeline side ----------
+
+// reportProgress stores p on the job row. Best effort: a failed write only
+// costs the operator a stale progress bar, never the job.
+func (f *Feature) reportProgress(ctx context.Context, jobID string, p jobProgress) {
+	raw, _ := json.Marshal(p)
+	if _, err := f.transcriptionDB.ExecContext(ctx, sqlUpdateJobProgress, jobID, raw); err != nil {
+		f.log.Warn("transcription.progress.update_failed", "jobId", jobID, "stage", p.Stage, "error", err.Error())
+	}
+}
+
+// transcribeProgress is the TRANSCRIBING event after `done` of `parts`
+// audio parts came back from the speech-to-text backend.
+func transcribeProgress(done, parts int) jobProgress {
+	return jobProgress{
+		Stage:   VideoStatusTranscribing,
+		Percent: bandPercent(progressTranscribeFrom, progressTranscribeTo, done, parts),
+		Part:    done,
+		Parts:   parts,
+		Message: fmt.Sprintf("parte %d/%d transcrita", done, parts),
+	}
+}
+
+// embedProgress is the GENERATING_EMBEDDINGS event after `done` of
+// `batches` embedding requests.
+func embedProgress(done, batches int) jobProgress {
+	return jobProgress{
+		Stage:   VideoStatusGeneratingEmbeddings,
+		Percent: bandPercent(progressEmbedFrom, progressEmbedTo, done, batches),
+		Part:    done,
+		Parts:   batches,
+		Message: fmt.Sprintf("lote %d/%d de embeddings", done, batches),
+	}
+}
+
+func bandPercent(from, to, done, total int) int {
+	if total <= 0 {
+		return from
+	}
+	return from + (to-from)*done/total
+}
+
+// ---------- 2. HTTP handlers ----------
+
+// StreamJobEvents handles `GET /api/v1/ai/jobs/{jobId}/events`.
+//
+// Server-Sent Events stream of one job. Sends a `job` event every time the
+// row's status, attempts or progress change (the first one right away),
+// then `done` once the job is COMPLETED, FAILED or CANCELLED and closes.
+// The row is polled, so the stream works whichever replica runs the job.
+func (f *Feature) StreamJobEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+	jobID := chi.URLParam(r, "jobId")
+	if jobID == "" {
+		writeCustomError(w, http.StatusBadRequest, "jobId é obrigatório", "MISSING_JOB_ID")
+		return
+	}
+
+	ctx := r.Context()
+	ev, err := f.loadJobEvent(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeCustomError(w, http.StatusNotFound, "Job não encontrado", "JOB_NOT_FOUND")
+			return
+		}
+		f.log.Error("transcription.progress.load_failed", "jobId", jobID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	s, ok := startSSE(w)
+	if !ok {
+		return
+	}
+	last := ""
+	f.streamLoop(ctx, s, func() (bool, error) {
+		if ev == nil {
+			if ev, err = f.loadJobEvent(ctx, jobID); err != nil {
+				return false, err
+			}
+		}
+		if fp := ev.fingerprint(); fp != last {
+			last = fp
+			if err := s.send("job", ev); err != nil {
+				return true, nil
+			}
+		}
+		if isTerminalJobStatus(ev.Status) {
+			_ = s.send("done", ev)
+			return true, nil
+		}
+		ev = nil
+		return false, nil
+	})
+}
+
+// StreamTenantJobEvents handles `GET /api/v1/ai/tenants/{tenantId}/jobs/events`.
+//
+// Server-Sent Events stream of a tenant's batch: every PENDING, RUNNING or
+// OVER_BUDGET job, plus the ones that finish while the stream is open.
+// Sends a `job` event per changed row and a `summary` event with the
+// batch counts after each change. Sends `done` and closes once nothing is
+// PENDING or RUNNING (OVER_BUDGET jobs can wait for days, so they don't
+// hold the stream open).
+func (f *Feature) StreamTenantJobEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+	tenantID := chi.URLParam(r, "tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+
+	ctx := r.Context()
+	var openedAt time.Time
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectDBNow).Scan(&openedAt); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	s, ok := startSSE(w)
+	if !ok {
+		return
+	}
+	sent := make(map[string]string)
+	jobs := make(map[string]*jobEvent)
+	var order []string
+	f.streamLoop(ctx, s, func() (bool, error) {
+		evs, err := f.loadTenantJobEvents(ctx, tenantID, openedAt)
+		if err != nil {
+			return false, err
+		}
+		changed := false
+		for _, ev := range evs {
+			fp := ev.fingerprint()
+			if sent[ev.JobID] == fp {
+				continue
+			}
+			if _, seen := sent[ev.JobID]; !seen {
+				order = append(order, ev.JobID)
+			}
+			sent[ev.JobID] = fp
+			jobs[ev.JobID] = ev
+			changed = true
+			if err := s.send("job", ev); err != nil {
+				return true, nil
+			}
+		}
+		sum := summarizeBatch(tenantID, order, jobs)
+		if changed {
+			if err := s.send("summary", sum); err != nil {
+				return true, nil
+			}
+		}
+		if sum.Pending+sum.Running == 0 {
+			_ = s.send("done", sum)
+			return true, nil
+		}
+		return false, nil
+	})
+}
+
+// ---------- 3. Business rule ----------
+
+func (f *Feature) loadJobEvent(ctx context.Context, jobID string) (*jobEvent, error) {
+	var (
+		ev       jobEvent
+		lessonID sql.NullString
+		jobErr   sql.NullString
+		progress []byte
+	)
+	err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectJobProgress, jobID).
+		Scan(&ev.JobID, &lessonID, &ev.Status, &ev.Attempts, &jobErr, &progress)
+	if err != nil {
+		return nil, err
+	}
+	ev.LessonID = lessonID.String
+	ev.Error = jobErr.String
+	if len(progress) > 0 {
+		ev.Progress = progress
+	}
+	return &ev, nil
+}
+
+func (f *Feature) loadTenantJobEvents(ctx context.Context, tenantID string, since time.Time) ([]*jobEvent, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectTenantJobsProgress, tenantID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []*jobEvent
+	for rows.Next() {
+		var (
+			ev       jobEvent
+			lessonID sql.NullString
+			jobErr   sql.NullString
+			progress []byte
+		)
+		if err := rows.Scan(&ev.JobID, &lessonID, &ev.Status, &ev.Attempts, &jobErr, &progress); err != nil {
+			return nil, err
+		}
+		ev.LessonID = lessonID.String
+		ev.Error = jobErr.String
+		if len(progress) > 0 {
+			ev.Progress = progress
+		}
+		out = append(out, &ev)
+	}
+	return out, rows.Err()
+}
+
+// fingerprint is what the streams diff on to decide whether to resend.
+func (ev *jobEvent) fingerprint() string {
+	return fmt.Sprintf("%s|%d|%s|%s", ev.Status, ev.Attempts, ev.Error, ev.Progress)
+}
+
+func (ev *jobEvent) percent() int {
+	switch ev.Status {
+	case JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+		return 100
+	case JobStatusRunning:
+		var p jobProgress
+		if json.Unmarshal(ev.Progress, &p) == nil {
+			return p.Percent
+		}
+	}
+	return 0
+}
+
+func summarizeBatch(tenantID string, order []string, jobs map[string]*jobEvent) batchSummary {
+	sum := batchSummary{TenantID: tenantID, Total: len(order)}
+	pct := 0
+	for _, id := range order {
+		ev := jobs[id]
+		switch ev.Status {
+		case JobStatusPending:
+			sum.Pending++
+		case JobStatusRunning:
+			sum.Running++
+		case JobStatusOverBudget:
+			sum.OverBudget++
+		case JobStatusCompleted:
+			sum.Completed++
+		case JobStatusFailed:
+			sum.Failed++
+		case JobStatusCancelled:
+			sum.Cancelled++
+		}
+		pct += ev.percent()
+	}
+	if sum.Total > 0 {
+		sum.Percent = pct / sum.Total
+	}
+	return sum
+}
+
+func isTerminalJobStatus(status string) bool {
+	return status == JobStatusCompleted || status == JobStatusFailed || status == JobStatusCancelled
+}
+
+// ---------- 4. SSE plumbing ----------
+
+type sseStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// startSSE writes the event-stream headers. Fails with 500 when the
+// response can't be flushed incrementally.
+func startSSE(w http.ResponseWriter) (*sseStream, bool) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return nil, false
+	}
+	return &sseStream{w: w, rc: rc}, true
+}
+
+func (s *sseStream) send(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func (s *sseStream) ping() error {
+	if _, err := fmt.Fprint(s.w, ": ping\n\n"); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// streamLoop runs poll right away and then every stream poll interval
+// until it reports done or the client goes away, pinging in between. Poll
+// errors are logged and retried on the next tick, like watchCancellation.
+func (f *Feature) streamLoop(ctx context.Context, s *sseStream, poll func() (bool, error)) {
+	interval := f.streamPollInterval
+	if interval <= 0 {
+		interval = defaultStreamPollInterval
+	}
+	pollT := time.NewTicker(interval)
+	defer pollT.Stop()
+	pingT := time.NewTicker(streamHeartbeatInterval)
+	defer pingT.Stop()
+
+	for {
+		done, err := poll()
+		if err != nil && ctx.Err() == nil {
+			f.log.Warn("transcription.progress.poll_failed", "error", err.Error())
+		}
+		if done {
+			return
+		}
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				return
+			case <-pingT.C:
+				if s.ping() != nil {
+					return
+				}
+			case <-pollT.C:
+				waiting = false
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package transcription
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// progressStage matches a jobs.progress value at the given stage/percent.
+type progressStage struct {
+	stage   string
+	percent int
+}
+
+func (m progressStage) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+	var p jobProgress
+	return json.Unmarshal(b, &p) == nil && p.Stage == m.stage && p.Percent == m.percent
+}
+
+// expectProgress queues one progress UPDATE per stage, in order.
+func expectProgress(mock sqlmock.Sqlmock, jobID string, stages ...progressStage) {
+	for _, s := range stages {
+		mock.ExpectExec(`UPDATE jobs.*SET progress`).
+			WithArgs(jobID, s).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+func TestProgressBands(t *testing.T) {
+	if p := transcribeProgress(3, 7); p.Percent != 45 || p.Part != 3 || p.Parts != 7 || p.Message != "parte 3/7 transcrita" {
+		t.Fatalf("transcribeProgress(3, 7) = %+v", p)
+	}
+	if p := transcribeProgress(7, 7); p.Percent != progressTranscribeTo {
+		t.Fatalf("last part percent = %d", p.Percent)
+	}
+	if p := embedProgress(1, 2); p.Percent != 90 || p.Stage != VideoStatusGeneratingEmbeddings {
+		t.Fatalf("embedProgress(1, 2) = %+v", p)
+	}
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	jobs := map[string]*jobEvent{
+		"a": {JobID: "a", Status: JobStatusCompleted},
+		"b": {JobID: "b", Status: JobStatusRunning, Progress: json.RawMessage(`{"stage":"TRANSCRIBING","percent":50}`)},
+		"c": {JobID: "c", Status: JobStatusPending},
+		"d": {JobID: "d", Status: JobStatusFailed},
+	}
+	got := summarizeBatch("t1", []string{"a", "b", "c", "d"}, jobs)
+	want := batchSummary{TenantID: "t1", Total: 4, Pending: 1, Running: 1, Completed: 1, Failed: 1, Percent: 62}
+	if got != want {
+		t.Fatalf("summary = %+v, want %+v", got, want)
+	}
+}
+
+var jobProgressColumns = []string{"id", "lesson_id", "status", "attempts", "error", "progress"}
+
+func streamRequest(t *testing.T, path string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("x-internal-api-key", "k")
+	return req
+}
+
+func TestStreamJobEvents_StreamsUntilTerminal(t *testing.T) {
+	f, mock, r := newJobControlRouter(t)
+	f.streamPollInterval = time.Millisecond
+	mock.ExpectQuery(`FROM jobs`).WithArgs("j1").
+		WillReturnRows(sqlmock.NewRows(jobProgressColumns).
+			AddRow("j1", "l1", JobStatusRunning, 1, nil, []byte(`{"stage":"TRANSCRIBING","percent":45,"part":3,"parts":7}`)))
+	// Same row again: no duplicate event.
+	mock.ExpectQuery(`FROM jobs`).WithArgs("j1").
+		WillReturnRows(sqlmock.NewRows(jobProgressColumns).
+			AddRow("j1", "l1", JobStatusRunning, 1, nil, []byte(`{"stage":"TRANSCRIBING","percent":45,"part":3,"parts":7}`)))
+	mock.ExpectQuery(`FROM jobs`).WithArgs("j1").
+		WillReturnRows(sqlmock.NewRows(jobProgressColumns).
+			AddRow("j1", "l1", JobStatusCompleted, 1, nil, []byte(`{"stage":"GENERATING_EMBEDDINGS","percent":95}`)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, streamRequest(t, "/jobs/j1/events"))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content-type = %q", ct)
+	}
+	body := w.Body.String()
+	if n := strings.Count(body, "event: job\n"); n != 2 {
+		t.Fatalf("job events = %d, body:\n%s", n, body)
+	}
+	if !strings.Contains(body, `"part":3,"parts":7`) || !strings.HasSuffix(body, "\n\n") ||
+		!strings.Contains(body, "event: done\ndata: {\"jobId\":\"j1\",\"lessonId\":\"l1\",\"status\":\"COMPLETED\"") {
+		t.Fatalf("body:\n%s", body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStreamJobEvents_NotFound(t *testing.T) {
+	_, mock, r := newJobControlRouter(t)
+	mock.ExpectQuery(`FROM jobs`).WithArgs("nope").WillReturnRows(sqlmock.NewRows(jobProgressColumns))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, streamRequest(t, "/jobs/nope/events"))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "JOB_NOT_FOUND") {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStreamTenantJobEvents_SummarizesBatch(t *testing.T) {
+	f, mock, r := newJobControlRouter(t)
+	f.streamPollInterval = time.Millisecond
+	// The cursor comes from the database clock updated_at is written with.
+	openedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectQuery(`SELECT now\(\)`).WillReturnRows(sqlmock.NewRows([]string{"now"}).AddRow(openedAt))
+	mock.ExpectQuery(`FROM jobs.*tenant_id = \$1`).WithArgs("t1", openedAt).
+		WillReturnRows(sqlmock.NewRows(jobProgressColumns).
+			AddRow("j1", "l1", JobStatusRunning, 1, nil, []byte(`{"stage":"CHUNKING","percent":80}`)).
+			AddRow("j2", "l2", JobStatusPending, 0, nil, nil))
+	mock.ExpectQuery(`FROM jobs.*tenant_id = \$1`).WithArgs("t1", openedAt).
+		WillReturnRows(sqlmock.NewRows(jobProgressColumns).
+			AddRow("j1", "l1", JobStatusCompleted, 1, nil, []byte(`{"stage":"GENERATING_EMBEDDINGS","percent":95}`)).
+			AddRow("j2", "l2", JobStatusFailed, 3, "boom", nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, streamRequest(t, "/tenants/t1/jobs/events"))
+
+	body := w.Body.String()
+	if n := strings.Count(body, "event: job\n"); n != 4 {
+		t.Fatalf("job events = %d, body:\n%s", n, body)
+	}
+	if n := strings.Count(body, "event: summary\n"); n != 2 {
+		t.Fatalf("summary events = %d, body:\n%s", n, body)
+	}
+	if !strings.Contains(body, `"percent":40}`) {
+		t.Fatalf("first summary should average 80 and 0, body:\n%s", body)
+	}
+	if !strings.Contains(body, "event: done\ndata: {\"tenantId\":\"t1\",\"total\":2,\"pending\":0,\"running\":0,\"overBudget\":0,\"completed\":1,\"failed\":1,\"cancelled\":0,\"percent\":100}") {
+		t.Fatalf("body:\n%s", body)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,495 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Quiz drafts: multiple-choice questions generated from a lesson's
+// transcript chunks through the chatCompleter port, each with its answer
+// key and the timestamp of the chunk it came from. Drafts live in the
+// transcription DB's quiz_drafts table for an admin to review; nothing is
+// written to the memberclass Quiz tables.
+
+// Review states of a quiz draft.
+const (
+	QuizDraftStatusDraft    = "DRAFT"
+	QuizDraftStatusApproved = "APPROVED"
+	QuizDraftStatusRejected = "REJECTED"
+)
+
+const (
+	quizDefaultQuestions = 5
+	quizMaxQuestions     = 20
+
+	// quizMaxContextChunks bounds the prompt: longer lessons are sampled
+	// evenly so questions cover the whole lesson, not just its start.
+	quizMaxContextChunks = 24
+
+	// quizOutputTokensPerQuestion prices the reply for the budget check.
+	quizOutputTokensPerQuestion = 200
+
+	quizMinOptions = 3
+	quizMaxOptions = 6
+)
+
+// quizSystemPrompt pins the questions to the numbered excerpts; the
+// `source` number is how each question gets its timestamp back.
+const quizSystemPrompt = `Você cria questões de múltipla escolha para alunos a partir dos trechos numerados de uma aula.
+Responda APENAS com um objeto JSON neste formato:
+{"questions": [{"question": "...", "options": ["...", "...", "...", "..."], "answer": 0, "explanation": "...", "source": 1}]}
+Regras:
+- Escreva no mesmo idioma dos trechos.
+- Cada questão tem 4 alternativas e exatamente uma correta; "answer" é o índice (a partir de 0) da correta.
+- "source" é o número do trecho que contém a resposta.
+- Use apenas o conteúdo dos trechos e espalhe as questões pela aula.
+- "explanation" explica em uma frase por que a alternativa está correta.`
+
+// ---------- DTOs ----------
+
+// generateQuizRequest is the body of POST /lessons/{lessonId}/quiz-drafts.
+type generateQuizRequest struct {
+	TenantID  string `json:"tenantId"`
+	Questions int    `json:"questions,omitempty"`
+}
+
+// quizQuestion is one generated question. AnswerIndex points into
+// Options; ChunkID/StartTime/EndTime locate the source in the video.
+type quizQuestion struct {
+	Question    string   `json:"question"`
+	Options     []string `json:"options"`
+	AnswerIndex int      `json:"answerIndex"`
+	Explanation string   `json:"explanation,omitempty"`
+	ChunkID     string   `json:"chunkId"`
+	StartTime   float64  `json:"startTime"`
+	EndTime     float64  `json:"endTime"`
+}
+
+// quizDraft is one generation run, reviewed as a unit.
+type quizDraft struct {
+	ID         string         `json:"id"`
+	TenantID   string         `json:"tenantId"`
+	LessonID   string         `json:"lessonId"`
+	VideoID    string         `json:"videoId"`
+	Status     string         `json:"status"`
+	Model      string         `json:"model"`
+	Questions  []quizQuestion `json:"questions"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	ReviewedAt *time.Time     `json:"reviewedAt,omitempty"`
+}
+
+// reviewQuizDraftRequest is the body of PATCH /quiz-drafts/{draftId}.
+type reviewQuizDraftRequest struct {
+	TenantID string `json:"tenantId"`
+	Status   string `json:"status"`
+}
+
+// quizChunk is a transcript chunk offered to the model as a source.
+type quizChunk struct {
+	ID        string
+	VideoID   string
+	Text      string
+	StartTime float64
+	EndTime   float64
+}
+
+// ---------- 1. HTTP handlers ----------
+
+// GenerateQuizDraft handles `POST /api/v1/ai/lessons/{lessonId}/quiz-drafts`.
+//
+// Body: { tenantId, questions? } — questions defaults to 5, max 20.
+//
+// Generates the questions synchronously (one chat completion) and stores
+// them as a DRAFT. 404 when the lesson has no transcript chunks.
+func (f *Feature) GenerateQuizDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	limitBody(w, r)
+	var req generateQuizRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.TenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	if req.Questions <= 0 {
+		req.Questions = quizDefaultQuestions
+	}
+	if req.Questions > quizMaxQuestions {
+		writeCustomError(w, http.StatusBadRequest,
+			fmt.Sprintf("questions excede o limite de %d por chamada", quizMaxQuestions),
+			"TOO_MANY_QUESTIONS")
+		return
+	}
+
+	draft, status, err := f.generateQuizDraft(r.Context(), req, lessonID)
+	if errors.Is(err, errBudgetExceeded) {
+		writeCustomError(w, http.StatusPaymentRequired, err.Error(), "BUDGET_EXCEEDED")
+		return
+	}
+	if err != nil {
+		writeError(w, status, http.StatusText(status), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, draft)
+}
+
+// ListQuizDrafts handles `GET /api/v1/ai/lessons/{lessonId}/quiz-drafts?tenantId=…&status=…`.
+//
+// Newest first; status (DRAFT, APPROVED or REJECTED) is optional.
+func (f *Feature) ListQuizDrafts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	tenantID := r.URL.Query().Get("tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	status := strings.ToUpper(r.URL.Query().Get("status"))
+	if status != "" && !validQuizDraftStatus(status) {
+		writeCustomError(w, http.StatusBadRequest, "status deve ser DRAFT, APPROVED ou REJECTED", "INVALID_STATUS")
+		return
+	}
+
+	drafts, err := f.listQuizDrafts(r.Context(), tenantID, lessonID, status)
+	if err != nil {
+		f.log.Error("transcription.quiz.list_failed", "tenant", tenantID, "lesson", lessonID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"lessonId": lessonID, "drafts": drafts})
+}
+
+// ReviewQuizDraft handles `PATCH /api/v1/ai/quiz-drafts/{draftId}`.
+//
+// Body: { tenantId, status } — records the admin's decision. Approving
+// does not publish anything; the admin UI copies approved questions into
+// a real Quiz.
+func (f *Feature) ReviewQuizDraft(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	draftID := chi.URLParam(r, "draftId")
+	if draftID == "" {
+		writeCustomError(w, http.StatusBadRequest, "draftId é obrigatório", "MISSING_DRAFT_ID")
+		return
+	}
+	limitBody(w, r)
+	var req reviewQuizDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.TenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	req.Status = strings.ToUpper(req.Status)
+	if !validQuizDraftStatus(req.Status) {
+		writeCustomError(w, http.StatusBadRequest, "status deve ser DRAFT, APPROVED ou REJECTED", "INVALID_STATUS")
+		return
+	}
+
+	var id string
+	err := f.transcriptionDB.QueryRowContext(r.Context(), sqlReviewQuizDraft, draftID, req.TenantID, req.Status).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeCustomError(w, http.StatusNotFound, "Rascunho de quiz não encontrado", "QUIZ_DRAFT_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		f.log.Error("transcription.quiz.review_failed", "draftId", draftID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "status": req.Status})
+}
+
+// ---------- 2. Business rule ----------
+
+// generateQuizDraft loads the lesson's chunks, asks the model for
+// questions, keeps the valid ones and stores them as one draft.
+func (f *Feature) generateQuizDraft(ctx context.Context, req generateQuizRequest, lessonID string) (*quizDraft, int, error) {
+	if _, status, err := f.requireAITenant(ctx, req.TenantID); err != nil {
+		return nil, status, err
+	}
+
+	chunks, err := f.loadQuizChunks(ctx, req.TenantID, lessonID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("listar trechos: %w", err)
+	}
+	if len(chunks) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("aula sem transcrição armazenada")
+	}
+	chunks = sampleQuizChunks(chunks, quizMaxContextChunks)
+
+	messages := buildQuizMessages(chunks, req.Questions)
+	promptTokens := 0
+	for _, m := range messages {
+		promptTokens += countTokens(m.Content)
+	}
+	inEst, outEst := chatCostCents(f.chatClientFor(f.quizChat).Model(), promptTokens, req.Questions*quizOutputTokensPerQuestion)
+	if err := f.checkJobBudget(ctx, req.TenantID, "", inEst+outEst); err != nil {
+		if errors.Is(err, errBudgetExceeded) {
+			return nil, http.StatusPaymentRequired, err
+		}
+		return nil, http.StatusInternalServerError, err
+	}
+
+	completion, err := f.chatClientFor(f.quizChat).Complete(ctx, messages)
+	if err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("chat completion: %w", err)
+	}
+	questions, err := parseQuizQuestions(completion.Content, chunks, req.Questions)
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+
+	draft := &quizDraft{
+		ID:        uuid.NewString(),
+		TenantID:  req.TenantID,
+		LessonID:  lessonID,
+		VideoID:   chunks[0].VideoID,
+		Status:    QuizDraftStatusDraft,
+		Model:     completion.Model,
+		Questions: questions,
+	}
+	if err := f.saveQuizDraft(ctx, draft, completion); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return draft, http.StatusCreated, nil
+}
+
+func validQuizDraftStatus(s string) bool {
+	switch s {
+	case QuizDraftStatusDraft, QuizDraftStatusApproved, QuizDraftStatusRejected:
+		return true
+	}
+	return false
+}
+
+// sampleQuizChunks keeps at most max chunks, evenly spaced and in order.
+func sampleQuizChunks(chunks []quizChunk, max int) []quizChunk {
+	if len(chunks) <= max {
+		return chunks
+	}
+	out := make([]quizChunk, 0, max)
+	for i := 0; i < max; i++ {
+		out = append(out, chunks[i*len(chunks)/max])
+	}
+	return out
+}
+
+// buildQuizMessages numbers the chunks [1]..[n] with their time range;
+// the number is what the model returns as `source`.
+func buildQuizMessages(chunks []quizChunk, questions int) []chatMessage {
+	var b strings.Builder
+	for i, c := range chunks {
+		fmt.Fprintf(&b, "[%d] (%s–%s)\n%s\n\n", i+1, formatTimestamp(c.StartTime), formatTimestamp(c.EndTime), c.Text)
+	}
+	fmt.Fprintf(&b, "Crie %d questões.", questions)
+	return []chatMessage{
+		{Role: "system", Content: quizSystemPrompt},
+		{Role: "user", Content: b.String()},
+	}
+}
+
+// parseQuizQuestions decodes the model's JSON and keeps only well-formed
+// questions: a question text, 3–6 distinct non-empty options, an answer
+// index inside them and a source number pointing at one of the prompt's
+// chunks. Fails when none is left.
+func parseQuizQuestions(content string, chunks []quizChunk, max int) ([]quizQuestion, error) {
+	var raw struct {
+		Questions []struct {
+			Question    string   `json:"question"`
+			Options     []string `json:"options"`
+			Answer      int      `json:"answer"`
+			Explanation string   `json:"explanation"`
+			Source      int      `json:"source"`
+		} `json:"questions"`
+	}
+	if err := decodeModelJSON(content, &raw); err != nil {
+		return nil, fmt.Errorf("decode quiz json: %w", err)
+	}
+
+	out := make([]quizQuestion, 0, len(raw.Questions))
+	for _, q := range raw.Questions {
+		text := strings.TrimSpace(q.Question)
+		if text == "" || q.Source < 1 || q.Source > len(chunks) {
+			continue
+		}
+		if len(q.Options) < quizMinOptions || len(q.Options) > quizMaxOptions || q.Answer < 0 || q.Answer >= len(q.Options) {
+			continue
+		}
+		options := make([]string, len(q.Options))
+		seen := make(map[string]bool, len(q.Options))
+		ok := true
+		for i, o := range q.Options {
+			o = strings.TrimSpace(o)
+			key := strings.ToLower(o)
+			if o == "" || seen[key] {
+				ok = false
+				break
+			}
+			seen[key] = true
+			options[i] = o
+		}
+		if !ok {
+			continue
+		}
+		src := chunks[q.Source-1]
+		out = append(out, quizQuestion{
+			Question:    text,
+			Options:     options,
+			AnswerIndex: q.Answer,
+			Explanation: strings.TrimSpace(q.Explanation),
+			ChunkID:     src.ID,
+			StartTime:   src.StartTime,
+			EndTime:     src.EndTime,
+		})
+		if len(out) == max {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("modelo não gerou nenhuma questão válida")
+	}
+	return out, nil
+}
+
+// ---------- 3. SQL ----------
+
+func (f *Feature) loadQuizChunks(ctx context.Context, tenantID, lessonID string) ([]quizChunk, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectLessonChunksForQuiz, tenantID, lessonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []quizChunk
+	for rows.Next() {
+		var c quizChunk
+		if err := rows.Scan(&c.ID, &c.VideoID, &c.Text, &c.StartTime, &c.EndTime); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// saveQuizDraft inserts the draft and its token_usage row together, so a
+// stored draft is always accounted for.
+func (f *Feature) saveQuizDraft(ctx context.Context, d *quizDraft, c *chatCompletion) error {
+	questionsJSON, err := json.Marshal(d.Questions)
+	if err != nil {
+		return fmt.Errorf("marshal questions: %w", err)
+	}
+	tx, err := f.transcriptionDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRowContext(ctx, sqlInsertQuizDraft,
+		d.ID, d.TenantID, d.LessonID, nullableString(d.VideoID), d.Status, d.Model, questionsJSON,
+	).Scan(&d.CreatedAt, &d.UpdatedAt); err != nil {
+		return fmt.Errorf("insert quiz draft: %w", err)
+	}
+	inCents, outCents := chatCostCents(c.Model, c.PromptTokens, c.CompletionTokens)
+	meta, _ := json.Marshal(map[string]any{
+		"quizDraftId": d.ID,
+		"lessonId":    d.LessonID,
+		"questions":   len(d.Questions),
+	})
+	if _, err := tx.ExecContext(ctx, sqlInsertTokenUsage,
+		uuid.NewString(), d.TenantID, nil, nullableString(d.VideoID), nil,
+		c.PromptTokens, c.CompletionTokens, c.PromptTokens+c.CompletionTokens,
+		inCents, outCents, inCents+outCents,
+		c.Model, "quiz", meta,
+	); err != nil {
+		return fmt.Errorf("insert token_usage: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit quiz draft tx: %w", err)
+	}
+	return nil
+}
+
+func (f *Feature) listQuizDrafts(ctx context.Context, tenantID, lessonID, status string) ([]quizDraft, error) {
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectQuizDrafts, tenantID, lessonID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]quizDraft, 0)
+	for rows.Next() {
+		var (
+			d             quizDraft
+			videoID       sql.NullString
+			questionsJSON []byte
+			reviewedAt    sql.NullTime
+		)
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.LessonID, &videoID, &d.Status, &d.Model,
+			&questionsJSON, &d.CreatedAt, &d.UpdatedAt, &reviewedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(questionsJSON, &d.Questions); err != nil {
+			return nil, fmt.Errorf("decode quiz draft %s: %w", d.ID, err)
+		}
+		d.VideoID = videoID.String
+		if reviewedAt.Valid {
+			d.ReviewedAt = &reviewedAt.Time
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,227 @@
+package transcription
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+)
+
+var quizChunks = []quizChunk{
+	{ID: "c1", VideoID: "v1", Text: "Funil de vendas tem topo, meio e fundo.", StartTime: 0, EndTime: 30},
+	{ID: "c2", VideoID: "v1", Text: "O fundo do funil é onde acontece a compra.", StartTime: 30, EndTime: 65},
+}
+
+func TestParseQuizQuestions_KeepsOnlyWellFormedQuestions(t *testing.T) {
+	content := "```json\n" + `{"questions": [
+		{"question": " Onde acontece a compra? ", "options": ["Topo", "Meio", "Fundo", "Fora"], "answer": 2, "explanation": "Trecho 2.", "source": 2},
+		{"question": "Sem fonte", "options": ["a", "b", "c"], "answer": 0, "source": 9},
+		{"question": "Resposta fora", "options": ["a", "b", "c"], "answer": 3, "source": 1},
+		{"question": "Duplicadas", "options": ["a", "A ", "c"], "answer": 0, "source": 1},
+		{"question": "Poucas", "options": ["a", "b"], "answer": 0, "source": 1},
+		{"question": "", "options": ["a", "b", "c"], "answer": 0, "source": 1},
+		{"question": "Quantas etapas?", "options": ["Uma", "Duas", "Três"], "answer": 2, "source": 1}
+	]}` + "\n```"
+
+	got, err := parseQuizQuestions(content, quizChunks, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("questions = %+v", got)
+	}
+	q := got[0]
+	if q.Question != "Onde acontece a compra?" || q.AnswerIndex != 2 || q.ChunkID != "c2" || q.StartTime != 30 || q.EndTime != 65 {
+		t.Fatalf("first question = %+v", q)
+	}
+	if got[1].ChunkID != "c1" {
+		t.Fatalf("second question = %+v", got[1])
+	}
+
+	// The requested count caps what the model over-delivers.
+	if got, _ := parseQuizQuestions(content, quizChunks, 1); len(got) != 1 {
+		t.Fatalf("capped = %+v", got)
+	}
+}
+
+func TestParseQuizQuestions_FailsWithoutValidQuestions(t *testing.T) {
+	for _, content := range []string{`not json`, `{"questions": []}`, `{"questions": [{"question": "x", "options": ["a"], "answer": 0, "source": 1}]}`} {
+		if _, err := parseQuizQuestions(content, quizChunks, 5); err == nil {
+			t.Errorf("%q: expected error", content)
+		}
+	}
+}
+
+func TestSampleQuizChunks_SpreadsAcrossLesson(t *testing.T) {
+	chunks := make([]quizChunk, 10)
+	for i := range chunks {
+		chunks[i].ID = string(rune('a' + i))
+	}
+	got := sampleQuizChunks(chunks, 4)
+	ids := ""
+	for _, c := range got {
+		ids += c.ID
+	}
+	if ids != "acfh" {
+		t.Fatalf("sampled = %q", ids)
+	}
+	if len(sampleQuizChunks(chunks, 20)) != 10 {
+		t.Fatal("short lessons should be kept whole")
+	}
+}
+
+func TestBuildQuizMessages_NumbersChunksWithTimestamps(t *testing.T) {
+	messages := buildQuizMessages(quizChunks, 3)
+	if len(messages) != 2 || messages[0].Role != "system" {
+		t.Fatalf("messages = %+v", messages)
+	}
+	for _, want := range []string{"[1] (0:00–0:30)", "[2] (0:30–1:05)", "Crie 3 questões."} {
+		if !strings.Contains(messages[1].Content, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, messages[1].Content)
+		}
+	}
+}
+
+func serveQuiz(r chi.Router, method, path string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGenerateQuizDraft_StoresDraftAndUsage(t *testing.T) {
+	chat := &fakeChat{reply: `{"questions": [{"question": "Onde acontece a compra?", "options": ["Topo", "Meio", "Fundo"], "answer": 2, "source": 2}]}`}
+	fx := newTestFixture(t)
+	fx.f.quizChat = chat
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	created := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	fx.expectAITenant(true)
+	rows := sqlmock.NewRows([]string{"id", "video_id", "text", "start_time", "end_time"})
+	for _, c := range quizChunks {
+		rows.AddRow(c.ID, c.VideoID, c.Text, c.StartTime, c.EndTime)
+	}
+	txMock.ExpectQuery(`FROM chunks`).WithArgs("t1", "l1").WillReturnRows(rows)
+	txMock.ExpectBegin()
+	txMock.ExpectQuery(`INSERT INTO quiz_drafts`).
+		WithArgs(sqlmock.AnyArg(), "t1", "l1", "v1", "DRAFT", "fake-chat", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(created, created))
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t1", nil, "v1", nil, 1000, 100, 1100,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "fake-chat", "quiz", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+
+	w := serveQuiz(r, http.MethodPost, "/lessons/l1/quiz-drafts", generateQuizRequest{TenantID: "t1", Questions: 3})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var got quizDraft
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != QuizDraftStatusDraft || len(got.Questions) != 1 || got.Questions[0].StartTime != 30 || !got.CreatedAt.Equal(created) {
+		t.Fatalf("draft = %+v", got)
+	}
+	if chat.calls != 1 || !strings.Contains(chat.messages[1].Content, "Crie 3 questões.") {
+		t.Fatalf("chat calls = %d messages = %+v", chat.calls, chat.messages)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateQuizDraft_Validation(t *testing.T) {
+	chat := &fakeChat{}
+	fx := newTestFixture(t)
+	fx.f.quizChat = chat
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+
+	if w := serveQuiz(r, http.MethodPost, "/lessons/l1/quiz-drafts", generateQuizRequest{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing tenant: status = %d", w.Code)
+	}
+	if w := serveQuiz(r, http.MethodPost, "/lessons/l1/quiz-drafts", generateQuizRequest{TenantID: "t1", Questions: 21}); w.Code != http.StatusBadRequest {
+		t.Fatalf("too many questions: status = %d", w.Code)
+	}
+
+	fx.expectAITenant(false)
+	if w := serveQuiz(r, http.MethodPost, "/lessons/l1/quiz-drafts", generateQuizRequest{TenantID: "t1"}); w.Code != http.StatusForbidden {
+		t.Fatalf("AI disabled: status = %d", w.Code)
+	}
+
+	fx.expectAITenant(true)
+	txMock.ExpectQuery(`FROM chunks`).WithArgs("t1", "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "video_id", "text", "start_time", "end_time"}))
+	if w := serveQuiz(r, http.MethodPost, "/lessons/l1/quiz-drafts", generateQuizRequest{TenantID: "t1"}); w.Code != http.StatusNotFound {
+		t.Fatalf("no chunks: status = %d", w.Code)
+	}
+	if chat.calls != 0 {
+		t.Fatalf("chat called %d times", chat.calls)
+	}
+}
+
+func TestListQuizDrafts(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	created := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	txMock.ExpectQuery(`FROM quiz_drafts`).WithArgs("t1", "l1", "APPROVED").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "lesson_id", "video_id", "status", "model",
+			"questions", "created_at", "updated_at", "reviewed_at"}).
+			AddRow("d1", "t1", "l1", "v1", "APPROVED", "gpt-4o-mini",
+				[]byte(`[{"question":"Q","options":["a","b","c"],"answerIndex":1,"chunkId":"c1","startTime":0,"endTime":30}]`),
+				created, created, created))
+
+	w := serveQuiz(r, http.MethodGet, "/lessons/l1/quiz-drafts?tenantId=t1&status=approved", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Drafts []quizDraft `json:"drafts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Drafts) != 1 || body.Drafts[0].ReviewedAt == nil || body.Drafts[0].Questions[0].AnswerIndex != 1 {
+		t.Fatalf("drafts = %+v", body.Drafts)
+	}
+
+	if w := serveQuiz(r, http.MethodGet, "/lessons/l1/quiz-drafts?tenantId=t1&status=PUBLISHED", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad status: status = %d", w.Code)
+	}
+}
+
+func TestReviewQuizDraft(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+
+	txMock.ExpectQuery(`UPDATE quiz_drafts`).WithArgs("d1", "t1", "APPROVED").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("d1"))
+	if w := serveQuiz(r, http.MethodPatch, "/quiz-drafts/d1", reviewQuizDraftRequest{TenantID: "t1", Status: "approved"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+
+	// Another tenant's draft looks missing.
+	txMock.ExpectQuery(`UPDATE quiz_drafts`).WithArgs("d1", "t2", "REJECTED").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if w := serveQuiz(r, http.MethodPatch, "/quiz-drafts/d1", reviewQuizDraftRequest{TenantID: "t2", Status: "REJECTED"}); w.Code != http.StatusNotFound {
+		t.Fatalf("other tenant: status = %d", w.Code)
+	}
+
+	if w := serveQuiz(r, http.MethodPatch, "/quiz-drafts/d1", reviewQuizDraftRequest{TenantID: "t1", Status: "PUBLISHED"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad status: status = %d", w.Code)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
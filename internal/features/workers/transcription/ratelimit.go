@@ -0,0 +1,75 @@
+package transcription
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rpmLimiter is a token bucket shared by every OpenAI call the process
+// makes (Whisper parts, embedding batches, search queries), so the worker
+// pool and the parallel part transcription together stay under the
+// account's requests-per-minute. A nil limiter never waits.
+type rpmLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // one token every interval
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// newRPMLimiter returns nil (no limit) for rpm <= 0. The bucket holds a
+// tenth of a minute's budget so a burst of search queries isn't spaced
+// out one by one.
+func newRPMLimiter(rpm int) *rpmLimiter {
+	if rpm <= 0 {
+		return nil
+	}
+	burst := float64(rpm / 10)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rpmLimiter{
+		interval: time.Minute / time.Duration(rpm),
+		burst:    burst,
+		tokens:   burst,
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until the caller may send one request or ctx is done. The
+// token is reserved up front (like x/time/rate's Reserve) and handed back
+// if ctx ends first.
+func (l *rpmLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens * float64(l.interval))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
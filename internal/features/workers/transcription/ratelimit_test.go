@@ -0,0 +1,47 @@
+package transcription
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRPMLimiter_NilAndZeroNeverWait(t *testing.T) {
+	if newRPMLimiter(0) != nil {
+		t.Fatal("rpm 0 should disable the limiter")
+	}
+	var l *rpmLimiter
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRPMLimiter_SpacesRequestsAfterBurst(t *testing.T) {
+	// 600 rpm → one token per 100ms, burst of 60.
+	l := newRPMLimiter(600)
+	l.tokens = 1
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := time.Since(start); got < 180*time.Millisecond {
+		t.Fatalf("3 requests with 1 token took %s, want ≥ ~200ms", got)
+	}
+}
+
+func TestRPMLimiter_ContextCancelReturnsToken(t *testing.T) {
+	l := newRPMLimiter(1) // one token per minute
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); err == nil {
+		t.Fatal("expected context error")
+	}
+	if l.tokens < -0.01 || l.tokens > 0.01 {
+		t.Fatalf("tokens = %f, want the reservation handed back (~0)", l.tokens)
+	}
+}
@@ -0,0 +1,363 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// ---------- DTOs ----------
+
+// reembedLessonsRequest scopes an embedding-only re-run. Every field but
+// tenantId is optional and they narrow each other:
+//
+//   - courseId + lessonIds empty: every transcribed video of the tenant
+//   - courseId set: only videos of that course
+//   - lessonIds set: only those lessons (still within courseId if given)
+type reembedLessonsRequest struct {
+	TenantID  string   `json:"tenantId"`
+	CourseID  string   `json:"courseId,omitempty"`
+	LessonIDs []string `json:"lessonIds,omitempty"`
+}
+
+// embeddingJobPayload is jobs.payload for EMBEDDING_GENERATION. One job =
+// one video; the transcript is re-read at execution time so a re-embed
+// queued before a fresh transcription picks up the newer text.
+type embeddingJobPayload struct {
+	TenantID string `json:"tenantId"`
+	VideoID  string `json:"videoId"`
+	LessonID string `json:"lessonId,omitempty"`
+	CourseID string `json:"courseId,omitempty"`
+}
+
+// ---------- 1. HTTP handler ----------
+
+// ReembedLessons handles `POST /api/v1/ai/tenants/reembed-lessons`.
+//
+// Body: { tenantId, courseId?, lessonIds? }
+//
+// Enqueues one EMBEDDING_GENERATION job per transcribed video in scope.
+// Used after changing embedModel or the chunks.embedding width: the
+// stored transcripts.segments are re-chunked and re-embedded, so nothing
+// is downloaded or sent to Whisper again. Lessons without a stored
+// transcript come back in `skipped`.
+func (f *Feature) ReembedLessons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	limitBody(w, r)
+	var req reembedLessonsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.TenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	if len(req.LessonIDs) > maxLessonIDsPerRequest {
+		writeCustomError(w, http.StatusBadRequest,
+			fmt.Sprintf("lessonIds excede o limite de %d por chamada", maxLessonIDsPerRequest),
+			"TOO_MANY_LESSON_IDS")
+		return
+	}
+
+	resp, status, err := f.enqueueReembed(r.Context(), req)
+	if err != nil {
+		writeError(w, status, http.StatusText(status), err.Error())
+		return
+	}
+	writeJSON(w, status, resp)
+}
+
+// ---------- 2. Business rule ----------
+
+// enqueueReembed inserts one EMBEDDING_GENERATION job per lesson in the
+// scope, for its current video. Videos that already have a re-embed
+// queued or running are reported as skipped.
+func (f *Feature) enqueueReembed(ctx context.Context, req reembedLessonsRequest) (*processLessonsResponse, int, error) {
+	return f.enqueueLessonJobs(ctx, req, lessonJobKind{
+		insert:    f.insertEmbeddingJob,
+		logKey:    "transcription.reembed.insert_failed",
+		duplicate: "re-embed já enfileirado para este vídeo",
+		noneMsg:   "nenhuma transcrição elegível para re-embed",
+		doneMsg:   "%d job(s) de re-embed enfileirado(s)",
+	})
+}
+
+// insertEmbeddingJob queues one EMBEDDING_GENERATION job. sql.ErrNoRows
+// means one is already queued or running for the video.
+func (f *Feature) insertEmbeddingJob(ctx context.Context, t lessonJobTarget) (string, error) {
+	payload, err := json.Marshal(embeddingJobPayload{
+		TenantID: t.TenantID, VideoID: t.VideoID, LessonID: t.LessonID, CourseID: t.CourseID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal embedding payload: %w", err)
+	}
+	var jobID string
+	err = f.transcriptionDB.QueryRowContext(ctx, sqlInsertEmbeddingJob,
+		uuid.NewString(), t.TenantID, 0, payload, 3, t.VideoID,
+	).Scan(&jobID)
+	return jobID, err
+}
+
+// lessonJobTarget is a lesson's current video, as sqlSelectReembedTargets
+// picks it.
+type lessonJobTarget struct {
+	TenantID string
+	VideoID  string
+	LessonID string
+	CourseID string
+}
+
+// lessonJobKind is what differs between the per-lesson jobs that
+// enqueueLessonJobs queues over a re-embed style scope.
+type lessonJobKind struct {
+	// insert queues the job; sql.ErrNoRows means one is already queued
+	// or running for the video and is reported with the duplicate reason.
+	insert    func(ctx context.Context, t lessonJobTarget) (string, error)
+	logKey    string
+	duplicate string
+	noneMsg   string
+	doneMsg   string // formatted with the enqueued count
+}
+
+// enqueueLessonJobs resolves the scope against the transcription DB (only
+// videos with a stored transcript qualify) and inserts one job per lesson
+// through kind.insert.
+func (f *Feature) enqueueLessonJobs(ctx context.Context, req reembedLessonsRequest, kind lessonJobKind) (*processLessonsResponse, int, error) {
+	if _, status, err := f.requireAITenant(ctx, req.TenantID); err != nil {
+		return nil, status, err
+	}
+
+	lessonIDs := req.LessonIDs
+	if lessonIDs == nil {
+		lessonIDs = []string{}
+	}
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectReembedTargets,
+		req.TenantID, req.CourseID, pq.Array(lessonIDs))
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("listar transcrições: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []lessonJobTarget
+	withTranscript := make(map[string]bool)
+	for rows.Next() {
+		var (
+			t            = lessonJobTarget{TenantID: req.TenantID}
+			transcriptID string
+		)
+		if err := rows.Scan(&t.VideoID, &t.LessonID, &t.CourseID, &transcriptID); err != nil {
+			return nil, http.StatusInternalServerError, fmt.Errorf("scan video: %w", err)
+		}
+		targets = append(targets, t)
+		withTranscript[t.LessonID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("iterate videos: %w", err)
+	}
+
+	resp := &processLessonsResponse{
+		TenantID: req.TenantID,
+		Enqueued: make([]enqueuedLesson, 0, len(targets)),
+		Skipped:  make([]skippedLesson, 0),
+	}
+	for _, id := range req.LessonIDs {
+		if !withTranscript[id] {
+			resp.Skipped = append(resp.Skipped, skippedLesson{
+				LessonID: id,
+				Reason:   "lesson sem transcrição armazenada (ou fora do curso informado)",
+			})
+		}
+	}
+
+	for _, t := range targets {
+		jobID, err := kind.insert(ctx, t)
+		if errors.Is(err, sql.ErrNoRows) {
+			resp.Skipped = append(resp.Skipped, skippedLesson{LessonID: t.LessonID, Reason: kind.duplicate})
+			continue
+		}
+		if err != nil {
+			f.log.Error(kind.logKey,
+				"tenant", req.TenantID, "video", t.VideoID, "error", err.Error())
+			resp.Skipped = append(resp.Skipped, skippedLesson{LessonID: t.LessonID, Reason: "erro ao gravar job"})
+			continue
+		}
+		resp.Enqueued = append(resp.Enqueued, enqueuedLesson{LessonID: t.LessonID, JobID: jobID})
+	}
+
+	resp.EnqueuedCount = len(resp.Enqueued)
+	resp.Success = resp.EnqueuedCount > 0
+	if !resp.Success {
+		resp.Message = kind.noneMsg
+		return resp, http.StatusOK, nil
+	}
+	resp.Message = fmt.Sprintf(kind.doneMsg, resp.EnqueuedCount)
+	return resp, http.StatusAccepted, nil
+}
+
+// ---------- 3. Worker ----------
+
+// executeEmbeddingJob runs an EMBEDDING_GENERATION job: re-chunk the
+// stored segments, embed them with the current embedModel/embedDims, and
+// swap the video's chunks in a single transaction so search never sees a
+// half-embedded video. The transcript row itself is kept as-is.
+func (f *Feature) executeEmbeddingJob(ctx context.Context, jobID, tenantID string, rawPayload []byte) error {
+	if err := f.preflight(); err != nil {
+		return err
+	}
+
+	var p embeddingJobPayload
+	if err := json.Unmarshal(rawPayload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	if p.VideoID == "" {
+		return fmt.Errorf("payload missing videoId")
+	}
+
+	if err := f.requireJobTenantAI(ctx, tenantID); err != nil {
+		return err
+	}
+	var (
+		transcriptID       string
+		segmentsJSON       []byte
+		lessonID, courseID string
+	)
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTranscriptForReembed, p.VideoID, tenantID).
+		Scan(&transcriptID, &segmentsJSON, &lessonID, &courseID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("video %s has no stored transcript", p.VideoID)
+		}
+		return fmt.Errorf("select transcript: %w", err)
+	}
+	superseded, err := f.isSupersededVideo(ctx, tenantID, lessonID, p.VideoID)
+	if err != nil {
+		return err
+	}
+	if superseded {
+		// Queued before a new recording of the lesson landed: its chunks
+		// were retired on purpose and must not be put back.
+		f.log.Info("transcription.reembed.superseded", "jobId", jobID, "videoId", p.VideoID, "lessonId", lessonID)
+		result, _ := json.Marshal(jobResult{VideoID: p.VideoID, TranscriptID: transcriptID, Superseded: true})
+		return f.markJobCompleted(ctx, jobID, result)
+	}
+	var segments []whisperSegment
+	if err := json.Unmarshal(segmentsJSON, &segments); err != nil {
+		return fmt.Errorf("decode transcript segments: %w", err)
+	}
+
+	f.reportProgress(ctx, jobID, jobProgress{Stage: VideoStatusChunking, Percent: progressChunkingPct})
+	chunks := splitIntoChunks(segments, 500, 50)
+	if len(chunks) == 0 {
+		return fmt.Errorf("chunker produced 0 chunks (segments=%d)", len(segments))
+	}
+	// The chunk token counts are the embedding model's own, so this is
+	// what embedding every chunk costs — an upper bound once the
+	// embedding cache serves some of them.
+	estimateTokens := 0
+	for _, c := range chunks {
+		estimateTokens += c.Tokens
+	}
+	if err := f.checkJobBudget(ctx, tenantID, jobID, embedCostCents(estimateTokens)); err != nil {
+		return err
+	}
+	embedded, err := f.embedChunks(ctx, chunks, nil, func(done, total int) {
+		f.reportProgress(ctx, jobID, embedProgress(done, total))
+	})
+	if err != nil {
+		return err
+	}
+	embeddings, embedTokens, costCents := embedded.Vectors, embedded.Tokens, embedded.CostCents
+
+	tx, err := f.transcriptionDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, sqlDeleteChunksByVideo, p.VideoID); err != nil {
+		return fmt.Errorf("delete prior chunks: %w", err)
+	}
+	if err := copyChunks(ctx, tx, chunks, embeddings, chunkRowKeys{
+		VideoID:      p.VideoID,
+		TranscriptID: transcriptID,
+		TenantID:     tenantID,
+		CourseID:     courseID,
+		LessonID:     lessonID,
+	}); err != nil {
+		return err
+	}
+
+	dims := f.embedDims
+	if dims <= 0 {
+		dims = defaultEmbedDims
+	}
+	tokenMeta, _ := json.Marshal(map[string]any{
+		"chunks":           len(chunks),
+		"embedDims":        dims,
+		"jobId":            jobID,
+		"embedCacheHits":   embedded.CacheHits,
+		"embedCacheMisses": embedded.CacheMisses,
+	})
+	if _, err := tx.ExecContext(ctx, sqlInsertTokenUsage,
+		uuid.NewString(), tenantID, nullableString(courseID), p.VideoID, transcriptID,
+		embedTokens, 0, embedTokens, costCents, 0, costCents,
+		embedModel, "reembed", tokenMeta,
+	); err != nil {
+		return fmt.Errorf("insert token_usage: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit reembed tx: %w", err)
+	}
+
+	result, _ := json.Marshal(jobResult{
+		VideoID:      p.VideoID,
+		TranscriptID: transcriptID,
+		ChunksCount:  len(chunks),
+		CostCents:    costCents,
+	})
+	if err := f.markJobCompleted(ctx, jobID, result); err != nil {
+		if !errors.Is(err, errJobCancelled) {
+			f.log.Error("transcription.reembed.mark_job_failed",
+				"error", err.Error(), "jobId", jobID, "videoId", p.VideoID)
+		}
+		return err
+	}
+	f.refreshLessonEmbedding(ctx, tenantID, lessonID)
+	return nil
+}
+
+// isSupersededVideo reports whether a newer recording replaced videoID as
+// the lesson's current video (sqlSelectLessonCurrentVideo). Videos
+// without a lesson are never superseded.
+func (f *Feature) isSupersededVideo(ctx context.Context, tenantID, lessonID, videoID string) (bool, error) {
+	if lessonID == "" {
+		return false, nil
+	}
+	var current string
+	err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectLessonCurrentVideo, tenantID, lessonID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("select current video: %w", err)
+	}
+	return current != videoID, nil
+}
@@ -0,0 +1,149 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReembedLessons_EnqueuesDedupesAndSkips(t *testing.T) {
+	fx := newTestFixture(t)
+	f, txMock := fx.f, fx.txMock
+
+	fx.expectAITenant(true)
+	txMock.ExpectQuery(`SELECT DISTINCT ON \(COALESCE\(v.lesson_id, v.id\)\)`).
+		WithArgs("t1", "c1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "transcript_id"}).
+			AddRow("v1", "l1", "c1", "tr1").
+			AddRow("v2", "l2", "c1", "tr2"))
+	txMock.ExpectQuery(`INSERT INTO jobs.*EMBEDDING_GENERATION.*NOT EXISTS`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "v1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("j1"))
+	// v2 already has a re-embed in flight.
+	txMock.ExpectQuery(`INSERT INTO jobs.*EMBEDDING_GENERATION`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "v2").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	body, _ := json.Marshal(reembedLessonsRequest{TenantID: "t1", CourseID: "c1", LessonIDs: []string{"l1", "l2", "l3"}})
+	req := httptest.NewRequest(http.MethodPost, "/tenants/reembed-lessons", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.ReembedLessons(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var resp processLessonsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.EnqueuedCount != 1 || resp.Enqueued[0].LessonID != "l1" {
+		t.Fatalf("enqueued = %+v", resp.Enqueued)
+	}
+	// l3 has no transcript, l2 was deduplicated.
+	if len(resp.Skipped) != 2 {
+		t.Fatalf("skipped = %+v", resp.Skipped)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteEmbeddingJob_SwapsChunksFromStoredSegments(t *testing.T) {
+	// Whisper must never be called: the fixture's OpenAI fails the test on
+	// any path other than /v1/embeddings.
+	fx := newTestFixture(t)
+	f, txMock := fx.f, fx.txMock
+
+	fx.expectAITenant(true)
+	segments, _ := json.Marshal([]whisperSegment{{Start: 0, End: 3, Text: "oi mundo"}})
+	txMock.ExpectQuery(`FROM transcripts t.*JOIN videos v`).WithArgs("v1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "segments", "lesson_id", "course_id"}).
+			AddRow("tr1", segments, "l1", "c1"))
+	expectCurrentVideo(txMock, "t1", "l1", "v1")
+	expectProgress(txMock, "job-1", progressStage{VideoStatusChunking, 80})
+	expectEmbedCacheMiss(txMock)
+	expectEmbedCacheStore(txMock)
+	expectProgress(txMock, "job-1", progressStage{VideoStatusGeneratingEmbeddings, 95})
+	txMock.ExpectBegin()
+	txMock.ExpectExec(`DELETE FROM chunks`).WithArgs("v1").WillReturnResult(sqlmock.NewResult(0, 3))
+	prep := txMock.ExpectPrepare(`COPY "public"."chunks"`)
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t1", "c1", "v1", "tr1", 4, 0, 4, 1, 0, 1, embedModel, "reembed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+	txMock.ExpectExec(`UPDATE jobs.*SET status.*COMPLETED`).WithArgs("job-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(embeddingJobPayload{TenantID: "t1", VideoID: "v1", LessonID: "l1"})
+	if err := f.executeEmbeddingJob(context.Background(), "job-1", "t1", payload); err != nil {
+		t.Fatalf("executeEmbeddingJob: %v", err)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectCurrentVideo answers sqlSelectLessonCurrentVideo for a lesson.
+func expectCurrentVideo(mock sqlmock.Sqlmock, tenantID, lessonID, videoID string) {
+	mock.ExpectQuery(`SELECT t.video_id.*FROM transcripts t`).WithArgs(tenantID, lessonID).
+		WillReturnRows(sqlmock.NewRows([]string{"video_id"}).AddRow(videoID))
+}
+
+func TestReembedLessons_OneJobPerLessonWithTwoVideos(t *testing.T) {
+	fx := newTestFixture(t)
+	f, txMock := fx.f, fx.txMock
+
+	fx.expectAITenant(true)
+	// l1 has an old recording (v-old) and its replacement (v-new); the
+	// query groups by lesson and keeps the video with the newest
+	// transcript, so only v-new comes back.
+	txMock.ExpectQuery(`DISTINCT ON \(COALESCE\(v.lesson_id, v.id\)\).*ORDER BY COALESCE\(v.lesson_id, v.id\), t.created_at DESC`).
+		WithArgs("t1", "", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "transcript_id"}).
+			AddRow("v-new", "l1", "c1", "tr-new"))
+	txMock.ExpectQuery(`INSERT INTO jobs.*EMBEDDING_GENERATION`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "v-new").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("j1"))
+
+	resp, status, err := f.enqueueReembed(context.Background(), reembedLessonsRequest{TenantID: "t1", LessonIDs: []string{"l1"}})
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("status = %d err = %v", status, err)
+	}
+	if resp.EnqueuedCount != 1 || len(resp.Skipped) != 0 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteEmbeddingJob_SkipsSupersededVideo(t *testing.T) {
+	fx := newTestFixture(t)
+	f, txMock := fx.f, fx.txMock
+
+	fx.expectAITenant(true)
+	segments, _ := json.Marshal([]whisperSegment{{Start: 0, End: 3, Text: "gravação antiga"}})
+	txMock.ExpectQuery(`FROM transcripts t.*JOIN videos v`).WithArgs("v-old", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "segments", "lesson_id", "course_id"}).
+			AddRow("tr-old", segments, "l1", "c1"))
+	expectCurrentVideo(txMock, "t1", "l1", "v-new")
+	// No embedding call and no chunk writes: the job just completes.
+	txMock.ExpectExec(`UPDATE jobs.*SET status.*COMPLETED`).WithArgs("job-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(embeddingJobPayload{TenantID: "t1", VideoID: "v-old", LessonID: "l1"})
+	if err := f.executeEmbeddingJob(context.Background(), "job-1", "t1", payload); err != nil {
+		t.Fatalf("executeEmbeddingJob: %v", err)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
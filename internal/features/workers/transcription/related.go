@@ -0,0 +1,189 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// Related lessons: each lesson gets one embedding, the mean of its chunk
+// embeddings, kept in lesson_embeddings. Jobs that rewrite a lesson's
+// chunks refresh it; the endpoint ranks the tenant's other lessons by
+// cosine distance so the vitrine can suggest "you may also like" without
+// manual curation.
+
+const (
+	relatedDefaultLimit = 5
+	relatedMaxLimit     = 20
+)
+
+// ---------- DTOs ----------
+
+// relatedLesson is one entry of the GET /lessons/{lessonId}/related
+// response. Similarity is 1 - cosine distance.
+type relatedLesson struct {
+	LessonID   string  `json:"lessonId"`
+	CourseID   string  `json:"courseId,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+type relatedLessonsResponse struct {
+	TenantID string          `json:"tenantId"`
+	LessonID string          `json:"lessonId"`
+	Lessons  []relatedLesson `json:"lessons"`
+	Count    int             `json:"count"`
+}
+
+// errLessonNotEmbedded is returned when the lesson has no row in
+// lesson_embeddings (not transcribed yet, or no chunks).
+var errLessonNotEmbedded = errors.New("lesson not embedded")
+
+// ---------- 1. HTTP handler ----------
+
+// RelatedLessons handles `GET /api/v1/ai/lessons/{lessonId}/related`.
+//
+// Query: tenantId (required), limit (default 5, max 20), userId (optional;
+// drops the lessons that user already completed).
+func (f *Feature) RelatedLessons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	lessonID := chi.URLParam(r, "lessonId")
+	if lessonID == "" {
+		writeCustomError(w, http.StatusBadRequest, "lessonId é obrigatório", "MISSING_LESSON_ID")
+		return
+	}
+	q := r.URL.Query()
+	tenantID := q.Get("tenantId")
+	if tenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	limit := relatedDefaultLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeCustomError(w, http.StatusBadRequest, "limit deve ser um inteiro positivo", "INVALID_LIMIT")
+			return
+		}
+		limit = min(n, relatedMaxLimit)
+	}
+	userID := q.Get("userId")
+	if userID != "" && f.memberclassDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "memberclass DB not configured")
+		return
+	}
+
+	lessons, err := f.relatedLessons(r.Context(), tenantID, lessonID, userID, limit)
+	if errors.Is(err, errLessonNotEmbedded) {
+		writeCustomError(w, http.StatusNotFound, "Aula ainda não possui embeddings", "LESSON_NOT_EMBEDDED")
+		return
+	}
+	if err != nil {
+		f.log.Error("transcription.related.query_failed", "tenant", tenantID, "lesson", lessonID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, relatedLessonsResponse{
+		TenantID: tenantID,
+		LessonID: lessonID,
+		Lessons:  lessons,
+		Count:    len(lessons),
+	})
+}
+
+// ---------- 2. Business rule ----------
+
+// relatedLessons returns up to limit lessons closest to lessonID, without
+// the ones userID (if set) already completed.
+func (f *Feature) relatedLessons(ctx context.Context, tenantID, lessonID, userID string, limit int) ([]relatedLesson, error) {
+	excluded := []string{}
+	if userID != "" {
+		ids, err := f.userCompletedLessonIDs(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		excluded = ids
+	}
+
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlSelectRelatedLessons, tenantID, lessonID, pq.Array(excluded), limit)
+	if err != nil {
+		return nil, fmt.Errorf("select related lessons: %w", err)
+	}
+	defer rows.Close()
+	lessons := make([]relatedLesson, 0, limit)
+	for rows.Next() {
+		var l relatedLesson
+		if err := rows.Scan(&l.LessonID, &l.CourseID, &l.Similarity); err != nil {
+			return nil, fmt.Errorf("scan related lesson: %w", err)
+		}
+		lessons = append(lessons, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(lessons) > 0 {
+		return lessons, nil
+	}
+
+	var exists bool
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectLessonEmbeddingExists, tenantID, lessonID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("select lesson embedding: %w", err)
+	}
+	if !exists {
+		return nil, errLessonNotEmbedded
+	}
+	return lessons, nil
+}
+
+func (f *Feature) userCompletedLessonIDs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := f.memberclassDB.QueryContext(ctx, sqlSelectUserCompletedLessons, userID)
+	if err != nil {
+		return nil, fmt.Errorf("select completed lessons: %w", err)
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan completed lesson: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ---------- 3. Worker/SQL ----------
+
+// refreshLessonEmbedding recomputes the lesson's mean embedding after its
+// chunks changed, or drops it when none are left. Best effort: the job
+// that changed the chunks has already committed, so a failure here is
+// only logged and the next job on the lesson fixes it.
+func (f *Feature) refreshLessonEmbedding(ctx context.Context, tenantID, lessonID string) {
+	if lessonID == "" {
+		return
+	}
+	res, err := f.transcriptionDB.ExecContext(ctx, sqlUpsertLessonEmbedding, tenantID, lessonID, f.embedDimsOrDefault())
+	if err == nil {
+		if n, _ := res.RowsAffected(); n == 0 {
+			_, err = f.transcriptionDB.ExecContext(ctx, sqlDeleteLessonEmbedding, tenantID, lessonID)
+		}
+	}
+	if err != nil {
+		f.log.Warn("transcription.related.refresh_failed", "tenant", tenantID, "lesson", lessonID, "error", err.Error())
+	}
+}
@@ -0,0 +1,107 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func getRelated(r chi.Router, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/lessons/l1/related?"+query, nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRelatedLessons_ExcludesCompleted(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, mcMock, r := fx.txMock, fx.mcMock, fx.router(MiddlewareSet{})
+
+	mcMock.ExpectQuery(`FROM "Read" r`).WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"lessonId"}).AddRow("l2"))
+	txMock.ExpectQuery(`FROM lesson_embeddings s.*ORDER BY o.embedding <=> s.embedding`).
+		WithArgs("t1", "l1", pq.Array([]string{"l2"}), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"lesson_id", "course_id", "similarity"}).
+			AddRow("l3", "c1", 0.91).
+			AddRow("l4", "c2", 0.72))
+
+	w := getRelated(r, "tenantId=t1&limit=3&userId=u1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var got relatedLessonsResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Count != 2 || got.Lessons[0].LessonID != "l3" || got.Lessons[1].CourseID != "c2" {
+		t.Fatalf("response = %+v", got)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelatedLessons_NotEmbeddedVersusNoNeighbours(t *testing.T) {
+	fx := newTestFixture(t)
+	txMock, r := fx.txMock, fx.router(MiddlewareSet{})
+	emptyRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"lesson_id", "course_id", "similarity"}) }
+
+	txMock.ExpectQuery(`FROM lesson_embeddings s`).WithArgs("t1", "l1", pq.Array([]string{}), relatedDefaultLimit).
+		WillReturnRows(emptyRows())
+	txMock.ExpectQuery(`SELECT EXISTS`).WithArgs("t1", "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	if w := getRelated(r, "tenantId=t1"); w.Code != http.StatusNotFound {
+		t.Fatalf("not embedded: status = %d body = %s", w.Code, w.Body.String())
+	}
+
+	txMock.ExpectQuery(`FROM lesson_embeddings s`).WithArgs("t1", "l1", pq.Array([]string{}), relatedMaxLimit).
+		WillReturnRows(emptyRows())
+	txMock.ExpectQuery(`SELECT EXISTS`).WithArgs("t1", "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	w := getRelated(r, "tenantId=t1&limit=500")
+	if w.Code != http.StatusOK {
+		t.Fatalf("no neighbours: status = %d body = %s", w.Code, w.Body.String())
+	}
+	var got relatedLessonsResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Lessons == nil || got.Count != 0 {
+		t.Fatalf("response = %+v", got)
+	}
+}
+
+func TestRelatedLessons_Validation(t *testing.T) {
+	r := newTestFixture(t).router(MiddlewareSet{})
+	if w := getRelated(r, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing tenantId: status = %d", w.Code)
+	}
+	if w := getRelated(r, "tenantId=t1&limit=abc"); w.Code != http.StatusBadRequest {
+		t.Fatalf("bad limit: status = %d", w.Code)
+	}
+}
+
+func TestRefreshLessonEmbedding_DeletesWhenNoChunksLeft(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, log: logger.NewLogger(), embedDims: 512}
+
+	// Only chunks at the current width are averaged (mid re-embed).
+	mock.ExpectExec(`INSERT INTO lesson_embeddings.*AVG\(embedding\).*vector_dims\(embedding\) = \$3`).WithArgs("t1", "l1", 512).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM lesson_embeddings`).WithArgs("t1", "l1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	f.refreshLessonEmbedding(context.Background(), "t1", "l1")
+
+	mock.ExpectExec(`INSERT INTO lesson_embeddings`).WithArgs("t1", "l2", 512).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	f.refreshLessonEmbedding(context.Background(), "t1", "l2")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
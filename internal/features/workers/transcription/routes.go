@@ -12,22 +12,75 @@ import (
 // Register mounts the slice's HTTP routes on r. r is expected to already
 // be scoped to `/api/v1/ai`; paths below are relative to that prefix.
 //
-// The slice owns five routes:
+// The slice owns these routes:
 //   - POST   /tenants/process-lessons          enqueue a TRANSCRIPTION job per selected (or all unprocessed) lesson
+//   - POST   /tenants/reembed-lessons          enqueue an EMBEDDING_GENERATION job per transcribed video in scope
+//   - POST   /tenants/summarize-lessons        enqueue a LESSON_SUMMARY job per transcribed video in scope
 //   - GET    /jobs/{jobId}                     poll job status / result
+//   - POST   /jobs/{jobId}/cancel              cancel a PENDING job, or stop a RUNNING one cooperatively
+//   - POST   /jobs/{jobId}/retry               re-queue a FAILED job with a fresh attempts budget
+//   - GET    /jobs/{jobId}/events              SSE stream of one job's status + stage/percent progress
+//   - POST   /tenants/{tenantId}/jobs/cancel   cancel every PENDING/RUNNING job of a tenant
+//   - POST   /tenants/{tenantId}/jobs/retry-failed re-queue every FAILED job of a tenant
+//   - POST   /tenants/{tenantId}/jobs/priority set the priority of a tenant's PENDING jobs
+//   - GET    /tenants/{tenantId}/jobs/events   SSE stream of a tenant's batch (per-job events + summary)
 //   - PATCH  /lessons/{lessonId}/transcription manually flip transcriptionCompleted (backwards compat)
-//   - POST   /search                           RAG cosine-similarity search over chunks
+//   - GET    /lessons/{lessonId}/captions      lesson transcript as WebVTT or SRT (?tenantId=&format=vtt|srt)
+//   - POST   /lessons/{lessonId}/quiz-drafts   generate multiple-choice quiz drafts from the lesson's chunks
+//   - GET    /lessons/{lessonId}/quiz-drafts   list a lesson's quiz drafts (?tenantId=&status=)
+//   - PATCH  /quiz-drafts/{draftId}            approve or reject a quiz draft
+//   - GET    /lessons/{lessonId}/transcript    newest transcript's segments, indexed for editing
+//   - PATCH  /lessons/{lessonId}/transcript    correct segment text; re-chunks + re-embeds only the affected ranges
+//   - GET    /lessons/{lessonId}/transcript/edits correction history of a lesson
+//   - GET    /lessons/{lessonId}/related       most similar lessons of the tenant (?tenantId=&limit=&userId=)
+//   - GET    /tenants/{tenantId}/transcript-glossary find/replace rules applied after each Whisper run
+//   - PUT    /tenants/{tenantId}/transcript-glossary replace those rules
+//   - POST   /search                           RAG cosine-similarity (or hybrid) search over video and PDF chunks
+//   - POST   /answer                           grounded "ask the course" answer with chunk citations
 //   - GET    /transcription-stats             { total, transcribed, pending } per scope
+//   - GET    /usage-report                    token_usage + job counts by month/tenant/course/model (JSON or CSV)
 //
-// All five gate on x-internal-api-key matching INTERNAL_AI_API_KEY. The
+// All of them gate on x-internal-api-key matching INTERNAL_AI_API_KEY. The
 // previous code path enforced this inline in each handler rather than via
 // middleware; we keep the same surface so existing callers don't break.
-func (f *Feature) Register(r chi.Router, _ MiddlewareSet) {
+//
+// The student routes use the tenant API key (mw.AuthExternal) instead:
+//   - POST   /student/search                   search limited to the lessons a user's deliveries release
+func (f *Feature) Register(r chi.Router, mw MiddlewareSet) {
 	r.Post("/tenants/process-lessons", f.ProcessLessonsTenant)
+	r.Post("/tenants/reembed-lessons", f.ReembedLessons)
+	r.Post("/tenants/summarize-lessons", f.SummarizeLessons)
 	r.Get("/jobs/{jobId}", f.GetJobStatus)
+	r.Post("/jobs/{jobId}/cancel", f.CancelJob)
+	r.Post("/jobs/{jobId}/retry", f.RetryJob)
+	r.Get("/jobs/{jobId}/events", f.StreamJobEvents)
+	r.Post("/tenants/{tenantId}/jobs/cancel", f.CancelTenantJobs)
+	r.Post("/tenants/{tenantId}/jobs/retry-failed", f.RetryTenantFailedJobs)
+	r.Post("/tenants/{tenantId}/jobs/priority", f.SetTenantJobsPriority)
+	r.Get("/tenants/{tenantId}/jobs/events", f.StreamTenantJobEvents)
 	r.Patch("/lessons/{lessonId}/transcription", f.UpdateLessonTranscription)
+	r.Get("/lessons/{lessonId}/captions", f.GetLessonCaptions)
+	r.Post("/lessons/{lessonId}/quiz-drafts", f.GenerateQuizDraft)
+	r.Get("/lessons/{lessonId}/quiz-drafts", f.ListQuizDrafts)
+	r.Patch("/quiz-drafts/{draftId}", f.ReviewQuizDraft)
+	r.Get("/lessons/{lessonId}/transcript", f.GetLessonTranscript)
+	r.Patch("/lessons/{lessonId}/transcript", f.EditLessonTranscript)
+	r.Get("/lessons/{lessonId}/transcript/edits", f.ListTranscriptEdits)
+	r.Get("/lessons/{lessonId}/related", f.RelatedLessons)
+	r.Get("/tenants/{tenantId}/transcript-glossary", f.GetTranscriptGlossary)
+	r.Put("/tenants/{tenantId}/transcript-glossary", f.PutTranscriptGlossary)
 	r.Post("/search", f.Search)
+	r.Post("/answer", f.Answer)
 	r.Get("/transcription-stats", f.GetTranscriptionStats)
+	r.Get("/usage-report", f.GetUsageReport)
+
+	if mw.AuthExternal != nil {
+		student := r.With(mw.AuthExternal)
+		if mw.RateLimitTenant != nil {
+			student = student.With(mw.RateLimitTenant)
+		}
+		student.Post("/student/search", f.StudentSearch)
+	}
 }
 
 // requireInternalAPIKey validates x-internal-api-key against the
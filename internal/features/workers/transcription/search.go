@@ -18,11 +18,29 @@ import (
 // one is supplied the narrowest non-empty one wins — the others are
 // ignored — so the admin UI can ship the full hierarchy without having
 // to clear sibling fields.
+//
+// `mode` picks the ranking: "vector" (default) is pure cosine similarity,
+// "hybrid" fuses it with Portuguese full-text rank (see
+// buildHybridSearchQuery) so exact terms — product names, acronyms, code
+// identifiers — surface even when their embedding is unremarkable.
+//
+// `language` keeps only chunks whose transcript was detected in that
+// language; `boostLanguage` keeps everything but ranks that language's
+// chunks first among near-equals. Both take an ISO-639-1 code or a locale
+// ("pt-BR" → "pt").
 type searchRequest struct {
-	TenantID string      `json:"tenantId"`
-	Query    string      `json:"query"`
-	Scope    searchScope `json:"scope"`
-	Limit    int         `json:"limit"`
+	TenantID      string      `json:"tenantId"`
+	Query         string      `json:"query"`
+	Scope         searchScope `json:"scope"`
+	Limit         int         `json:"limit"`
+	Mode          string      `json:"mode,omitempty"`
+	Language      string      `json:"language,omitempty"`
+	BoostLanguage string      `json:"boostLanguage,omitempty"`
+
+	// allowedLessonIDs is set by StudentSearch, never decoded from a body:
+	// nil searches the whole scope, non-nil (even empty) keeps only chunks
+	// of these lessons on top of the scope.
+	allowedLessonIDs []string
 }
 
 type searchScope struct {
@@ -35,7 +53,10 @@ type searchScope struct {
 // searchHit is one chunk returned to the caller. Similarity is the
 // cosine similarity (1 - distance), so 1.0 is a perfect match and 0.0
 // is orthogonal. start_time / end_time are seconds into the source
-// video so the frontend can deep-link.
+// video so the frontend can deep-link; a PDF hit (SourceType "PDF") has
+// no video or times and links to PageNumber instead. Score is only set in
+// hybrid mode: it is the fused RRF score the hits are ordered by,
+// meaningful only relative to the other hits of the same response.
 type searchHit struct {
 	ChunkID    string  `json:"chunkId"`
 	LessonID   string  `json:"lessonId"`
@@ -44,12 +65,16 @@ type searchHit struct {
 	Text       string  `json:"text"`
 	StartTime  float64 `json:"startTime"`
 	EndTime    float64 `json:"endTime"`
+	SourceType string  `json:"sourceType"`
+	PageNumber int     `json:"pageNumber,omitempty"`
 	Similarity float64 `json:"similarity"`
+	Score      float64 `json:"score,omitempty"`
 }
 
 type searchResponse struct {
 	TenantID string      `json:"tenantId"`
 	Query    string      `json:"query"`
+	Mode     string      `json:"mode"`
 	Scope    searchScope `json:"scope"`
 	Hits     []searchHit `json:"hits"`
 	Count    int         `json:"count"`
@@ -58,6 +83,33 @@ type searchResponse struct {
 const (
 	searchDefaultLimit = 10
 	searchMaxLimit     = 20
+
+	searchModeVector = "vector"
+	searchModeHybrid = "hybrid"
+
+	// rrfK is the reciprocal rank fusion constant: score = Σ 1/(k + rank).
+	// 60 is the value from the original RRF paper and what most engines
+	// ship; larger k flattens the advantage of being ranked first.
+	rrfK = 60
+
+	// hybridCandidates is how deep each ranking is read before fusion.
+	// A chunk ranked below this in both lists cannot make the top 20.
+	hybridCandidates = 50
+
+	// ftsConfig is the text search configuration for chunks.text. It must
+	// match the expression index in migrations/transcription/003, or
+	// Postgres falls back to a sequential scan.
+	ftsConfig = "portuguese"
+
+	// languageBoost is added to the cosine similarity of boostLanguage
+	// chunks in vector mode. Small on purpose: it reorders close calls,
+	// it does not lift an off-topic chunk over an on-topic one. Hybrid
+	// mode boosts by one extra first-place RRF vote instead.
+	languageBoost = 0.05
+
+	// languageRerankDepth is how many nearest chunks vector mode reads
+	// before applying languageBoost.
+	languageRerankDepth = hybridCandidates
 )
 
 // ---------- 1. HTTP handler ----------
@@ -74,7 +126,8 @@ const (
 //
 // The handler reuses the slice's embedBatch helper to embed the query,
 // then runs cosine similarity (`<=>` operator) against the HNSW index
-// on chunks.embedding.
+// on chunks.embedding. With `mode: "hybrid"` the same scope also filters
+// a full-text ranking and both are fused with RRF.
 func (f *Feature) Search(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
@@ -94,21 +147,14 @@ func (f *Feature) Search(w http.ResponseWriter, r *http.Request) {
 		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
 		return
 	}
-	req.Query = strings.TrimSpace(req.Query)
 	if req.TenantID == "" {
 		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
 		return
 	}
-	if req.Query == "" {
-		writeCustomError(w, http.StatusBadRequest, "query é obrigatório", "MISSING_QUERY")
+	if msg, code := normalizeSearchRequest(&req); code != "" {
+		writeCustomError(w, http.StatusBadRequest, msg, code)
 		return
 	}
-	if req.Limit <= 0 {
-		req.Limit = searchDefaultLimit
-	}
-	if req.Limit > searchMaxLimit {
-		req.Limit = searchMaxLimit
-	}
 
 	resp, status, err := f.searchChunks(r.Context(), req)
 	if err != nil {
@@ -121,6 +167,18 @@ func (f *Feature) Search(w http.ResponseWriter, r *http.Request) {
 // ---------- 2. Business rule ----------
 
 func (f *Feature) searchChunks(ctx context.Context, req searchRequest) (*searchResponse, int, error) {
+	resp := &searchResponse{
+		TenantID: req.TenantID,
+		Query:    req.Query,
+		Mode:     req.Mode,
+		Scope:    req.Scope,
+		Hits:     []searchHit{},
+	}
+	if req.allowedLessonIDs != nil && len(req.allowedLessonIDs) == 0 {
+		// Nothing is reachable: skip the embedding call altogether.
+		return resp, http.StatusOK, nil
+	}
+
 	// 1. Embed the query (single input batch).
 	vecs, _, err := f.embedBatch(ctx, []string{req.Query})
 	if err != nil {
@@ -139,7 +197,17 @@ func (f *Feature) searchChunks(ctx context.Context, req searchRequest) (*searchR
 
 	// 3. Build the SQL — scope dictates the WHERE clause and the
 	//    parameter layout. Embedding always rides $1; tenant always $2.
-	sqlText, args := buildSearchQuery(queryVec, req.TenantID, req.Scope, lessonIDs, req.Limit)
+	hybrid := req.Mode == searchModeHybrid
+	var (
+		sqlText string
+		args    []any
+	)
+	lang := searchLanguage{Only: req.Language, Boost: req.BoostLanguage}
+	if hybrid {
+		sqlText, args = buildHybridSearchQuery(queryVec, req.Query, req.TenantID, req.Scope, lessonIDs, req.allowedLessonIDs, lang, req.Limit)
+	} else {
+		sqlText, args = buildSearchQuery(queryVec, req.TenantID, req.Scope, lessonIDs, req.allowedLessonIDs, lang, req.Limit)
+	}
 
 	rows, err := f.transcriptionDB.QueryContext(ctx, sqlText, args...)
 	if err != nil {
@@ -150,31 +218,75 @@ func (f *Feature) searchChunks(ctx context.Context, req searchRequest) (*searchR
 	hits := make([]searchHit, 0, req.Limit)
 	for rows.Next() {
 		var (
-			h        searchHit
-			courseID *string
+			h          searchHit
+			courseID   *string
+			pageNumber *int
 		)
-		if err := rows.Scan(
+		dest := []any{
 			&h.ChunkID, &h.LessonID, &courseID, &h.VideoID,
-			&h.Text, &h.StartTime, &h.EndTime, &h.Similarity,
-		); err != nil {
+			&h.Text, &h.StartTime, &h.EndTime, &h.SourceType, &pageNumber, &h.Similarity,
+		}
+		if hybrid {
+			dest = append(dest, &h.Score)
+		}
+		if err := rows.Scan(dest...); err != nil {
 			return nil, http.StatusInternalServerError, fmt.Errorf("scan hit: %w", err)
 		}
 		if courseID != nil {
 			h.CourseID = *courseID
 		}
+		if pageNumber != nil {
+			h.PageNumber = *pageNumber
+		}
 		hits = append(hits, h)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, http.StatusInternalServerError, fmt.Errorf("iterate hits: %w", err)
 	}
 
-	return &searchResponse{
-		TenantID: req.TenantID,
-		Query:    req.Query,
-		Scope:    req.Scope,
-		Hits:     hits,
-		Count:    len(hits),
-	}, http.StatusOK, nil
+	resp.Hits, resp.Count = hits, len(hits)
+	return resp, http.StatusOK, nil
+}
+
+// normalizeSearchRequest trims the query and applies the limit, mode and
+// language defaults in place. A non-empty code means the request is
+// invalid; msg is the user-facing reason.
+func normalizeSearchRequest(req *searchRequest) (msg, code string) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return "query é obrigatório", "MISSING_QUERY"
+	}
+	if req.Limit <= 0 {
+		req.Limit = searchDefaultLimit
+	}
+	if req.Limit > searchMaxLimit {
+		req.Limit = searchMaxLimit
+	}
+	switch req.Mode {
+	case "":
+		req.Mode = searchModeVector
+	case searchModeVector, searchModeHybrid:
+	default:
+		return "mode deve ser \"vector\" ou \"hybrid\"", "INVALID_SEARCH_MODE"
+	}
+	if !normalizeSearchLanguages(req) {
+		return "language/boostLanguage deve ser um código ISO-639-1 (ex.: \"pt\", \"es\")", "INVALID_LANGUAGE"
+	}
+	return "", ""
+}
+
+// normalizeSearchLanguages rewrites language/boostLanguage to ISO-639-1 in
+// place. Returns false when a non-empty value is not a language code.
+func normalizeSearchLanguages(req *searchRequest) bool {
+	for _, v := range []*string{&req.Language, &req.BoostLanguage} {
+		if *v == "" {
+			continue
+		}
+		if *v = normalizeLanguage(*v); *v == "" {
+			return false
+		}
+	}
+	return true
 }
 
 // resolveScopeLessonIDs returns the explicit lesson_id list when the
@@ -221,40 +333,179 @@ func (f *Feature) fetchLessonIDs(ctx context.Context, query, arg string) ([]stri
 
 // ---------- 3. SQL ----------
 
+// searchLanguage carries the optional language filter (Only) and soft
+// preference (Boost), both already normalized. chunks has no language
+// column; both go through transcripts.language via transcript_id.
+type searchLanguage struct {
+	Only  string
+	Boost string
+}
+
 // buildSearchQuery composes the search SQL with the right WHERE clause
 // for the resolved scope. $1 is always the embedding vector literal,
 // $2 is always the tenant id, additional params depend on the scope.
 // `<=>` is pgvector's cosine distance operator; we expose `1 - distance`
 // as the similarity score so 1.0 means identical and 0.0 means
 // orthogonal.
-func buildSearchQuery(queryVec, tenantID string, scope searchScope, lessonIDs []string, limit int) (string, []any) {
+//
+// With lang.Boost set, the languageRerankDepth nearest chunks are read
+// first (still on the HNSW index) and re-ordered by similarity plus
+// languageBoost for the boosted language.
+//
+// allowed, when non-nil, is an access allow-list applied on top of the
+// scope (see appendAccessFilter).
+func buildSearchQuery(queryVec, tenantID string, scope searchScope, lessonIDs, allowed []string, lang searchLanguage, limit int) (string, []any) {
 	const base = `
-        SELECT id, lesson_id, course_id, video_id, text, start_time, end_time,
+        SELECT id, lesson_id, course_id, COALESCE(video_id, ''), text,
+               COALESCE(start_time, 0), COALESCE(end_time, 0), source_type, page_number,
                1 - (embedding <=> $1::vector) AS similarity
           FROM chunks
          WHERE tenant_id = $2 AND embedding IS NOT NULL
     `
 
-	args := []any{queryVec, tenantID}
-	var where string
+	args, where := appendScopeFilter([]any{queryVec, tenantID}, scope, lessonIDs)
+	args, accessWhere := appendAccessFilter(args, allowed)
+	args, langWhere := appendLanguageFilter(args, lang.Only)
+	where += accessWhere + langWhere
 
+	if lang.Boost != "" {
+		args = append(args, languageRerankDepth, lang.Boost, languageBoost, limit)
+		n := len(args)
+		return fmt.Sprintf(`
+        SELECT id, lesson_id, course_id, video_id, text, start_time, end_time,
+               source_type, page_number, similarity
+          FROM (
+                SELECT id, lesson_id, course_id, COALESCE(video_id, '') AS video_id, transcript_id, text,
+                       COALESCE(start_time, 0) AS start_time, COALESCE(end_time, 0) AS end_time,
+                       source_type, page_number,
+                       1 - (embedding <=> $1::vector) AS similarity
+                  FROM chunks
+                 WHERE tenant_id = $2 AND embedding IS NOT NULL%s
+                 ORDER BY embedding <=> $1::vector ASC
+                 LIMIT $%d
+               ) cand
+         ORDER BY similarity + CASE WHEN %s THEN $%d::float8 ELSE 0.0 END DESC, id
+         LIMIT $%d
+    `, where, n-3, languageMatch("transcript_id", n-2), n-1, n), args
+	}
+
+	args = append(args, limit)
+	tail := fmt.Sprintf(`
+         ORDER BY embedding <=> $1::vector ASC
+         LIMIT $%d
+    `, len(args))
+
+	return base + where + tail, args
+}
+
+// buildHybridSearchQuery fuses two rankings of the same scoped chunk set
+// with reciprocal rank fusion: the top hybridCandidates by cosine distance
+// and the top hybridCandidates by ts_rank_cd over
+// to_tsvector('portuguese', text). A chunk's score is Σ 1/(rrfK + rank)
+// over the lists it appears in, so one that ranks well in both beats one
+// that tops only one. Parameter layout: $1 embedding, $2 tenant, $3 raw
+// query text, then the scope param (if any), candidates, k, limit.
+//
+// websearch_to_tsquery never errors on user input (quotes, "or", "-"
+// work as on a search engine); a query made only of stopwords yields an
+// empty tsquery, the lexical list comes back empty, and the result
+// degrades to plain vector ranking.
+//
+// lang.Only narrows both rankings like the scope does. lang.Boost adds
+// 1/(rrfK+1) to the fused score of matching chunks — the same as ranking
+// first in one more list.
+func buildHybridSearchQuery(queryVec, queryText, tenantID string, scope searchScope, lessonIDs, allowed []string, lang searchLanguage, limit int) (string, []any) {
+	args, where := appendScopeFilter([]any{queryVec, tenantID, queryText}, scope, lessonIDs)
+	args, accessWhere := appendAccessFilter(args, allowed)
+	args, langWhere := appendLanguageFilter(args, lang.Only)
+	where += accessWhere + langWhere
+	args = append(args, hybridCandidates, rrfK, limit)
+	nCand, nK, nLimit := len(args)-2, len(args)-1, len(args)
+
+	scoreExpr := "fused.score"
+	if lang.Boost != "" {
+		args = append(args, lang.Boost)
+		scoreExpr = fmt.Sprintf("fused.score + CASE WHEN %s THEN 1.0 / ($%d + 1) ELSE 0 END",
+			languageMatch("c.transcript_id", len(args)), nK)
+	}
+
+	q := fmt.Sprintf(`
+        WITH vec AS (
+            SELECT id, ROW_NUMBER() OVER (ORDER BY embedding <=> $1::vector ASC) AS rnk
+              FROM chunks
+             WHERE tenant_id = $2 AND embedding IS NOT NULL%[1]s
+             ORDER BY embedding <=> $1::vector ASC
+             LIMIT $%[2]d
+        ),
+        lex AS (
+            SELECT id, ROW_NUMBER() OVER (ORDER BY ts_rank_cd(to_tsvector('%[5]s', text), q) DESC) AS rnk
+              FROM chunks, websearch_to_tsquery('%[5]s', $3) AS q
+             WHERE tenant_id = $2 AND to_tsvector('%[5]s', text) @@ q%[1]s
+             ORDER BY ts_rank_cd(to_tsvector('%[5]s', text), q) DESC
+             LIMIT $%[2]d
+        ),
+        fused AS (
+            SELECT id, SUM(1.0 / ($%[3]d + rnk)) AS score
+              FROM (SELECT id, rnk FROM vec UNION ALL SELECT id, rnk FROM lex) ranked
+             GROUP BY id
+        )
+        SELECT c.id, c.lesson_id, c.course_id, COALESCE(c.video_id, ''), c.text,
+               COALESCE(c.start_time, 0), COALESCE(c.end_time, 0), c.source_type, c.page_number,
+               COALESCE(1 - (c.embedding <=> $1::vector), 0) AS similarity,
+               %[6]s AS score
+          FROM fused
+          JOIN chunks c ON c.id = fused.id
+         ORDER BY %[6]s DESC, c.id
+         LIMIT $%[4]d
+    `, where, nCand, nK, nLimit, ftsConfig, scoreExpr)
+
+	return q, args
+}
+
+// appendScopeFilter appends the narrowest scope's parameter to args and
+// returns the matching AND clause on the unqualified chunks columns.
+// Shared by both search modes so they always narrow identically.
+func appendScopeFilter(args []any, scope searchScope, lessonIDs []string) ([]any, string) {
 	switch {
 	case scope.LessonID != "":
 		args = append(args, scope.LessonID)
-		where = fmt.Sprintf(" AND lesson_id = $%d", len(args))
+		return args, fmt.Sprintf(" AND lesson_id = $%d", len(args))
 	case len(lessonIDs) > 0:
 		args = append(args, pq.Array(lessonIDs))
-		where = fmt.Sprintf(" AND lesson_id = ANY($%d)", len(args))
+		return args, fmt.Sprintf(" AND lesson_id = ANY($%d)", len(args))
 	case scope.CourseID != "":
 		args = append(args, scope.CourseID)
-		where = fmt.Sprintf(" AND course_id = $%d", len(args))
+		return args, fmt.Sprintf(" AND course_id = $%d", len(args))
+	}
+	return args, ""
+}
+
+// appendAccessFilter narrows the chunks to the lessons a student can
+// reach. It is ANDed with the scope rather than replacing it, so a
+// course-scoped student search still stays inside that course.
+func appendAccessFilter(args []any, allowed []string) ([]any, string) {
+	if allowed == nil {
+		return args, ""
 	}
+	args = append(args, pq.Array(allowed))
+	return args, fmt.Sprintf(" AND lesson_id = ANY($%d)", len(args))
+}
 
-	args = append(args, limit)
-	tail := fmt.Sprintf(`
-         ORDER BY embedding <=> $1::vector ASC
-         LIMIT $%d
-    `, len(args))
+// appendLanguageFilter narrows to chunks whose transcript is in `lang`.
+// Chunks without a transcript (PDF pages) carry no language and always
+// pass. No-op when lang is empty.
+func appendLanguageFilter(args []any, lang string) ([]any, string) {
+	if lang == "" {
+		return args, ""
+	}
+	args = append(args, lang)
+	return args, fmt.Sprintf(" AND (transcript_id IS NULL OR %s)", languageMatch("transcript_id", len(args)))
+}
 
-	return base + where + tail, args
+// languageMatch is the predicate "this chunk's transcript is in the
+// language bound to $param". The subquery is scoped to the tenant ($2 in
+// both search builders) so it stays on the tenant's own transcripts. It is
+// NULL, not true, for chunks without a transcript.
+func languageMatch(transcriptCol string, param int) string {
+	return fmt.Sprintf("%s IN (SELECT id FROM transcripts WHERE tenant_id = $2 AND language = $%d)", transcriptCol, param)
 }
@@ -66,10 +66,10 @@ func TestSearch_HappyPath_TenantScopeOnly(t *testing.T) {
 		log:             logger.NewLogger(),
 	}
 
-	txMock.ExpectQuery(`SELECT id, lesson_id, course_id, video_id`).
+	txMock.ExpectQuery(`SELECT id, lesson_id, course_id, COALESCE\(video_id, ''\)`).
 		WithArgs("[0.1,0.2]", "t-1", 10).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "similarity"}).
-			AddRow("chunk-1", "lesson-1", "course-1", "video-1", "Texto exemplo", 0.0, 12.5, 0.87))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}).
+			AddRow("chunk-1", "lesson-1", "course-1", "video-1", "Texto exemplo", 0.0, 12.5, "VIDEO", nil, 0.87))
 
 	body, _ := json.Marshal(searchRequest{TenantID: "t-1", Query: "como funciona X"})
 	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewReader(body))
@@ -115,7 +115,7 @@ func TestSearch_LessonScopeFiltersDirectly(t *testing.T) {
 
 	txMock.ExpectQuery(`AND lesson_id = \$3`).
 		WithArgs("[0.5]", "t-1", "lesson-X", 10).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "similarity"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}))
 
 	body, _ := json.Marshal(searchRequest{
 		TenantID: "t-1",
@@ -166,7 +166,7 @@ func TestSearch_ModuleScopeResolvesViaMemberclass(t *testing.T) {
 
 	// Transcription: chunk search filtered by lesson_id = ANY(...).
 	txMock.ExpectQuery(`AND lesson_id = ANY\(\$3\)`).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "similarity"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}))
 
 	body, _ := json.Marshal(searchRequest{
 		TenantID: "t",
@@ -223,7 +223,7 @@ func TestSearch_LimitDefaultsAndClamps(t *testing.T) {
 			}
 			txMock.ExpectQuery(`SELECT`).
 				WithArgs("[0.1]", "t", tt.want).
-				WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "similarity"}))
+				WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}))
 
 			body, _ := json.Marshal(searchRequest{TenantID: "t", Query: "x", Limit: tt.input})
 			req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewReader(body))
@@ -239,3 +239,139 @@ func TestSearch_LimitDefaultsAndClamps(t *testing.T) {
 		})
 	}
 }
+
+func TestSearch_RejectsUnknownMode(t *testing.T) {
+	setEnvKey(t, "k")
+	transcriptionDB, _, _ := sqlmock.New()
+	defer transcriptionDB.Close()
+	f := &Feature{transcriptionDB: transcriptionDB, openaiAPIKey: "x", log: logger.NewLogger()}
+
+	body, _ := json.Marshal(searchRequest{TenantID: "t", Query: "x", Mode: "bm25"})
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.Search(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_SEARCH_MODE") {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestSearch_HybridFusesAndKeepsScope(t *testing.T) {
+	setEnvKey(t, "k")
+	transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer transcriptionDB.Close()
+
+	openai := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(embeddingsResponse{
+			Data:  []embedding{{Index: 0, Embedding: []float32{0.3}}},
+			Usage: usage{TotalTokens: 1},
+		})
+	}))
+	defer openai.Close()
+
+	f := &Feature{
+		transcriptionDB: transcriptionDB,
+		openaiAPIKey:    "k",
+		openaiBaseURL:   openai.URL,
+		httpClient:      openai.Client(),
+		log:             logger.NewLogger(),
+	}
+
+	txMock.ExpectQuery(`websearch_to_tsquery\('portuguese', \$3\).*AND course_id = \$4`).
+		WithArgs("[0.3]", "t", "SKU-42 checkout", "c-1", hybridCandidates, rrfK, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity", "score"}).
+			AddRow("c-lex", "l1", "c-1", "v1", "o SKU-42 no checkout", 10.0, 20.0, "VIDEO", nil, 0.41, 0.0325).
+			AddRow("c-vec", "l2", "c-1", "v2", "finalizando a compra", 0.0, 9.0, "VIDEO", nil, 0.88, 0.0164))
+
+	body, _ := json.Marshal(searchRequest{
+		TenantID: "t",
+		Query:    "SKU-42 checkout",
+		Scope:    searchScope{CourseID: "c-1"},
+		Limit:    5,
+		Mode:     searchModeHybrid,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.Search(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+	var resp searchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Mode != searchModeHybrid || resp.Count != 2 {
+		t.Fatalf("unexpected: %+v", resp)
+	}
+	if resp.Hits[0].ChunkID != "c-lex" || resp.Hits[0].Score != 0.0325 {
+		t.Fatalf("fused order lost: %+v", resp.Hits)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildHybridSearchQuery_ScopeAppliesToBothRankings(t *testing.T) {
+	q, args := buildHybridSearchQuery("[0.1]", "x", "t", searchScope{}, []string{"L1", "L2"}, nil, searchLanguage{}, 10)
+	if got := strings.Count(q, "lesson_id = ANY($4)"); got != 2 {
+		t.Fatalf("scope filter appears %d times, want 2 (vec + lex):\n%s", got, q)
+	}
+	if len(args) != 7 {
+		t.Fatalf("args = %d, want 7", len(args))
+	}
+	if !strings.Contains(q, "LIMIT $7") || !strings.Contains(q, "1.0 / ($6 + rnk)") {
+		t.Fatalf("param layout drifted:\n%s", q)
+	}
+}
+
+func TestBuildSearchQuery_LanguageFilterAndBoost(t *testing.T) {
+	q, args := buildSearchQuery("[0.1]", "t", searchScope{CourseID: "c"}, nil, nil,
+		searchLanguage{Only: "es"}, 10)
+	// PDF chunks have no transcript and must survive the filter; the
+	// subquery stays on the tenant's transcripts.
+	if !strings.Contains(q, "AND (transcript_id IS NULL OR transcript_id IN (SELECT id FROM transcripts WHERE tenant_id = $2 AND language = $4))") {
+		t.Fatalf("language filter missing:\n%s", q)
+	}
+	if len(args) != 5 || args[3] != "es" {
+		t.Fatalf("args = %v", args)
+	}
+
+	q, args = buildSearchQuery("[0.1]", "t", searchScope{}, nil, nil, searchLanguage{Boost: "en"}, 10)
+	if !strings.Contains(q, "LIMIT $3") || !strings.Contains(q, "language = $4) THEN $5::float8") || !strings.Contains(q, "LIMIT $6") {
+		t.Fatalf("boost layout drifted:\n%s", q)
+	}
+	if args[2] != languageRerankDepth || args[3] != "en" || args[4] != languageBoost || args[5] != 10 {
+		t.Fatalf("args = %v", args)
+	}
+}
+
+func TestBuildHybridSearchQuery_LanguageBoost(t *testing.T) {
+	q, args := buildHybridSearchQuery("[0.1]", "x", "t", searchScope{}, nil, nil, searchLanguage{Only: "pt", Boost: "pt"}, 10)
+	if got := strings.Count(q, "(transcript_id IS NULL OR transcript_id IN (SELECT id FROM transcripts WHERE tenant_id = $2 AND language = $4))"); got != 2 {
+		t.Fatalf("language filter appears %d times, want 2:\n%s", got, q)
+	}
+	if !strings.Contains(q, "c.transcript_id IN (SELECT id FROM transcripts WHERE tenant_id = $2 AND language = $8) THEN 1.0 / ($6 + 1)") {
+		t.Fatalf("boost term missing:\n%s", q)
+	}
+	if len(args) != 8 {
+		t.Fatalf("args = %d, want 8", len(args))
+	}
+}
+
+func TestSearch_RejectsUnknownLanguage(t *testing.T) {
+	setEnvKey(t, "k")
+	transcriptionDB, _, _ := sqlmock.New()
+	defer transcriptionDB.Close()
+	f := &Feature{transcriptionDB: transcriptionDB, openaiAPIKey: "x", log: logger.NewLogger()}
+
+	body, _ := json.Marshal(searchRequest{TenantID: "t", Query: "x", Language: "portugues"})
+	req := httptest.NewRequest(http.MethodPost, "/search", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.Search(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_LANGUAGE") {
+		t.Fatalf("status=%d body=%s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,228 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Video sources. A lesson's mediaUrl is matched against one resolver per
+// provider; the resolver either turns it into local audio parts or says
+// why the provider cannot be transcribed, which the enqueue handlers
+// surface as a skippedLesson reason.
+
+// videoSource is a lesson's mediaUrl resolved to a provider.
+type videoSource struct {
+	// Type is what videos.source_type records (SourceType*).
+	Type string
+	// URL is the lesson's mediaUrl as stored.
+	URL string
+	// LibraryID and GUID identify a Bunny Stream video.
+	LibraryID string
+	GUID      string
+	// AccessKey is the tenant's Bunny library key, filled in by the
+	// pipeline for Bunny sources.
+	AccessKey string
+}
+
+// sourceResolver is one video provider.
+type sourceResolver interface {
+	// Match reports whether the mediaUrl belongs to this provider.
+	Match(u *url.URL) bool
+	// Source validates the URL and builds the videoSource. An
+	// *unsupportedSourceError means lessons of this provider (or this
+	// particular URL) cannot be transcribed.
+	Source(u *url.URL) (videoSource, error)
+	// Resolve downloads the audio into tmpDir and returns the parts plus
+	// the duration in seconds (0 when the provider does not report one;
+	// the pipeline then uses the transcribed duration).
+	Resolve(ctx context.Context, src videoSource, tmpDir string) ([]string, float64, error)
+}
+
+// unsupportedSourceError carries the skip reason shown to the admin UI.
+// Provider is empty when the URL matched no known provider at all (a PDF,
+// an external page), which the "process all" path drops silently.
+type unsupportedSourceError struct {
+	Provider string
+	Reason   string
+}
+
+func (e *unsupportedSourceError) Error() string { return e.Reason }
+
+// sourceResolvers lists the providers in match order. Direct URLs go last
+// so a provider's own .m3u8 links are claimed by the provider first.
+func (f *Feature) sourceResolvers() []sourceResolver {
+	return []sourceResolver{
+		bunnySource{f: f},
+		unsupportedSource{
+			provider: "youtube",
+			hosts:    []string{"youtube.com", "youtu.be", "youtube-nocookie.com"},
+			reason:   "YouTube não suportado: os termos de uso não permitem baixar o áudio do vídeo",
+		},
+		unsupportedSource{
+			provider: "vimeo",
+			hosts:    []string{"vimeo.com"},
+			reason:   "Vimeo não suportado ainda: o download exige o token da API do Vimeo do produtor",
+		},
+		unsupportedSource{
+			provider: "panda",
+			hosts:    []string{"pandavideo.com.br", "pandavideo.com"},
+			reason:   "Panda Video não suportado ainda: o download exige a API key da Panda do produtor",
+		},
+		directSource{f: f},
+	}
+}
+
+// detectSource picks the resolver for a mediaUrl.
+func (f *Feature) detectSource(mediaURL string) (sourceResolver, videoSource, error) {
+	u, err := url.Parse(strings.TrimSpace(mediaURL))
+	if err != nil || u.Host == "" {
+		return nil, videoSource{}, &unsupportedSourceError{Reason: "mediaUrl inválida"}
+	}
+	for _, r := range f.sourceResolvers() {
+		if !r.Match(u) {
+			continue
+		}
+		src, err := r.Source(u)
+		if err != nil {
+			return nil, videoSource{}, err
+		}
+		src.URL = mediaURL
+		return r, src, nil
+	}
+	return nil, videoSource{}, &unsupportedSourceError{Reason: "mediaUrl não é um vídeo de provedor suportado"}
+}
+
+// hostIs reports whether host is domain or one of its subdomains.
+func hostIs(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// ---------- Bunny Stream ----------
+
+type bunnySource struct{ f *Feature }
+
+func (bunnySource) Match(u *url.URL) bool { return u.Hostname() == iframeMediaDeliveryDomain }
+
+func (bunnySource) Source(u *url.URL) (videoSource, error) {
+	libID, guid, err := guidFromEmbedURL(u.String())
+	if err != nil {
+		return videoSource{}, &unsupportedSourceError{Provider: "bunny", Reason: "URL do Bunny não é um embed de vídeo"}
+	}
+	return videoSource{Type: SourceTypeBunnyCDN, LibraryID: libID, GUID: guid}, nil
+}
+
+// Resolve validates the video via Bunny meta, then pulls HLS through
+// ffmpeg, splitting audio over Whisper's upload cap.
+func (b bunnySource) Resolve(ctx context.Context, src videoSource, tmpDir string) ([]string, float64, error) {
+	meta, err := b.f.fetchBunnyVideoMeta(ctx, src.LibraryID, src.GUID, src.AccessKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	playback, err := b.f.resolveBunnyPlayback(ctx, src.LibraryID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("resolve CDN playback for library %s: %w", src.LibraryID, err)
+	}
+	// 1h TTL covers download of a 4-hour aula at minimum bitrate; tokens
+	// only need to be valid long enough for ffmpeg to finish pulling.
+	hlsURL := buildHLSURL(playback, src.GUID, time.Now(), time.Hour)
+
+	full := filepath.Join(tmpDir, "audio.mp3")
+	if _, err := extractAudioMP3(ctx, hlsURL, full); err != nil {
+		return nil, 0, err
+	}
+	parts, err := splitIfOverWhisperCap(ctx, full, tmpDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parts, meta.Length, nil
+}
+
+// ---------- Direct MP4 / HLS ----------
+
+// directMediaExtensions are the file types pulled straight through
+// ffmpeg (DigitalOcean Spaces uploads, any plain CDN file).
+var directMediaExtensions = map[string]bool{
+	".mp4": true, ".m4v": true, ".mov": true, ".webm": true, ".mkv": true,
+	".m3u8": true, ".mp3": true, ".m4a": true, ".wav": true,
+}
+
+type directSource struct{ f *Feature }
+
+func (directSource) Match(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return directMediaExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// Source only accepts https URLs on a public hostname: the URL is tenant
+// content handed to ffmpeg, so IP literals and localhost are refused to
+// keep the worker from being pointed at internal services.
+func (directSource) Source(u *url.URL) (videoSource, error) {
+	host := u.Hostname()
+	if u.Scheme != "https" || host == "" || net.ParseIP(host) != nil ||
+		hostIs(host, "localhost") || !strings.Contains(host, ".") {
+		return videoSource{}, &unsupportedSourceError{
+			Provider: "direct",
+			Reason:   "URL direta de vídeo precisa ser https em um domínio público",
+		}
+	}
+	return videoSource{Type: SourceTypeDirectURL}, nil
+}
+
+func (directSource) Resolve(ctx context.Context, src videoSource, tmpDir string) ([]string, float64, error) {
+	full := filepath.Join(tmpDir, "audio.mp3")
+	if _, err := extractDirectAudioMP3(ctx, src.URL, full); err != nil {
+		return nil, 0, err
+	}
+	parts, err := splitIfOverWhisperCap(ctx, full, tmpDir)
+	return parts, 0, err
+}
+
+// ---------- Unsupported providers ----------
+
+// unsupportedSource recognises a provider only to explain why its lessons
+// are skipped.
+type unsupportedSource struct {
+	provider string
+	hosts    []string
+	reason   string
+}
+
+func (s unsupportedSource) Match(u *url.URL) bool {
+	for _, h := range s.hosts {
+		if hostIs(u.Hostname(), h) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s unsupportedSource) Source(*url.URL) (videoSource, error) {
+	return videoSource{}, &unsupportedSourceError{Provider: s.provider, Reason: s.reason}
+}
+
+func (s unsupportedSource) Resolve(context.Context, videoSource, string) ([]string, float64, error) {
+	return nil, 0, &unsupportedSourceError{Provider: s.provider, Reason: s.reason}
+}
+
+// splitIfOverWhisperCap returns `full` as the only part when it fits one
+// Whisper upload, else 10-minute parts.
+func splitIfOverWhisperCap(ctx context.Context, full, tmpDir string) ([]string, error) {
+	info, err := os.Stat(full)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= whisperMaxAudioBytes {
+		return []string{full}, nil
+	}
+	return splitAudioByDuration(ctx, full, tmpDir, 600)
+}
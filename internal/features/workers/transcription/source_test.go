@@ -0,0 +1,178 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func TestDetectSource(t *testing.T) {
+	f := &Feature{}
+	cases := []struct {
+		url      string
+		typ      string // "" = not transcribable
+		provider string // provider of the unsupportedSourceError
+	}{
+		{"https://iframe.mediadelivery.net/embed/383534/abc?autoplay=false", SourceTypeBunnyCDN, ""},
+		{"https://iframe.mediadelivery.net/play/383534/abc", "", "bunny"},
+		{"https://memberclass.nyc3.digitaloceanspaces.com/aulas/aula-1.mp4", SourceTypeDirectURL, ""},
+		{"https://cdn.example.com/hls/aula/playlist.m3u8?token=x", SourceTypeDirectURL, ""},
+		{"http://cdn.example.com/aula.mp4", "", "direct"},
+		{"https://10.0.0.5/aula.mp4", "", "direct"},
+		{"https://localhost/aula.mp4", "", "direct"},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "", "youtube"},
+		{"https://youtu.be/dQw4w9WgXcQ", "", "youtube"},
+		{"https://player.vimeo.com/video/76979871", "", "vimeo"},
+		{"https://player-vz-1234.tv.pandavideo.com.br/embed/?v=abc", "", "panda"},
+		{"https://memberclass.nyc3.digitaloceanspaces.com/apostila.pdf", "", ""},
+		{"not a url", "", ""},
+	}
+	for _, c := range cases {
+		_, src, err := f.detectSource(c.url)
+		if c.typ != "" {
+			if err != nil || src.Type != c.typ || src.URL != c.url {
+				t.Errorf("%s: got %+v, %v; want type %s", c.url, src, err, c.typ)
+			}
+			continue
+		}
+		var unsupported *unsupportedSourceError
+		if !errors.As(err, &unsupported) {
+			t.Errorf("%s: err = %v, want unsupportedSourceError", c.url, err)
+			continue
+		}
+		if unsupported.Provider != c.provider || unsupported.Reason == "" {
+			t.Errorf("%s: got %+v, want provider %q", c.url, unsupported, c.provider)
+		}
+	}
+}
+
+func TestProcessLessonsTenant_SkipsUnsupportedProviders(t *testing.T) {
+	setEnvKey(t, "k")
+	transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer transcriptionDB.Close()
+	defer memberclassDB.Close()
+	f := &Feature{
+		transcriptionDB: transcriptionDB,
+		memberclassDB:   memberclassDB,
+		openaiAPIKey:    "x",
+		log:             logger.NewLogger(),
+	}
+
+	mcMock.ExpectQuery(`FROM "Tenant"`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t1", "T", true, nil, nil))
+	lessonCols := []string{
+		"id", "name", "slug", "type", "mediaUrl", "thumbnail", "content",
+		"module_id", "module_name", "section_id", "section_name",
+		"course_id", "course_name", "vitrine_id", "vitrine_name",
+	}
+	row := func(id, mediaURL string) []driver.Value {
+		return []driver.Value{id, "Aula", "aula", nil, mediaURL, nil, nil, "m", "M", "s", "S", "c", "C", "v", "V"}
+	}
+	mcMock.ExpectQuery(`FROM "Lesson"`).WithArgs("t1").
+		WillReturnRows(sqlmock.NewRows(lessonCols).
+			AddRow(row("l1", "https://memberclass.nyc3.digitaloceanspaces.com/aulas/aula-1.mp4")...).
+			AddRow(row("l2", "https://youtu.be/abc")...).
+			AddRow(row("l3", "https://memberclass.nyc3.digitaloceanspaces.com/apostila.pdf")...))
+	txMock.ExpectExec(`INSERT INTO jobs`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	body, _ := json.Marshal(processLessonsRequest{TenantID: "t1"})
+	req := httptest.NewRequest(http.MethodPost, "/tenants/process-lessons", bytes.NewReader(body))
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	f.ProcessLessonsTenant(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("status = %d body=%s", w.Code, w.Body.String())
+	}
+	var resp processLessonsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	// The MP4 is enqueued, YouTube is reported, the PDF is not a video.
+	if resp.EnqueuedCount != 1 || resp.Enqueued[0].LessonID != "l1" {
+		t.Fatalf("enqueued = %+v", resp.Enqueued)
+	}
+	if len(resp.Skipped) != 1 || resp.Skipped[0].LessonID != "l2" || resp.Skipped[0].Reason == "" {
+		t.Fatalf("skipped = %+v", resp.Skipped)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteJob_DirectURLNeedsNoBunnyCredentials(t *testing.T) {
+	transcriptionDB, txMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer transcriptionDB.Close()
+	memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer memberclassDB.Close()
+
+	const mediaURL = "https://memberclass.nyc3.digitaloceanspaces.com/aulas/aula-1.mp4"
+	var resolved videoSource
+	audio := fakeAudio(t)
+	f := &Feature{
+		transcriptionDB: transcriptionDB,
+		memberclassDB:   memberclassDB,
+		log:             logger.NewLogger(),
+		openaiAPIKey:    "test-key",
+		openaiBaseURL:   "https://openai.invalid",
+		httpClient:      http.DefaultClient,
+		stt: sttConfig{
+			backends: map[string]transcriber{"stub": &stubTranscriber{}},
+			fallback: "stub",
+		},
+		testHookResolveAudio: func(ctx context.Context, src videoSource, tmpDir string) ([]string, float64, error) {
+			resolved = src
+			return audio(ctx, src, tmpDir)
+		},
+	}
+
+	// Tenant without Bunny credentials: fine for a direct URL. The
+	// pipeline then stops at the embeddings call (no fake OpenAI here);
+	// reaching it proves the source was resolved.
+	mcMock.ExpectQuery(`FROM "Tenant"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t", "T", true, nil, nil))
+	expectNoCheckpoints(txMock, "job-d")
+
+	payload, _ := json.Marshal(jobPayload{LessonID: "l", TenantID: "t", VideoURL: mediaURL})
+	err := f.executeJob(context.Background(), "job-d", "t", payload)
+	if err == nil {
+		t.Fatal("expected the embeddings call to fail")
+	}
+	if resolved.Type != SourceTypeDirectURL || resolved.URL != mediaURL || resolved.AccessKey != "" {
+		t.Fatalf("resolved = %+v (err %v)", resolved, err)
+	}
+}
+
+func TestExecuteJob_RejectsUnsupportedProvider(t *testing.T) {
+	memberclassDB, mcMock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer memberclassDB.Close()
+	transcriptionDB, _, _ := sqlmock.New()
+	defer transcriptionDB.Close()
+	f := &Feature{
+		transcriptionDB: transcriptionDB,
+		memberclassDB:   memberclassDB,
+		log:             logger.NewLogger(),
+		openaiAPIKey:    "test-key",
+	}
+	mcMock.ExpectQuery(`FROM "Tenant"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "aiEnabled", "bunnyLibraryId", "bunnyLibraryApiKey"}).
+			AddRow("t", "T", true, "lib", "key"))
+
+	payload, _ := json.Marshal(jobPayload{LessonID: "l", TenantID: "t", VideoURL: "https://vimeo.com/76979871"})
+	err := f.executeJob(context.Background(), "job-v", "t", payload)
+	var unsupported *unsupportedSourceError
+	if !errors.As(err, &unsupported) || unsupported.Provider != "vimeo" {
+		t.Fatalf("err = %v, want vimeo unsupportedSourceError", err)
+	}
+}
@@ -11,13 +11,22 @@ const (
 	JobStatusCompleted = "COMPLETED"
 	JobStatusFailed    = "FAILED"
 	JobStatusCancelled = "CANCELLED"
+	// JobStatusOverBudget parks a job whose tenant hit its monthly AI
+	// spend cap (see budget.go). Added by migrations/transcription/004.
+	JobStatusOverBudget = "OVER_BUDGET"
 
 	// JobTypeVideoProcessing groups the whole pipeline (download → audio →
-	// Whisper → chunk → embed). The legacy schema also has
-	// EMBEDDING_GENERATION for embedding-only reruns; we don't enqueue
-	// those yet but keep the constant available.
+	// Whisper → chunk → embed). JobTypeEmbeddingGeneration re-chunks and
+	// re-embeds a video from its stored transcripts.segments, without
+	// touching Bunny or Whisper (see reembed.go). JobTypeLessonSummary
+	// writes the LLM summary, chapters and key points of a transcribed
+	// video (see summary.go; added by migrations/transcription/009).
+	// JobTypePdfEmbedding chunks and embeds the stored page text of a PDF
+	// lesson (see pdf.go; added by migrations/transcription/013).
 	JobTypeVideoProcessing     = "VIDEO_PROCESSING"
 	JobTypeEmbeddingGeneration = "EMBEDDING_GENERATION"
+	JobTypeLessonSummary       = "LESSON_SUMMARY"
+	JobTypePdfEmbedding        = "PDF_EMBEDDING"
 
 	VideoStatusPending              = "PENDING"
 	VideoStatusDownloading          = "DOWNLOADING"
@@ -28,7 +37,13 @@ const (
 	VideoStatusCompleted            = "COMPLETED"
 	VideoStatusFailed               = "FAILED"
 
-	SourceTypeBunnyCDN = "BUNNY_CDN"
+	SourceTypeBunnyCDN  = "BUNNY_CDN"
+	SourceTypeDirectURL = "DIRECT_URL"
+
+	// chunks.source_type: what a chunk was cut from. VIDEO chunks carry
+	// start_time/end_time, PDF chunks a page_number (migrations/transcription/014).
+	ChunkSourceVideo = "VIDEO"
+	ChunkSourcePDF   = "PDF"
 )
 
 // ============================================================================
@@ -36,28 +51,80 @@ const (
 // ============================================================================
 
 // sqlClaimJobs atomically grabs up to $1 PENDING rows of type
-// VIDEO_PROCESSING and flips them to RUNNING. FOR UPDATE SKIP LOCKED makes
-// the claim concurrent-safe so multiple worker goroutines do not collide
-// (Postgres-only — CockroachDB would need a different pattern, but this DB
-// is plain Postgres on Railway).
+// VIDEO_PROCESSING, EMBEDDING_GENERATION, LESSON_SUMMARY or PDF_EMBEDDING
+// and flips them to RUNNING; the type comes back so the worker can
+// dispatch. FOR UPDATE SKIP LOCKED makes the claim concurrent-safe so
+// multiple worker goroutines do not collide (Postgres-only — CockroachDB
+// would need a different pattern, but this DB is plain Postgres on
+// Railway).
+//
+// The type is compared as text: an enum literal the database does not
+// know is an error, and claiming must keep working on a DB where
+// migrations 009 (LESSON_SUMMARY) and 013 (PDF_EMBEDDING) have not run.
+// Each only has to run before the first job of its type is enqueued.
+//
+// The claim is fair across tenants: each tenant's next $1 jobs are locked
+// (LATERAL, so one big batch cannot crowd the others out of the window)
+// and numbered after the jobs the tenant already has RUNNING. Within a
+// priority level the lowest slot wins, so tenants take turns instead of
+// one 1,000-lesson batch starving everyone for hours; a higher priority
+// still goes first. $2 > 0 caps a tenant's RUNNING jobs. The cap is soft:
+// two replicas claiming at once both count the same RUNNING rows.
 const sqlClaimJobs = `
+    WITH running AS (
+        SELECT tenant_id, COUNT(*) AS n
+          FROM jobs
+         WHERE status = 'RUNNING'
+           AND type::text IN ('VIDEO_PROCESSING', 'EMBEDDING_GENERATION', 'LESSON_SUMMARY', 'PDF_EMBEDDING')
+         GROUP BY tenant_id
+    ),
+    tenants AS (
+        SELECT DISTINCT tenant_id
+          FROM jobs
+         WHERE status = 'PENDING'
+           AND type::text IN ('VIDEO_PROCESSING', 'EMBEDDING_GENERATION', 'LESSON_SUMMARY', 'PDF_EMBEDDING')
+           AND attempts < max_attempts
+    ),
+    candidates AS (
+        SELECT c.id, c.tenant_id, c.priority, c.created_at
+          FROM tenants t
+         CROSS JOIN LATERAL (
+            SELECT id, tenant_id, priority, created_at
+              FROM jobs
+             WHERE tenant_id = t.tenant_id
+               AND status = 'PENDING'
+               AND type::text IN ('VIDEO_PROCESSING', 'EMBEDDING_GENERATION', 'LESSON_SUMMARY', 'PDF_EMBEDDING')
+               AND attempts < max_attempts
+             ORDER BY priority DESC, created_at ASC
+             LIMIT $1
+             FOR UPDATE SKIP LOCKED
+         ) c
+    ),
+    ranked AS (
+        SELECT c.id, c.priority, c.created_at,
+               COALESCE(r.n, 0) + ROW_NUMBER() OVER (
+                   PARTITION BY c.tenant_id ORDER BY c.priority DESC, c.created_at ASC
+               ) AS slot
+          FROM candidates c
+          LEFT JOIN running r ON r.tenant_id = c.tenant_id
+    )
     UPDATE jobs
        SET status     = 'RUNNING',
            started_at = now(),
            attempts   = attempts + 1,
            updated_at = now()
      WHERE id IN (
-        SELECT id FROM jobs
-         WHERE status = 'PENDING'
-           AND type   = 'VIDEO_PROCESSING'
-           AND attempts < max_attempts
-         ORDER BY priority DESC, created_at ASC
-         FOR UPDATE SKIP LOCKED
+        SELECT id FROM ranked
+         WHERE $2 <= 0 OR slot <= $2
+         ORDER BY priority DESC, slot ASC, created_at ASC
          LIMIT $1
      )
-    RETURNING id, tenant_id, payload, attempts, max_attempts
+    RETURNING id, tenant_id, payload, attempts, max_attempts, type
 `
 
+// sqlMarkJobCompleted finishes a RUNNING job. Like sqlMarkJobFailed it is
+// guarded on status = 'RUNNING', so a cancel that lands while the
+// pipeline is finishing stays CANCELLED; no row is updated in that case.
 const sqlMarkJobCompleted = `
     UPDATE jobs
        SET status       = 'COMPLETED',
@@ -65,11 +132,14 @@ const sqlMarkJobCompleted = `
            result       = $2::jsonb,
            updated_at   = now()
      WHERE id = $1
+       AND status = 'RUNNING'
 `
 
 // sqlMarkJobFailed bumps a job back to PENDING if it has retries left;
 // otherwise terminates it as FAILED. RETURNING lets the caller log the
-// transition.
+// transition. The status = 'RUNNING' guard keeps a job that was cancelled
+// mid-run from being resurrected into PENDING by its own failure path —
+// no row comes back in that case.
 //
 // The ::job_status casts are required: Postgres cannot infer the column's
 // enum type through a CASE WHEN whose branches are bare text literals.
@@ -82,6 +152,7 @@ const sqlMarkJobFailed = `
            error      = $2,
            updated_at = now()
      WHERE id = $1
+       AND status = 'RUNNING'
     RETURNING status
 `
 
@@ -98,11 +169,87 @@ const sqlResetOrphans = `
 `
 
 const sqlGetJobStatus = `
-    SELECT id, tenant_id, status, attempts, error, started_at, completed_at, payload, result
+    SELECT id, tenant_id, status, attempts, error, started_at, completed_at, payload, result, progress
       FROM jobs
      WHERE id = $1
 `
 
+// sqlSelectJobState is the cheap status probe the worker's cancellation
+// watcher runs while a job is in flight, and the cancel/retry handlers run
+// to tell "not found" from "wrong state".
+const sqlSelectJobState = `SELECT status FROM jobs WHERE id = $1`
+
+// sqlCancelJob flips a PENDING, RUNNING or OVER_BUDGET job to CANCELLED.
+// Terminal rows (COMPLETED / FAILED / CANCELLED) are left alone and
+// produce no row.
+// A RUNNING job is stopped cooperatively: the worker that owns it sees
+// the status (or the in-process signal) and cancels the job's context.
+const sqlCancelJob = `
+    UPDATE jobs
+       SET status     = 'CANCELLED',
+           updated_at = now()
+     WHERE id = $1
+       AND status IN ('PENDING', 'RUNNING', 'OVER_BUDGET')
+    RETURNING id
+`
+
+// sqlCancelTenantJobs is the bulk variant for a misfired batch: every
+// PENDING, RUNNING or OVER_BUDGET job of the tenant, regardless of type.
+const sqlCancelTenantJobs = `
+    UPDATE jobs
+       SET status     = 'CANCELLED',
+           updated_at = now()
+     WHERE tenant_id = $1
+       AND status IN ('PENDING', 'RUNNING', 'OVER_BUDGET')
+    RETURNING id
+`
+
+// sqlRetryFailedJob re-queues a FAILED job with a fresh attempts budget.
+// error/failed_at/started_at are cleared so GET /jobs/{id} doesn't show
+// the previous run's outcome next to the new PENDING status.
+const sqlRetryFailedJob = `
+    UPDATE jobs
+       SET status       = 'PENDING',
+           attempts     = 0,
+           error        = NULL,
+           failed_at    = NULL,
+           started_at   = NULL,
+           completed_at = NULL,
+           updated_at   = now()
+     WHERE id = $1
+       AND status = 'FAILED'
+    RETURNING id
+`
+
+// sqlSetTenantJobsPriority moves a tenant's PENDING jobs, optionally of
+// one type, to priority $2 so the claim picks them before other tenants'.
+//
+// $1 tenant_id, $2 priority, $3 type ('' = any).
+const sqlSetTenantJobsPriority = `
+    UPDATE jobs
+       SET priority   = $2,
+           updated_at = now()
+     WHERE tenant_id = $1
+       AND status = 'PENDING'
+       AND ($3 = '' OR type::text = $3)
+    RETURNING id
+`
+
+// sqlRetryTenantFailedJobs re-queues every FAILED job of the tenant.
+const sqlRetryTenantFailedJobs = `
+    UPDATE jobs
+       SET status       = 'PENDING',
+           attempts     = 0,
+           error        = NULL,
+           failed_at    = NULL,
+           started_at   = NULL,
+           completed_at = NULL,
+           updated_at   = now()
+     WHERE tenant_id = $1
+       AND status = 'FAILED'
+    RETURNING id
+`
+
 // sqlInsertJob is used by both the HTTP enqueue handler and the daily cron
 // to push one TRANSCRIPTION job per unprocessed lesson.
 const sqlInsertJob = `
@@ -110,6 +257,68 @@ const sqlInsertJob = `
     VALUES ($1, $2, 'VIDEO_PROCESSING', 'PENDING', $3, $4::jsonb, $5, now(), now())
 `
 
+// sqlInsertEmbeddingJob enqueues one EMBEDDING_GENERATION job for a video
+// unless one is already PENDING or RUNNING for it — re-clicking "re-embed"
+// in the admin UI must not pay for the same video twice. No row comes back
+// when the job was deduplicated.
+//
+// $1 id, $2 tenant_id, $3 priority, $4 payload, $5 max_attempts, $6 video id.
+const sqlInsertEmbeddingJob = `
+    INSERT INTO jobs (id, tenant_id, type, status, priority, payload, max_attempts, created_at, updated_at)
+    SELECT $1, $2, 'EMBEDDING_GENERATION', 'PENDING', $3, $4::jsonb, $5, now(), now()
+     WHERE NOT EXISTS (
+        SELECT 1 FROM jobs
+         WHERE type   = 'EMBEDDING_GENERATION'
+           AND status IN ('PENDING', 'RUNNING')
+           AND payload->>'videoId' = $6::text
+     )
+    RETURNING id
+`
+
+// sqlSelectReembedTargets lists the current video of each lesson of a
+// tenant, with its newest transcript, optionally narrowed to one course
+// ($2, '' disables) and/or an explicit lesson id set ($3, empty array
+// disables). A lesson's current video is the one with the newest
+// transcript (see sqlSelectLessonCurrentVideo); older videos are
+// superseded recordings whose chunks are already gone and must not come
+// back. Videos without a lesson are each their own group.
+const sqlSelectReembedTargets = `
+    SELECT DISTINCT ON (COALESCE(v.lesson_id, v.id))
+           v.id, COALESCE(v.lesson_id, ''), COALESCE(v.course_id, ''), t.id
+      FROM videos v
+      JOIN transcripts t ON t.video_id = v.id
+     WHERE v.tenant_id = $1
+       AND ($2 = '' OR v.course_id = $2)
+       AND (cardinality($3::text[]) = 0 OR v.lesson_id = ANY($3::text[]))
+     ORDER BY COALESCE(v.lesson_id, v.id), t.created_at DESC
+`
+
+// sqlSelectLessonCurrentVideo returns the video a lesson is currently
+// transcribed from: the one with the newest transcript. Only the pipeline
+// inserts transcripts, so a new recording (or a reprocess) moves it.
+const sqlSelectLessonCurrentVideo = `
+    SELECT t.video_id
+      FROM transcripts t
+      JOIN videos v ON v.id = t.video_id
+     WHERE v.tenant_id = $1
+       AND v.lesson_id = $2
+     ORDER BY t.created_at DESC
+     LIMIT 1
+`
+
+// sqlSelectTranscriptForReembed loads what an EMBEDDING_GENERATION job
+// re-chunks. The tenant predicate keeps a forged payload from touching
+// another tenant's video.
+const sqlSelectTranscriptForReembed = `
+    SELECT t.id, t.segments, COALESCE(v.lesson_id, ''), COALESCE(v.course_id, '')
+      FROM transcripts t
+      JOIN videos v ON v.id = t.video_id
+     WHERE t.video_id  = $1
+       AND v.tenant_id = $2
+     ORDER BY t.created_at DESC
+     LIMIT 1
+`
+
 // sqlUpsertVideo keyed on (tenant_id, source_url). On conflict we keep the
 // pre-existing id and refresh status/updated_at so the pipeline can pick
 // up a reprocess without orphaning chunks.
@@ -164,7 +373,7 @@ const chunksTable = `chunks`
 var chunksColumns = []string{
 	"id", "video_id", "transcript_id", "tenant_id", "course_id", "lesson_id",
 	"text", "order", "start_time", "end_time", "embedding", "embedding_model",
-	"provider", "metadata", "created_at", "updated_at",
+	"provider", "metadata", "created_at", "updated_at", "source_type", "page_number",
 }
 
 const sqlInsertTokenUsage = `
@@ -195,13 +404,16 @@ const sqlSelectTenantBunnyCreds = `
 // Lesson hierarchy (Lesson has no direct tenantId — the relationship is
 // owned by Vitrine), filtered to the explicit set of lessonIds the admin
 // UI selected. The Vitrine tenantId predicate prevents one tenant from
-// enqueueing lessons that belong to another. The `mediaUrl LIKE` clause
-// keeps non-Bunny rows (PDF, text) out of the transcription path.
+// enqueueing lessons that belong to another. Every lesson with an http(s)
+// mediaUrl comes back: detectSource decides per row whether it can be
+// transcribed and, if not, the reason the admin UI shows.
 //
 // $1 = tenantId, $2 = lesson id array (pq.Array(ids)).
-// sqlSelectUnprocessedLessons returns every Bunny-backed, unprocessed
-// lesson under a tenant. Used by the enqueue handler when the caller
-// did not pass an explicit lessonIds list (i.e. "process all pending").
+// sqlSelectUnprocessedLessons returns every unprocessed lesson with an
+// http(s) mediaUrl under a tenant; detectSource sorts out which of them
+// are videos it can transcribe. Used by the enqueue handler when the
+// caller did not pass an explicit lessonIds list (i.e. "process all
+// pending").
 const sqlSelectUnprocessedLessons = `
     SELECT l.id,
            l.name,
@@ -226,7 +438,7 @@ const sqlSelectUnprocessedLessons = `
      WHERE v."tenantId" = $1
        AND l.published  = true
        AND COALESCE(l."transcriptionCompleted", false) = false
-       AND l."mediaUrl" LIKE '%https://iframe.mediadelivery.net%'
+       AND l."mediaUrl" ~* '^https?://'
      ORDER BY COALESCE(v."order", 0) ASC,
               COALESCE(c."order", 0) ASC,
               COALESCE(s."order", 0) ASC,
@@ -234,10 +446,15 @@ const sqlSelectUnprocessedLessons = `
               COALESCE(l."order", 0) ASC
 `
 
+// sqlTranscribableMediaURL is the SQL side of the source resolvers: a
+// Bunny embed or a direct media file URL (directMediaExtensions). Lessons
+// of unsupported providers are left out; detectSource stays the final
+// word on each row.
+const sqlTranscribableMediaURL = `l."mediaUrl" ~* '^https://(iframe\.mediadelivery\.net/embed/|[^?#]+\.(mp4|m4v|mov|webm|mkv|m3u8|mp3|m4a|wav)([?#]|$))'`
+
 // sqlTranscriptionStats reports per-tenant (and optionally per-course /
-// per-module) counts of lessons split by transcriptionCompleted. Bunny-
-// only filter mirrors the enqueue queries so the stats line up with what
-// the pipeline can actually process.
+// per-module) counts of lessons split by transcriptionCompleted, over the
+// lessons sqlTranscribableMediaURL says the pipeline can process.
 //
 // $1 tenantId, $2 courseId or '' (empty disables the filter), $3 moduleId or ''.
 const sqlTranscriptionStats = `
@@ -252,7 +469,7 @@ const sqlTranscriptionStats = `
       JOIN "Vitrine" v ON c."vitrineId" = v.id
      WHERE v."tenantId" = $1
        AND l.published  = true
-       AND l."mediaUrl" LIKE '%https://iframe.mediadelivery.net%'
+       AND ` + sqlTranscribableMediaURL + `
        AND ($2 = '' OR c.id = $2)
        AND ($3 = '' OR m.id = $3)
 `
@@ -297,7 +514,7 @@ const sqlSelectLessonsByIDs = `
       JOIN "Vitrine" v ON c."vitrineId" = v.id
      WHERE l.id = ANY($2)
        AND v."tenantId" = $1
-       AND l."mediaUrl" LIKE '%https://iframe.mediadelivery.net%'
+       AND l."mediaUrl" ~* '^https?://'
 `
 
 const sqlMarkLessonTranscribed = `
@@ -314,3 +531,723 @@ const sqlMarkLessonTranscriptionStatus = `
      WHERE id = $1
 `
 
+
+// sqlSelectLessonSegments loads the newest transcript of a lesson for
+// caption export. Joined through videos so the tenant predicate applies.
+const sqlSelectLessonSegments = `
+    SELECT t.segments
+      FROM transcripts t
+      JOIN videos v ON v.id = t.video_id
+     WHERE v.tenant_id = $1
+       AND t.lesson_id = $2
+     ORDER BY t.created_at DESC
+     LIMIT 1
+`
+
+// sqlSelectTenantLanguage reads the tenant's configured locale, used as
+// the Whisper language hint. Kept apart from sqlSelectTenantBunnyCreds so
+// the enqueue paths don't pay for a column they never read.
+const sqlSelectTenantLanguage = `
+    SELECT "language"
+      FROM "Tenant"
+     WHERE id = $1
+`
+
+// sqlSelectTenantMonthSpend sums the tenant's token_usage for the current
+// calendar month (database clock, UTC on Railway).
+const sqlSelectTenantMonthSpend = `
+    SELECT COALESCE(SUM(total_cost_cents), 0)::int
+      FROM token_usage
+     WHERE tenant_id  = $1
+       AND created_at >= date_trunc('month', now())
+`
+
+// sqlSelectTenantReservedCents sums the enqueue-time estimates of the
+// tenant's queued and running jobs (jobPayload.EstimatedCostCents). Jobs
+// enqueued while the tenant had no cap carry no estimate and count as 0.
+const sqlSelectTenantReservedCents = `
+    SELECT COALESCE(SUM(COALESCE((payload->>'estimatedCostCents')::int, 0)), 0)::int
+      FROM jobs
+     WHERE tenant_id = $1
+       AND status IN ('PENDING', 'RUNNING')
+`
+
+// sqlParkJobOverBudget moves a RUNNING job to OVER_BUDGET. The attempt
+// the claim consumed is handed back: the job did not fail, it never ran.
+const sqlParkJobOverBudget = `
+    UPDATE jobs
+       SET status     = 'OVER_BUDGET',
+           attempts   = GREATEST(attempts - 1, 0),
+           error      = $2,
+           updated_at = now()
+     WHERE id = $1
+       AND status = 'RUNNING'
+`
+
+// sqlSelectTenantInFlightCents sums the estimates of the tenant's RUNNING
+// jobs that were claimed before job $2 (all of them when $2 is ''), so
+// parallel workers see each other's reservations. Ordering by
+// (started_at, id) makes two jobs claimed together agree on which one
+// goes first instead of both parking.
+const sqlSelectTenantInFlightCents = `
+    SELECT COALESCE(SUM(COALESCE((o.payload->>'estimatedCostCents')::int, 0)), 0)::int
+      FROM jobs o
+      LEFT JOIN jobs me ON me.id::text = $2
+     WHERE o.tenant_id = $1
+       AND o.status = 'RUNNING'
+       AND o.id::text <> $2
+       AND (me.id IS NULL OR (o.started_at, o.id) < (me.started_at, me.id))
+`
+
+// sqlSelectParkedJobs lists OVER_BUDGET jobs with their estimates, in the
+// order they would be claimed.
+const sqlSelectParkedJobs = `
+    SELECT id, tenant_id, COALESCE((payload->>'estimatedCostCents')::int, 0)
+      FROM jobs
+     WHERE status = 'OVER_BUDGET'
+     ORDER BY tenant_id, priority DESC, created_at
+`
+
+// sqlReleaseParkedJob re-queues one OVER_BUDGET job whose estimate fits
+// the tenant's remaining budget.
+const sqlReleaseParkedJob = `
+    UPDATE jobs
+       SET status     = 'PENDING',
+           error      = NULL,
+           updated_at = now()
+     WHERE id = $1
+       AND status = 'OVER_BUDGET'
+`
+
+// sqlUsageReport aggregates token_usage per (month, tenant, course, model)
+// over [$1, $2). $3 tenant and $4 course are optional ('' = all); $5 lists
+// the operations whose total_tokens are embedding tokens
+// (usageEmbeddingOperations). Minutes are the seconds billed by the
+// speech-to-text backend (metadata.billedSecs), falling back to the source
+// length (metadata.duration) on rows written before billedSecs existed.
+const sqlUsageReport = `
+    SELECT to_char(date_trunc('month', created_at), 'YYYY-MM') AS month,
+           tenant_id,
+           COALESCE(course_id, '') AS course_id,
+           model,
+           COUNT(*)::int AS entries,
+           COALESCE(SUM(COALESCE((metadata->>'billedSecs')::float8,
+                                 (metadata->>'duration')::float8))
+                    FILTER (WHERE operation = 'transcribe+embed'), 0) / 60.0 AS minutes,
+           COALESCE(SUM(total_tokens)
+                    FILTER (WHERE operation = ANY($5::text[])), 0)::bigint AS embedding_tokens,
+           COALESCE(SUM(total_tokens)
+                    FILTER (WHERE NOT (operation = ANY($5::text[]))), 0)::bigint AS other_tokens,
+           COALESCE(SUM(total_cost_cents), 0)::bigint AS cost_cents
+      FROM token_usage
+     WHERE created_at >= $1 AND created_at < $2
+       AND ($3 = '' OR tenant_id = $3)
+       AND ($4 = '' OR course_id = $4)
+     GROUP BY 1, 2, 3, 4
+     ORDER BY 1, 2, 3, 4
+`
+
+// sqlJobCountsReport counts jobs per (month created, tenant, course, type,
+// status) with the same filters as sqlUsageReport. Jobs have no course
+// column; it lives in the payload.
+const sqlJobCountsReport = `
+    SELECT to_char(date_trunc('month', created_at), 'YYYY-MM') AS month,
+           tenant_id,
+           COALESCE(payload->>'courseId', '') AS course_id,
+           type::text,
+           status::text,
+           COUNT(*)::int
+      FROM jobs
+     WHERE created_at >= $1 AND created_at < $2
+       AND ($3 = '' OR tenant_id = $3)
+       AND ($4 = '' OR payload->>'courseId' = $4)
+     GROUP BY 1, 2, 3, 4, 5
+     ORDER BY 1, 2, 3, 4, 5
+`
+
+// sqlUpdateJobProgress stores the pipeline's live progress on a RUNNING
+// job (see progress.go). Rows that left RUNNING (e.g. cancelled) keep
+// whatever they had.
+const sqlUpdateJobProgress = `
+    UPDATE jobs
+       SET progress   = $2::jsonb,
+           updated_at = now()
+     WHERE id = $1
+       AND status = 'RUNNING'
+`
+
+// sqlSelectJobProgress is what the per-job SSE stream polls.
+const sqlSelectJobProgress = `
+    SELECT id, payload->>'lessonId', status::text, attempts, error, progress
+      FROM jobs
+     WHERE id = $1
+`
+
+// sqlSelectDBNow is the clock jobs.updated_at is written with; the
+// per-tenant SSE stream takes its cursor from it, not from the app host.
+const sqlSelectDBNow = `SELECT now()`
+
+// sqlSelectTenantJobsProgress is what the per-tenant SSE stream polls:
+// every job still waiting or running, plus the ones touched since $2 (the
+// moment the stream opened, read with sqlSelectDBNow) so finishing jobs
+// report their final state.
+const sqlSelectTenantJobsProgress = `
+    SELECT id, payload->>'lessonId', status::text, attempts, error, progress
+      FROM jobs
+     WHERE tenant_id = $1
+       AND (status IN ('PENDING', 'RUNNING', 'OVER_BUDGET') OR updated_at >= $2)
+     ORDER BY created_at, id
+`
+
+// sqlSelectAutoEnqueueTenants lists the tenants the auto-enqueue job
+// visits: every aiEnabled tenant. Bunny credentials are optional (a tenant
+// may only have direct-URL lessons); detectSource decides per lesson.
+const sqlSelectAutoEnqueueTenants = `
+    SELECT id, COALESCE("bunnyLibraryApiKey", '')
+      FROM "Tenant"
+     WHERE "aiEnabled" = true
+     ORDER BY id
+`
+
+// sqlSelectAutoEnqueueLessons lists every published video lesson of a
+// tenant (sqlTranscribableMediaURL), transcribed or not: a transcribed
+// lesson whose mediaUrl changed needs a new run too. Same hierarchy walk
+// and order as sqlSelectUnprocessedLessons.
+const sqlSelectAutoEnqueueLessons = `
+    SELECT l.id,
+           l.name,
+           l."mediaUrl",
+           c.id AS course_id,
+           COALESCE(l."transcriptionCompleted", false) AS transcription_completed
+      FROM "Lesson" l
+      JOIN "Module"  m ON l."moduleId"  = m.id
+      JOIN "Section" s ON m."sectionId" = s.id
+      JOIN "Course"  c ON s."courseId"  = c.id
+      JOIN "Vitrine" v ON c."vitrineId" = v.id
+     WHERE v."tenantId" = $1
+       AND l.published  = true
+       AND ` + sqlTranscribableMediaURL + `
+     ORDER BY COALESCE(v."order", 0) ASC,
+              COALESCE(c."order", 0) ASC,
+              COALESCE(s."order", 0) ASC,
+              COALESCE(m."order", 0) ASC,
+              COALESCE(l."order", 0) ASC
+`
+
+// sqlSelectAutoEnqueueCandidates filters the lessons of sqlSelectAuto-
+// EnqueueLessons (passed as parallel arrays: $2 ids, $3 mediaUrls,
+// $4 transcriptionCompleted) down to the ones that need a job:
+//   - no videos row for the current URL (the UNIQUE (tenant_id,
+//     source_url) key), and
+//   - no non-cancelled job already created for this lesson + URL (a FAILED
+//     one is for an operator to retry, not for the cron to repeat), and
+//   - untranscribed, or transcribed from a different URL (a lesson flagged
+//     by hand with no videos row is left alone).
+//
+// WITH ORDINALITY keeps the course order of the input.
+const sqlSelectAutoEnqueueCandidates = `
+    SELECT l.lesson_id
+      FROM unnest($2::text[], $3::text[], $4::bool[]) WITH ORDINALITY
+           AS l(lesson_id, media_url, completed, ord)
+     WHERE NOT EXISTS (
+              SELECT 1 FROM videos v
+               WHERE v.tenant_id = $1 AND v.source_url = l.media_url)
+       AND NOT EXISTS (
+              SELECT 1 FROM jobs j
+               WHERE j.tenant_id = $1
+                 AND j.type = 'VIDEO_PROCESSING'
+                 AND j.status <> 'CANCELLED'
+                 AND j.payload->>'lessonId' = l.lesson_id
+                 AND j.payload->>'videoUrl' = l.media_url)
+       AND (NOT l.completed OR EXISTS (
+              SELECT 1 FROM videos v
+               WHERE v.tenant_id = $1 AND v.lesson_id = l.lesson_id))
+     ORDER BY l.ord
+`
+
+// sqlCountTenantQueuedJobs is the auto-enqueue throttle: VIDEO_PROCESSING
+// jobs of the tenant still waiting or running.
+const sqlCountTenantQueuedJobs = `
+    SELECT COUNT(*)
+      FROM jobs
+     WHERE tenant_id = $1
+       AND type = 'VIDEO_PROCESSING'
+       AND status IN ('PENDING', 'RUNNING')
+`
+
+// sqlInsertAutoJob is sqlInsertJob guarded against the cron running on
+// two replicas at once, or racing an admin click: nothing is inserted
+// (no row comes back) when the URL already has a video or an active job.
+//
+// $1 id, $2 tenant_id, $3 priority, $4 payload, $5 max_attempts,
+// $6 lesson id, $7 mediaUrl.
+const sqlInsertAutoJob = `
+    INSERT INTO jobs (id, tenant_id, type, status, priority, payload, max_attempts, created_at, updated_at)
+    SELECT $1, $2, 'VIDEO_PROCESSING', 'PENDING', $3, $4::jsonb, $5, now(), now()
+     WHERE NOT EXISTS (
+        SELECT 1 FROM videos WHERE tenant_id = $2 AND source_url = $7::text
+     )
+       AND NOT EXISTS (
+        SELECT 1 FROM jobs
+         WHERE tenant_id = $2
+           AND type   = 'VIDEO_PROCESSING'
+           AND status IN ('PENDING', 'RUNNING', 'OVER_BUDGET')
+           AND payload->>'lessonId' = $6::text
+           AND payload->>'videoUrl' = $7::text
+     )
+    RETURNING id
+`
+
+// sqlDeleteSupersededChunks drops the chunks a lesson still has under an
+// older video (its mediaUrl changed) once the new video's chunks are in,
+// so search stops returning the replaced recording. The old videos and
+// transcripts rows stay as history.
+const sqlDeleteSupersededChunks = `
+    DELETE FROM chunks
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+       AND video_id <> $3
+`
+
+// sqlSelectJobCheckpoints loads what earlier attempts of a job already
+// paid for (see checkpoint.go).
+const sqlSelectJobCheckpoints = `
+    SELECT kind, idx, data
+      FROM job_checkpoints
+     WHERE job_id = $1
+`
+
+// sqlUpsertJobCheckpoint stores one part/batch checkpoint. $1 job_id,
+// $2 kind, $3 idx, $4 data.
+const sqlUpsertJobCheckpoint = `
+    INSERT INTO job_checkpoints (job_id, kind, idx, data, created_at)
+    VALUES ($1, $2, $3, $4::jsonb, now())
+    ON CONFLICT (job_id, kind, idx) DO UPDATE SET
+        data       = EXCLUDED.data,
+        created_at = now()
+`
+
+const sqlDeleteJobCheckpoints = `DELETE FROM job_checkpoints WHERE job_id = $1`
+
+// sqlPruneJobCheckpoints drops checkpoints older than $1 seconds.
+const sqlPruneJobCheckpoints = `
+    DELETE FROM job_checkpoints
+     WHERE created_at < now() - ($1 * interval '1 second')
+`
+
+// sqlSelectCachedEmbeddings reads cached vectors as pgvector text. $1
+// model, $2 dims, $3 text_sha256[] (see embedcache.go).
+const sqlSelectCachedEmbeddings = `
+    SELECT text_sha256, embedding::text
+      FROM embedding_cache
+     WHERE model = $1
+       AND dims = $2
+       AND text_sha256 = ANY($3::text[])
+`
+
+// sqlInsertCachedEmbeddings caches one embeddings batch. $3 and $4 are
+// parallel arrays of text hashes and pgvector literals. A concurrent job
+// that cached the same text first wins; the vectors are identical.
+const sqlInsertCachedEmbeddings = `
+    INSERT INTO embedding_cache (model, dims, text_sha256, embedding, created_at)
+    SELECT $1, $2, u.hash, u.vec::vector, now()
+      FROM unnest($3::text[], $4::text[]) AS u(hash, vec)
+    ON CONFLICT (model, dims, text_sha256) DO NOTHING
+`
+
+// sqlInsertSummaryJob enqueues one LESSON_SUMMARY job for a video unless
+// one is already PENDING or RUNNING for it. No row comes back when the
+// job was deduplicated.
+//
+// $1 id, $2 tenant_id, $3 priority, $4 payload, $5 max_attempts, $6 video id.
+const sqlInsertSummaryJob = `
+    INSERT INTO jobs (id, tenant_id, type, status, priority, payload, max_attempts, created_at, updated_at)
+    SELECT $1, $2, 'LESSON_SUMMARY', 'PENDING', $3, $4::jsonb, $5, now(), now()
+     WHERE NOT EXISTS (
+        SELECT 1 FROM jobs
+         WHERE type   = 'LESSON_SUMMARY'
+           AND status IN ('PENDING', 'RUNNING')
+           AND payload->>'videoId' = $6::text
+     )
+    RETURNING id
+`
+
+// sqlSelectTranscriptForSummary loads the newest transcript of a video
+// for a LESSON_SUMMARY job, scoped to the tenant like the re-embed query.
+const sqlSelectTranscriptForSummary = `
+    SELECT t.id, t.segments, COALESCE(t.language, ''),
+           COALESCE(v.lesson_id, ''), COALESCE(v.course_id, ''),
+           COALESCE(v.title, ''), COALESCE(v.duration, 0)
+      FROM transcripts t
+      JOIN videos v ON v.id = t.video_id
+     WHERE t.video_id  = $1
+       AND v.tenant_id = $2
+     ORDER BY t.created_at DESC
+     LIMIT 1
+`
+
+// sqlUpsertLessonSummary stores one video's summary; a re-run replaces it.
+//
+// $1 video_id, $2 tenant_id, $3 lesson_id, $4 transcript_id, $5 summary,
+// $6 chapters, $7 key_points, $8 language, $9 model.
+const sqlUpsertLessonSummary = `
+    INSERT INTO lesson_summaries (
+        video_id, tenant_id, lesson_id, transcript_id, summary,
+        chapters, key_points, language, model, created_at, updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6::jsonb, $7::jsonb, $8, $9, now(), now()
+    )
+    ON CONFLICT (video_id) DO UPDATE SET
+        transcript_id = EXCLUDED.transcript_id,
+        summary       = EXCLUDED.summary,
+        chapters      = EXCLUDED.chapters,
+        key_points    = EXCLUDED.key_points,
+        language      = EXCLUDED.language,
+        model         = EXCLUDED.model,
+        updated_at    = now()
+`
+
+// sqlDeleteSupersededSummaries drops the summaries a lesson still has
+// under an older video, mirroring sqlDeleteSupersededChunks. "Older" is
+// by newest transcript, like sqlSelectLessonCurrentVideo: a summary whose
+// video has a transcript newer than $3's is kept, so a late job for a
+// replaced recording can never delete the current recording's summary.
+const sqlDeleteSupersededSummaries = `
+    DELETE FROM lesson_summaries s
+     WHERE s.tenant_id = $1
+       AND s.lesson_id = $2
+       AND s.video_id <> $3
+       AND NOT EXISTS (
+           SELECT 1 FROM transcripts t
+            WHERE t.video_id = s.video_id
+              AND t.created_at > (SELECT MAX(created_at) FROM transcripts WHERE video_id = $3)
+       )
+`
+
+// sqlSelectLessonSummary returns the newest summary of a lesson for the
+// vitrine lesson endpoint.
+const sqlSelectLessonSummary = `
+    SELECT summary, chapters, key_points, COALESCE(language, ''), model, updated_at
+      FROM lesson_summaries
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+     ORDER BY updated_at DESC
+     LIMIT 1
+`
+
+// sqlSelectLessonChunksForQuiz returns a lesson's transcript chunks in
+// playback order as quiz question sources.
+//
+// $1 tenant_id, $2 lesson_id.
+const sqlSelectLessonChunksForQuiz = `
+    SELECT id, COALESCE(video_id, ''), text, COALESCE(start_time, 0), COALESCE(end_time, 0)
+      FROM chunks
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+     ORDER BY page_number NULLS FIRST, start_time, "order"
+`
+
+// sqlInsertQuizDraft stores one generated quiz draft for admin review.
+//
+// $1 id, $2 tenant_id, $3 lesson_id, $4 video_id, $5 status, $6 model,
+// $7 questions.
+const sqlInsertQuizDraft = `
+    INSERT INTO quiz_drafts (
+        id, tenant_id, lesson_id, video_id, status, model, questions,
+        created_at, updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7::jsonb, now(), now()
+    )
+    RETURNING created_at, updated_at
+`
+
+// sqlSelectQuizDrafts lists a lesson's drafts, newest first. An empty $3
+// means any status.
+//
+// $1 tenant_id, $2 lesson_id, $3 status.
+const sqlSelectQuizDrafts = `
+    SELECT id, tenant_id, lesson_id, video_id, status, model, questions,
+           created_at, updated_at, reviewed_at
+      FROM quiz_drafts
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+       AND ($3 = '' OR status = $3)
+     ORDER BY created_at DESC
+`
+
+// sqlReviewQuizDraft records the admin's decision on a draft. Scoped by
+// tenant so one tenant cannot review another's drafts.
+//
+// $1 id, $2 tenant_id, $3 status.
+const sqlReviewQuizDraft = `
+    UPDATE quiz_drafts
+       SET status      = $3,
+           reviewed_at = CASE WHEN $3 = 'DRAFT' THEN NULL ELSE now() END,
+           updated_at  = now()
+     WHERE id = $1
+       AND tenant_id = $2
+    RETURNING id
+`
+
+// sqlSelectLessonTranscriptForEdit loads the newest transcript of a lesson
+// with the keys its chunks carry. updated_at is the optimistic lock the
+// correction endpoint writes back against.
+//
+// $1 tenant_id, $2 lesson_id.
+const sqlSelectLessonTranscriptForEdit = `
+    SELECT t.id, t.video_id, t.segments, COALESCE(t.language, ''), t.updated_at,
+           COALESCE(v.lesson_id, ''), COALESCE(v.course_id, '')
+      FROM transcripts t
+      JOIN videos v ON v.id = t.video_id
+     WHERE v.tenant_id = $1
+       AND t.lesson_id = $2
+     ORDER BY t.created_at DESC
+     LIMIT 1
+`
+
+// sqlSelectVideoChunkSpans lists a video's chunks in order, without text
+// or vectors: the correction endpoint only needs their time ranges.
+const sqlSelectVideoChunkSpans = `
+    SELECT id, "order", start_time, end_time
+      FROM chunks
+     WHERE video_id = $1
+     ORDER BY "order"
+`
+
+// sqlUpdateTranscriptSegments writes corrected segments and text. Zero
+// rows means the transcript changed (another edit, a reprocess) since it
+// was read.
+//
+// $1 id, $2 updated_at as read, $3 segments, $4 text.
+const sqlUpdateTranscriptSegments = `
+    UPDATE transcripts
+       SET segments   = $3::jsonb,
+           text       = $4,
+           updated_at = now()
+     WHERE id = $1
+       AND updated_at = $2
+`
+
+// sqlDeleteChunksByIDs drops the chunks a correction replaces.
+const sqlDeleteChunksByIDs = `
+    DELETE FROM chunks
+     WHERE video_id = $1
+       AND id = ANY($2)
+`
+
+// sqlRenumberVideoChunks rewrites chunks."order" by time after a
+// correction replaced some chunks with a different number of new ones.
+const sqlRenumberVideoChunks = `
+    UPDATE chunks c
+       SET "order" = n.rn
+      FROM (
+            SELECT id, (row_number() OVER (ORDER BY start_time, end_time, "order") - 1)::int AS rn
+              FROM chunks
+             WHERE video_id = $1
+           ) n
+     WHERE c.id = n.id
+       AND c."order" <> n.rn
+`
+
+// sqlInsertTranscriptEdit records one corrected segment.
+//
+// $1 id, $2 tenant_id, $3 transcript_id, $4 video_id, $5 lesson_id,
+// $6 segment_index, $7 start_time, $8 end_time, $9 old_text,
+// $10 new_text, $11 edited_by.
+const sqlInsertTranscriptEdit = `
+    INSERT INTO transcript_edits (
+        id, tenant_id, transcript_id, video_id, lesson_id, segment_index,
+        start_time, end_time, old_text, new_text, edited_by, created_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now()
+    )
+`
+
+// sqlSelectTranscriptEdits returns a lesson's correction history, newest
+// first.
+//
+// $1 tenant_id, $2 lesson_id, $3 limit.
+const sqlSelectTranscriptEdits = `
+    SELECT id, transcript_id, video_id, segment_index, start_time, end_time,
+           old_text, new_text, COALESCE(edited_by, ''), created_at
+      FROM transcript_edits
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+     ORDER BY created_at DESC, segment_index
+     LIMIT $3
+`
+
+// sqlSelectTranscriptGlossary reads a tenant's find/replace rules.
+const sqlSelectTranscriptGlossary = `
+    SELECT rules, updated_at
+      FROM transcript_glossaries
+     WHERE tenant_id = $1
+`
+
+// sqlUpsertTranscriptGlossary replaces a tenant's rules.
+//
+// $1 tenant_id, $2 rules.
+const sqlUpsertTranscriptGlossary = `
+    INSERT INTO transcript_glossaries (tenant_id, rules, created_at, updated_at)
+    VALUES ($1, $2::jsonb, now(), now())
+    ON CONFLICT (tenant_id) DO UPDATE SET
+        rules      = EXCLUDED.rules,
+        updated_at = now()
+    RETURNING updated_at
+`
+
+// sqlSelectLessonPdf resolves the tenant, course and current media URL
+// of a lesson for PDF indexing.
+const sqlSelectLessonPdf = `
+    SELECT v."tenantId", c.id, COALESCE(l."mediaUrl", '')
+      FROM "Lesson" l
+      JOIN "Module"  m ON l."moduleId"  = m.id
+      JOIN "Section" s ON m."sectionId" = s.id
+      JOIN "Course"  c ON s."courseId"  = c.id
+      JOIN "Vitrine" v ON c."vitrineId" = v.id
+     WHERE l.id = $1
+`
+
+// sqlDeletePdfPages drops the stored pages of a lesson before a fresh
+// extraction is written.
+const sqlDeletePdfPages = `DELETE FROM pdf_pages WHERE tenant_id = $1 AND lesson_id = $2`
+
+// sqlInsertPdfPage stores one page's extracted text.
+//
+// $1 tenant_id, $2 lesson_id, $3 course_id, $4 page_number, $5 text,
+// $6 source_url.
+const sqlInsertPdfPage = `
+    INSERT INTO pdf_pages (
+        tenant_id, lesson_id, course_id, page_number, text, source_url,
+        created_at, updated_at
+    ) VALUES (
+        $1, $2, $3, $4, $5, $6, now(), now()
+    )
+`
+
+// sqlInsertPdfEmbeddingJob enqueues one PDF_EMBEDDING job for a lesson
+// unless one is still pending for it. A running one may have downloaded
+// the file before it was replaced, so it does not count.
+//
+// $1 id, $2 tenant_id, $3 priority, $4 payload, $5 max_attempts,
+// $6 lesson_id.
+const sqlInsertPdfEmbeddingJob = `
+    INSERT INTO jobs (id, tenant_id, type, status, priority, payload, max_attempts, created_at, updated_at)
+    SELECT $1, $2, 'PDF_EMBEDDING', 'PENDING', $3, $4::jsonb, $5, now(), now()
+     WHERE NOT EXISTS (
+        SELECT 1 FROM jobs
+         WHERE type   = 'PDF_EMBEDDING'
+           AND status = 'PENDING'
+           AND payload->>'lessonId' = $6::text
+     )
+    RETURNING id
+`
+
+// sqlDeletePdfChunks drops a lesson's PDF chunks; its video chunks, if
+// any, stay.
+const sqlDeletePdfChunks = `
+    DELETE FROM chunks
+     WHERE tenant_id   = $1
+       AND lesson_id   = $2
+       AND source_type = 'PDF'
+`
+
+// sqlSelectUserTenantRole returns the user's role on the tenant; no row
+// means the user is not a member.
+const sqlSelectUserTenantRole = `
+    SELECT role
+      FROM "UsersOnTenants"
+     WHERE "userId"   = $1
+       AND "tenantId" = $2
+     LIMIT 1
+`
+
+// sqlSelectUserDeliveryLessons lists the lessons released to the user by
+// their deliveries, joined with LessonOnDelivery. A user reaches a
+// delivery through UserOnDelivery (as in GetUserDeliveryIDs) or, for
+// imported members, MemberOnDelivery (as in the user listing and the
+// notification audiences).
+//
+// $1 user id, $2 tenant id.
+const sqlSelectUserDeliveryLessons = `
+    SELECT lod."lessonId"
+      FROM "UserOnDelivery" uod
+      JOIN "LessonOnDelivery" lod ON lod."deliveryId" = uod."deliveryId"
+     WHERE uod."userId" = $1
+    UNION
+    SELECT lod."lessonId"
+      FROM "MemberOnDelivery" mod
+      JOIN "LessonOnDelivery" lod ON lod."deliveryId" = mod."deliveryId"
+     WHERE mod."memberId" = $1
+       AND mod."tenantId" = $2
+`
+
+// sqlUpsertLessonEmbedding recomputes a lesson's mean chunk embedding
+// over the chunks with the current dimensions: while a re-embed to other
+// dimensions is under way a lesson can hold both widths, and AVG fails
+// on mixed ones. No row is written when the lesson has no such chunks
+// left; the caller then runs sqlDeleteLessonEmbedding, and the re-embed
+// of the lesson's remaining chunks writes it back.
+//
+// $1 tenant_id, $2 lesson_id, $3 embedding dimensions.
+const sqlUpsertLessonEmbedding = `
+    INSERT INTO lesson_embeddings (tenant_id, lesson_id, course_id, embedding, chunks, updated_at)
+    SELECT tenant_id, lesson_id, MAX(course_id), AVG(embedding), COUNT(*), now()
+      FROM chunks
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+       AND embedding IS NOT NULL
+       AND vector_dims(embedding) = $3
+     GROUP BY tenant_id, lesson_id
+    ON CONFLICT (tenant_id, lesson_id) DO UPDATE
+       SET course_id  = EXCLUDED.course_id,
+           embedding  = EXCLUDED.embedding,
+           chunks     = EXCLUDED.chunks,
+           updated_at = now()
+`
+
+// sqlDeleteLessonEmbedding drops the embedding of a lesson without chunks.
+const sqlDeleteLessonEmbedding = `
+    DELETE FROM lesson_embeddings
+     WHERE tenant_id = $1
+       AND lesson_id = $2
+`
+
+// sqlSelectRelatedLessons ranks the tenant's other lessons by cosine
+// distance to the lesson's embedding. Rows embedded with other
+// dimensions (mid re-embed) are skipped rather than failing the query.
+//
+// $1 tenant_id, $2 lesson_id, $3 excluded lesson ids, $4 limit.
+const sqlSelectRelatedLessons = `
+    SELECT o.lesson_id, COALESCE(o.course_id, ''),
+           1 - (o.embedding <=> s.embedding) AS similarity
+      FROM lesson_embeddings s
+      JOIN lesson_embeddings o
+        ON o.tenant_id  = s.tenant_id
+       AND o.lesson_id <> s.lesson_id
+       AND vector_dims(o.embedding) = vector_dims(s.embedding)
+     WHERE s.tenant_id = $1
+       AND s.lesson_id = $2
+       AND NOT (o.lesson_id = ANY($3::text[]))
+     ORDER BY o.embedding <=> s.embedding
+     LIMIT $4
+`
+
+// sqlSelectLessonEmbeddingExists tells an unembedded lesson apart from
+// one with no neighbours.
+const sqlSelectLessonEmbeddingExists = `
+    SELECT EXISTS (
+        SELECT 1 FROM lesson_embeddings
+         WHERE tenant_id = $1
+           AND lesson_id = $2
+    )
+`
+
+// sqlSelectUserCompletedLessons lists the lessons the user marked as read.
+const sqlSelectUserCompletedLessons = `
+    SELECT DISTINCT r."lessonId"
+      FROM "Read" r
+     WHERE r."userId" = $1
+       AND r.read = true
+       AND r."lessonId" IS NOT NULL
+`
@@ -0,0 +1,142 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/memberclass-backend-golang/internal/domain/constants"
+)
+
+// Student-facing search: the same ranking as Search, called with the
+// tenant API key instead of the internal one, on behalf of one user. Hits
+// are limited to the lessons the user's deliveries release, so a student
+// searching "inside the videos" never sees content they have not bought.
+
+// roleOwner is the UsersOnTenants role that sees every lesson, as in the
+// social comment access rules.
+const roleOwner = "owner"
+
+// ---------- DTOs ----------
+
+// studentSearchRequest is the body of POST /api/v1/ai/student/search. The
+// tenant comes from mc-api-key; everything else matches searchRequest.
+type studentSearchRequest struct {
+	UserID        string      `json:"userId"`
+	Query         string      `json:"query"`
+	Scope         searchScope `json:"scope"`
+	Limit         int         `json:"limit"`
+	Mode          string      `json:"mode,omitempty"`
+	Language      string      `json:"language,omitempty"`
+	BoostLanguage string      `json:"boostLanguage,omitempty"`
+}
+
+// errUserNotInTenant is returned when userId has no UsersOnTenants row for
+// the API key's tenant.
+var errUserNotInTenant = errors.New("user not in tenant")
+
+// ---------- 1. HTTP handler ----------
+
+// StudentSearch handles `POST /api/v1/ai/student/search` (mc-api-key).
+//
+// Body: { userId, query, scope?, limit?, mode?, language?, boostLanguage? }.
+// Same response as Search. Tenant owners search the whole tenant.
+func (f *Feature) StudentSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	tenant := constants.GetTenantFromContext(r.Context())
+	if tenant == nil {
+		writeCustomError(w, http.StatusUnauthorized, "API key invalid", "INVALID_API_KEY")
+		return
+	}
+	if !tenant.AIEnabled {
+		writeCustomError(w, http.StatusForbidden, "IA não está habilitada para este tenant", "AI_NOT_ENABLED")
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	limitBody(w, r)
+	var body studentSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if body.UserID == "" {
+		writeCustomError(w, http.StatusBadRequest, "userId é obrigatório", "MISSING_USER_ID")
+		return
+	}
+	req := searchRequest{
+		TenantID:      tenant.ID,
+		Query:         body.Query,
+		Scope:         body.Scope,
+		Limit:         body.Limit,
+		Mode:          body.Mode,
+		Language:      body.Language,
+		BoostLanguage: body.BoostLanguage,
+	}
+	if msg, code := normalizeSearchRequest(&req); code != "" {
+		writeCustomError(w, http.StatusBadRequest, msg, code)
+		return
+	}
+
+	allowed, err := f.userAllowedLessonIDs(r.Context(), tenant.ID, body.UserID)
+	if errors.Is(err, errUserNotInTenant) {
+		writeCustomError(w, http.StatusNotFound, "usuário não encontrado", "USER_NOT_FOUND")
+		return
+	}
+	if err != nil {
+		f.log.Error("transcription.student_search.access_failed", "tenant", tenant.ID, "userId", body.UserID, "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+	req.allowedLessonIDs = allowed
+
+	resp, status, err := f.searchChunks(r.Context(), req)
+	if err != nil {
+		writeError(w, status, http.StatusText(status), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ---------- 2. Business rule ----------
+
+// userAllowedLessonIDs returns the lessons the user may search: nil for a
+// tenant owner (no restriction), otherwise the lessons of their
+// deliveries — an empty, non-nil slice when they have none.
+func (f *Feature) userAllowedLessonIDs(ctx context.Context, tenantID, userID string) ([]string, error) {
+	var role sql.NullString
+	err := f.memberclassDB.QueryRowContext(ctx, sqlSelectUserTenantRole, userID, tenantID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errUserNotInTenant
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select tenant role: %w", err)
+	}
+	if role.String == roleOwner {
+		return nil, nil
+	}
+
+	rows, err := f.memberclassDB.QueryContext(ctx, sqlSelectUserDeliveryLessons, userID, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("select delivery lessons: %w", err)
+	}
+	defer rows.Close()
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan delivery lesson: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
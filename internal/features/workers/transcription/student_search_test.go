@@ -0,0 +1,123 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+	"github.com/memberclass-backend-golang/internal/domain/constants"
+	"github.com/memberclass-backend-golang/internal/domain/entities/tenant"
+)
+
+// newStudentSearchRouter mounts the shared fixture with a stand-in for
+// AuthExternalMiddleware that puts tn in the context.
+func newStudentSearchRouter(t *testing.T, tn *tenant.Tenant) (*testFixture, chi.Router) {
+	t.Helper()
+	fx := newTestFixture(t)
+	r := fx.router(MiddlewareSet{AuthExternal: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), constants.TenantContextKey, tn)))
+		})
+	}})
+	return fx, r
+}
+
+func postStudentSearch(r chi.Router, req studentSearchRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/student/search", bytes.NewReader(body)))
+	return w
+}
+
+func expectTenantRole(mcMock sqlmock.Sqlmock, role string) {
+	mcMock.ExpectQuery(`FROM "UsersOnTenants"`).WithArgs("u1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(role))
+}
+
+func TestStudentSearch_OnlyDeliveredLessons(t *testing.T) {
+	fx, r := newStudentSearchRouter(t, &tenant.Tenant{ID: "t1", AIEnabled: true})
+	txMock, mcMock := fx.txMock, fx.mcMock
+
+	expectTenantRole(mcMock, "member")
+	// Deliveries count whether the user holds them directly or as an
+	// imported member of the tenant.
+	mcMock.ExpectQuery(`FROM "UserOnDelivery".*UNION.*FROM "MemberOnDelivery".*mod."tenantId" = \$2`).WithArgs("u1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"lessonId"}).AddRow("l1").AddRow("l2"))
+	// The course scope is kept and the delivery list is ANDed with it.
+	txMock.ExpectQuery(`AND course_id = \$3 AND lesson_id = ANY\(\$4\)`).
+		WithArgs(sqlmock.AnyArg(), "t1", "c1", pq.Array([]string{"l1", "l2"}), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}).
+			AddRow("chunk-1", "l2", "c1", "v2", "o checkout", 5.0, 9.0, "VIDEO", nil, 0.8))
+
+	w := postStudentSearch(r, studentSearchRequest{UserID: "u1", Query: "checkout", Scope: searchScope{CourseID: "c1"}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var got searchResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &got)
+	if got.TenantID != "t1" || got.Count != 1 || got.Hits[0].LessonID != "l2" {
+		t.Fatalf("response = %+v", got)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStudentSearch_NoDeliveriesSkipsEmbedding(t *testing.T) {
+	fx, r := newStudentSearchRouter(t, &tenant.Tenant{ID: "t1", AIEnabled: true})
+	mcMock := fx.mcMock
+
+	expectTenantRole(mcMock, "member")
+	mcMock.ExpectQuery(`JOIN "LessonOnDelivery"`).WithArgs("u1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"lessonId"}))
+
+	w := postStudentSearch(r, studentSearchRequest{UserID: "u1", Query: "checkout"})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"hits":[]`) {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if fx.embedCalls != 0 {
+		t.Fatalf("embedding called %d times", fx.embedCalls)
+	}
+}
+
+func TestStudentSearch_OwnerSearchesWholeTenant(t *testing.T) {
+	fx, r := newStudentSearchRouter(t, &tenant.Tenant{ID: "t1", AIEnabled: true})
+	txMock, mcMock := fx.txMock, fx.mcMock
+
+	expectTenantRole(mcMock, "owner")
+	txMock.ExpectQuery(`WHERE tenant_id = \$2 AND embedding IS NOT NULL\s+ORDER BY`).
+		WithArgs(sqlmock.AnyArg(), "t1", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "lesson_id", "course_id", "video_id", "text", "start_time", "end_time", "source_type", "page_number", "similarity"}))
+
+	if w := postStudentSearch(r, studentSearchRequest{UserID: "u1", Query: "checkout"}); w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStudentSearch_Rejections(t *testing.T) {
+	fx, r := newStudentSearchRouter(t, &tenant.Tenant{ID: "t1", AIEnabled: true})
+	mcMock := fx.mcMock
+	mcMock.ExpectQuery(`FROM "UsersOnTenants"`).WithArgs("u1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"role"}))
+	if w := postStudentSearch(r, studentSearchRequest{UserID: "u1", Query: "x"}); w.Code != http.StatusNotFound {
+		t.Fatalf("unknown user: status = %d body = %s", w.Code, w.Body.String())
+	}
+	if w := postStudentSearch(r, studentSearchRequest{Query: "x"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing userId: status = %d", w.Code)
+	}
+
+	_, r = newStudentSearchRouter(t, &tenant.Tenant{ID: "t1"})
+	if w := postStudentSearch(r, studentSearchRequest{UserID: "u1", Query: "x"}); w.Code != http.StatusForbidden {
+		t.Fatalf("AI disabled: status = %d", w.Code)
+	}
+}
@@ -0,0 +1,488 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/memberclass-backend-golang/internal/domain/dto/response/vitrine"
+)
+
+// Lesson summaries: an LLM-written summary, a chapter list with start
+// times and bullet key points, generated from a stored transcript by a
+// LESSON_SUMMARY job and served with the vitrine lesson (GetLesson). The
+// job goes through the chatCompleter port, so the model (or a non-OpenAI
+// backend) can be swapped without touching the pipeline. With
+// TRANSCRIPTION_SUMMARIES_ENABLED the job is queued as soon as a
+// VIDEO_PROCESSING job completes; POST /tenants/summarize-lessons
+// back-fills lessons transcribed before that.
+
+// ---------- DTOs ----------
+
+// summaryJobPayload is jobs.payload for LESSON_SUMMARY. Like re-embed,
+// one job = one video, and the transcript is read at execution time.
+type summaryJobPayload struct {
+	TenantID string `json:"tenantId"`
+	VideoID  string `json:"videoId"`
+	LessonID string `json:"lessonId,omitempty"`
+	CourseID string `json:"courseId,omitempty"`
+}
+
+// summaryJobResult is jobs.result for LESSON_SUMMARY.
+type summaryJobResult struct {
+	VideoID      string `json:"videoId"`
+	TranscriptID string `json:"transcriptId"`
+	Chapters     int    `json:"chapters"`
+	KeyPoints    int    `json:"keyPoints"`
+	CostCents    int    `json:"costCents"`
+
+	// Superseded is set when the video was replaced by a newer recording
+	// of the lesson before the job ran; nothing was generated.
+	Superseded bool `json:"superseded,omitempty"`
+}
+
+const (
+	// summaryBlockSeconds merges Whisper segments into blocks of at least
+	// this length before they go into the prompt: one timestamp per block
+	// is enough to place chapters and costs far fewer tokens.
+	summaryBlockSeconds = 30
+
+	// summaryMaxInputTokens caps the transcript sent to the model (about
+	// 4h of speech). Longer transcripts are cut and the prompt says so.
+	summaryMaxInputTokens = 60000
+
+	// summaryOutputTokensEstimate prices the reply for the budget check.
+	summaryOutputTokensEstimate = 1500
+
+	summaryMaxChapters  = 20
+	summaryMaxKeyPoints = 10
+)
+
+// summarySystemPrompt asks for strict JSON so parseSummaryDraft does not
+// have to guess. Chapter starts are the transcript's own [m:ss] marks.
+const summarySystemPrompt = `Você recebe a transcrição de uma aula em vídeo, em blocos marcados com o tempo de início [m:ss] ou [h:mm:ss].
+Gere um resumo para os alunos e responda APENAS com um objeto JSON neste formato:
+{"summary": "...", "chapters": [{"title": "...", "start": "m:ss"}], "keyPoints": ["..."]}
+Regras:
+- Escreva no mesmo idioma da transcrição.
+- summary: um ou dois parágrafos curtos sobre o que a aula ensina.
+- chapters: de 3 a 12 capítulos em ordem, cada um começando em um tempo que aparece na transcrição; o primeiro começa em 0:00.
+- keyPoints: de 3 a 8 frases curtas com o que o aluno deve lembrar.
+- Use apenas o conteúdo da transcrição.`
+
+// ---------- 1. HTTP handler ----------
+
+// SummarizeLessons handles `POST /api/v1/ai/tenants/summarize-lessons`.
+//
+// Body: { tenantId, courseId?, lessonIds? } — same scope rules as
+// /tenants/reembed-lessons.
+//
+// Enqueues one LESSON_SUMMARY job per transcribed video in scope, to
+// (re)generate summaries without waiting for a new transcription.
+func (f *Feature) SummarizeLessons(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if err := f.preflight(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", err.Error())
+		return
+	}
+
+	limitBody(w, r)
+	var req reembedLessonsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeCustomError(w, http.StatusBadRequest, "JSON inválido", "INVALID_REQUEST")
+		return
+	}
+	if req.TenantID == "" {
+		writeCustomError(w, http.StatusBadRequest, "tenantId é obrigatório", "MISSING_TENANT_ID")
+		return
+	}
+	if len(req.LessonIDs) > maxLessonIDsPerRequest {
+		writeCustomError(w, http.StatusBadRequest,
+			fmt.Sprintf("lessonIds excede o limite de %d por chamada", maxLessonIDsPerRequest),
+			"TOO_MANY_LESSON_IDS")
+		return
+	}
+
+	resp, status, err := f.enqueueSummaries(r.Context(), req)
+	if err != nil {
+		writeError(w, status, http.StatusText(status), err.Error())
+		return
+	}
+	writeJSON(w, status, resp)
+}
+
+// ---------- 2. Business rule ----------
+
+// enqueueSummaries resolves the scope exactly like enqueueReembed and
+// inserts one LESSON_SUMMARY job per lesson, for its current video.
+func (f *Feature) enqueueSummaries(ctx context.Context, req reembedLessonsRequest) (*processLessonsResponse, int, error) {
+	return f.enqueueLessonJobs(ctx, req, lessonJobKind{
+		insert: func(ctx context.Context, t lessonJobTarget) (string, error) {
+			return f.insertSummaryJob(ctx, summaryJobPayload{
+				TenantID: t.TenantID, VideoID: t.VideoID, LessonID: t.LessonID, CourseID: t.CourseID,
+			})
+		},
+		logKey:    "transcription.summary.insert_failed",
+		duplicate: "resumo já enfileirado para este vídeo",
+		noneMsg:   "nenhuma transcrição elegível para resumo",
+		doneMsg:   "%d job(s) de resumo enfileirado(s)",
+	})
+}
+
+// insertSummaryJob queues one LESSON_SUMMARY job. sql.ErrNoRows means one
+// is already queued or running for the video.
+func (f *Feature) insertSummaryJob(ctx context.Context, p summaryJobPayload) (string, error) {
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return "", fmt.Errorf("marshal summary payload: %w", err)
+	}
+	var jobID string
+	err = f.transcriptionDB.QueryRowContext(ctx, sqlInsertSummaryJob,
+		uuid.NewString(), p.TenantID, 0, payload, 3, p.VideoID,
+	).Scan(&jobID)
+	return jobID, err
+}
+
+// queueSummaryAfterTranscription is the pipeline's follow-up hook. Best
+// effort: the transcript is already committed, so a failed insert is
+// logged and the summary can be back-filled through the endpoint.
+func (f *Feature) queueSummaryAfterTranscription(ctx context.Context, p summaryJobPayload) {
+	if !f.summariesEnabled {
+		return
+	}
+	jobID, err := f.insertSummaryJob(ctx, p)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		f.log.Error("transcription.summary.enqueue_failed",
+			"tenant", p.TenantID, "videoId", p.VideoID, "error", err.Error())
+		return
+	}
+	f.log.Info("transcription.summary.enqueued", "tenant", p.TenantID, "videoId", p.VideoID, "jobId", jobID)
+}
+
+// ---------- 3. Worker ----------
+
+// executeSummaryJob runs a LESSON_SUMMARY job: one chat completion over
+// the stored transcript, validated and upserted into lesson_summaries.
+func (f *Feature) executeSummaryJob(ctx context.Context, jobID, tenantID string, rawPayload []byte) error {
+	if err := f.preflight(); err != nil {
+		return err
+	}
+
+	var p summaryJobPayload
+	if err := json.Unmarshal(rawPayload, &p); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	if p.VideoID == "" {
+		return fmt.Errorf("payload missing videoId")
+	}
+
+	if err := f.requireJobTenantAI(ctx, tenantID); err != nil {
+		return err
+	}
+
+	var (
+		transcriptID, language    string
+		segmentsJSON              []byte
+		lessonID, courseID, title string
+		duration                  float64
+	)
+	if err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectTranscriptForSummary, p.VideoID, tenantID).
+		Scan(&transcriptID, &segmentsJSON, &language, &lessonID, &courseID, &title, &duration); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("video %s has no stored transcript", p.VideoID)
+		}
+		return fmt.Errorf("select transcript: %w", err)
+	}
+	superseded, err := f.isSupersededVideo(ctx, tenantID, lessonID, p.VideoID)
+	if err != nil {
+		return err
+	}
+	if superseded {
+		// Queued before a new recording of the lesson landed; that
+		// recording's own job summarizes the lesson.
+		f.log.Info("transcription.summary.superseded", "jobId", jobID, "videoId", p.VideoID, "lessonId", lessonID)
+		result, _ := json.Marshal(summaryJobResult{VideoID: p.VideoID, TranscriptID: transcriptID, Superseded: true})
+		return f.markJobCompleted(ctx, jobID, result)
+	}
+	var segments []whisperSegment
+	if err := json.Unmarshal(segmentsJSON, &segments); err != nil {
+		return fmt.Errorf("decode transcript segments: %w", err)
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("video %s transcript has no segments", p.VideoID)
+	}
+	if last := segments[len(segments)-1].End; duration <= 0 || last > duration {
+		duration = last
+	}
+
+	messages, truncated := buildSummaryMessages(title, language, segments)
+	if truncated {
+		f.log.Warn("transcription.summary.transcript_truncated", "jobId", jobID, "videoId", p.VideoID)
+	}
+	promptTokens := 0
+	for _, m := range messages {
+		promptTokens += countTokens(m.Content)
+	}
+	inEst, outEst := chatCostCents(f.chatClientFor(f.summaryChat).Model(), promptTokens, summaryOutputTokensEstimate)
+	if err := f.checkJobBudget(ctx, tenantID, jobID, inEst+outEst); err != nil {
+		return err
+	}
+
+	completion, err := f.chatClientFor(f.summaryChat).Complete(ctx, messages)
+	if err != nil {
+		return fmt.Errorf("chat completion: %w", err)
+	}
+	draft, err := parseSummaryDraft(completion.Content, duration)
+	if err != nil {
+		return err
+	}
+	chaptersJSON, _ := json.Marshal(draft.Chapters)
+	keyPointsJSON, _ := json.Marshal(draft.KeyPoints)
+	inCents, outCents := chatCostCents(completion.Model, completion.PromptTokens, completion.CompletionTokens)
+
+	tx, err := f.transcriptionDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, sqlUpsertLessonSummary,
+		p.VideoID, tenantID, nullableString(lessonID), transcriptID, draft.Summary,
+		chaptersJSON, keyPointsJSON, nullableString(language), completion.Model,
+	); err != nil {
+		return fmt.Errorf("upsert lesson summary: %w", err)
+	}
+	if lessonID != "" {
+		if _, err := tx.ExecContext(ctx, sqlDeleteSupersededSummaries, tenantID, lessonID, p.VideoID); err != nil {
+			return fmt.Errorf("delete superseded summaries: %w", err)
+		}
+	}
+	tokenMeta, _ := json.Marshal(map[string]any{
+		"jobId":     jobID,
+		"chapters":  len(draft.Chapters),
+		"keyPoints": len(draft.KeyPoints),
+		"truncated": truncated,
+	})
+	if _, err := tx.ExecContext(ctx, sqlInsertTokenUsage,
+		uuid.NewString(), tenantID, nullableString(courseID), p.VideoID, transcriptID,
+		completion.PromptTokens, completion.CompletionTokens, completion.PromptTokens+completion.CompletionTokens,
+		inCents, outCents, inCents+outCents,
+		completion.Model, "summarize", tokenMeta,
+	); err != nil {
+		return fmt.Errorf("insert token_usage: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit summary tx: %w", err)
+	}
+
+	result, _ := json.Marshal(summaryJobResult{
+		VideoID:      p.VideoID,
+		TranscriptID: transcriptID,
+		Chapters:     len(draft.Chapters),
+		KeyPoints:    len(draft.KeyPoints),
+		CostCents:    inCents + outCents,
+	})
+	if err := f.markJobCompleted(ctx, jobID, result); err != nil {
+		if !errors.Is(err, errJobCancelled) {
+			f.log.Error("transcription.summary.mark_job_failed",
+				"error", err.Error(), "jobId", jobID, "videoId", p.VideoID)
+		}
+		return err
+	}
+	return nil
+}
+
+// buildSummaryMessages renders the transcript as [m:ss] blocks of at
+// least summaryBlockSeconds. Reports whether the transcript had to be cut
+// at summaryMaxInputTokens.
+func buildSummaryMessages(title, language string, segments []whisperSegment) ([]chatMessage, bool) {
+	var (
+		b         strings.Builder
+		block     strings.Builder
+		start     float64
+		tokens    int
+		truncated bool
+	)
+	if title != "" {
+		fmt.Fprintf(&b, "Título da aula: %s\n", title)
+	}
+	if language != "" {
+		fmt.Fprintf(&b, "Idioma da transcrição: %s\n", language)
+	}
+	b.WriteString("Transcrição:\n")
+	flush := func() bool {
+		text := strings.TrimSpace(block.String())
+		block.Reset()
+		if text == "" {
+			return true
+		}
+		line := fmt.Sprintf("[%s] %s\n", formatTimestamp(start), text)
+		n := countTokens(line)
+		if tokens+n > summaryMaxInputTokens {
+			return false
+		}
+		tokens += n
+		b.WriteString(line)
+		return true
+	}
+	for i, s := range segments {
+		if block.Len() == 0 {
+			start = s.Start
+		}
+		block.WriteString(strings.TrimSpace(s.Text))
+		block.WriteString(" ")
+		if s.End-start < summaryBlockSeconds && i < len(segments)-1 {
+			continue
+		}
+		if !flush() {
+			truncated = true
+			break
+		}
+	}
+	if truncated {
+		b.WriteString("[transcrição cortada por tamanho; resuma apenas o trecho acima]\n")
+	}
+	return []chatMessage{
+		{Role: "system", Content: summarySystemPrompt},
+		{Role: "user", Content: b.String()},
+	}, truncated
+}
+
+// summaryDraft is the validated model output.
+type summaryDraft struct {
+	Summary   string
+	Chapters  []vitrine.LessonChapter
+	KeyPoints []string
+}
+
+// parseSummaryDraft decodes the model's JSON and cleans it up: chapters
+// need a title and a start inside the video, are sorted and de-duplicated
+// by start; empty key points are dropped. Only a missing summary fails
+// the job.
+func parseSummaryDraft(content string, duration float64) (*summaryDraft, error) {
+	var raw struct {
+		Summary  string `json:"summary"`
+		Chapters []struct {
+			Title string          `json:"title"`
+			Start json.RawMessage `json:"start"`
+		} `json:"chapters"`
+		KeyPoints []string `json:"keyPoints"`
+	}
+	if err := decodeModelJSON(content, &raw); err != nil {
+		return nil, fmt.Errorf("decode summary json: %w", err)
+	}
+	out := &summaryDraft{
+		Summary:   strings.TrimSpace(raw.Summary),
+		Chapters:  []vitrine.LessonChapter{},
+		KeyPoints: []string{},
+	}
+	if out.Summary == "" {
+		return nil, fmt.Errorf("summary json has an empty summary")
+	}
+
+	seen := make(map[int]bool)
+	for _, c := range raw.Chapters {
+		title := strings.TrimSpace(c.Title)
+		start, ok := parseChapterStart(c.Start)
+		if title == "" || !ok || start < 0 || (duration > 0 && start >= duration) || seen[int(start)] {
+			continue
+		}
+		seen[int(start)] = true
+		out.Chapters = append(out.Chapters, vitrine.LessonChapter{Title: title, StartTime: start})
+	}
+	sort.SliceStable(out.Chapters, func(i, j int) bool { return out.Chapters[i].StartTime < out.Chapters[j].StartTime })
+	if len(out.Chapters) > summaryMaxChapters {
+		out.Chapters = out.Chapters[:summaryMaxChapters]
+	}
+
+	for _, k := range raw.KeyPoints {
+		if k = strings.TrimSpace(k); k != "" {
+			out.KeyPoints = append(out.KeyPoints, k)
+		}
+	}
+	if len(out.KeyPoints) > summaryMaxKeyPoints {
+		out.KeyPoints = out.KeyPoints[:summaryMaxKeyPoints]
+	}
+	return out, nil
+}
+
+// parseChapterStart accepts "m:ss", "h:mm:ss" or a number of seconds
+// (as a JSON number or string).
+func parseChapterStart(raw json.RawMessage) (float64, bool) {
+	var n *float64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		if n == nil {
+			return 0, false
+		}
+		return *n, true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, false
+	}
+	s = strings.Trim(strings.TrimSpace(s), "[]")
+	if x, err := strconv.ParseFloat(s, 64); err == nil {
+		return x, true
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	total := 0
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 || (i > 0 && v >= 60) {
+			return 0, false
+		}
+		total = total*60 + v
+	}
+	return float64(total), true
+}
+
+// ---------- 4. Vitrine read port ----------
+
+// GetLessonAISummary implements vitrineports.LessonAISummaryReader for
+// the vitrine GetLesson endpoint. (nil, nil) means no summary yet, or the
+// transcription DB is not configured.
+func (f *Feature) GetLessonAISummary(ctx context.Context, lessonID, tenantID string) (*vitrine.LessonAISummary, error) {
+	if f.transcriptionDB == nil {
+		return nil, nil
+	}
+	var (
+		out                     vitrine.LessonAISummary
+		chaptersJSON, keyPoints []byte
+	)
+	err := f.transcriptionDB.QueryRowContext(ctx, sqlSelectLessonSummary, tenantID, lessonID).
+		Scan(&out.Summary, &chaptersJSON, &keyPoints, &out.Language, &out.Model, &out.GeneratedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		f.log.Warn("transcription.summary.read_failed", "tenant", tenantID, "lessonId", lessonID, "error", err.Error())
+		return nil, fmt.Errorf("select lesson summary: %w", err)
+	}
+	if err := json.Unmarshal(chaptersJSON, &out.Chapters); err != nil {
+		return nil, fmt.Errorf("decode chapters: %w", err)
+	}
+	if err := json.Unmarshal(keyPoints, &out.KeyPoints); err != nil {
+		return nil, fmt.Errorf("decode key points: %w", err)
+	}
+	return &out, nil
+}
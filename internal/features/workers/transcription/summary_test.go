@@ -0,0 +1,208 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func TestParseSummaryDraft_CleansModelOutput(t *testing.T) {
+	content := "```json\n" + `{
+		"summary": "  A aula explica funis de venda. ",
+		"chapters": [
+			{"title": "Fechamento", "start": "12:30"},
+			{"title": "Introdução", "start": "0:00"},
+			{"title": "", "start": "1:00"},
+			{"title": "Depois do fim", "start": "1:00:00"},
+			{"title": "Duplicado", "start": 750},
+			{"title": "Meio", "start": "[5:05]"}
+		],
+		"keyPoints": ["Topo do funil", "  ", "Fundo do funil"]
+	}` + "\n```"
+
+	got, err := parseSummaryDraft(content, 1200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary != "A aula explica funis de venda." {
+		t.Fatalf("summary = %q", got.Summary)
+	}
+	// Empty title, past-the-end and duplicate starts are dropped; the rest
+	// come back in time order.
+	want := []struct {
+		title string
+		start float64
+	}{{"Introdução", 0}, {"Meio", 305}, {"Fechamento", 750}}
+	if len(got.Chapters) != len(want) {
+		t.Fatalf("chapters = %+v", got.Chapters)
+	}
+	for i, w := range want {
+		if got.Chapters[i].Title != w.title || got.Chapters[i].StartTime != w.start {
+			t.Fatalf("chapter %d = %+v, want %+v", i, got.Chapters[i], w)
+		}
+	}
+	if len(got.KeyPoints) != 2 {
+		t.Fatalf("keyPoints = %q", got.KeyPoints)
+	}
+}
+
+func TestParseSummaryDraft_RejectsMissingSummary(t *testing.T) {
+	for _, content := range []string{`not json`, `{"summary": " ", "chapters": []}`} {
+		if _, err := parseSummaryDraft(content, 0); err == nil {
+			t.Errorf("%q: expected error", content)
+		}
+	}
+}
+
+func TestParseChapterStart(t *testing.T) {
+	cases := map[string]float64{`"0:00"`: 0, `"2:05"`: 125, `"1:02:03"`: 3723, `90`: 90, `"42.5"`: 42.5}
+	for in, want := range cases {
+		if got, ok := parseChapterStart(json.RawMessage(in)); !ok || got != want {
+			t.Errorf("parseChapterStart(%s) = %v, %v; want %v", in, got, ok, want)
+		}
+	}
+	for _, in := range []string{`"1:75"`, `"abc"`, `null`, `"1:2:3:4"`} {
+		if _, ok := parseChapterStart(json.RawMessage(in)); ok {
+			t.Errorf("parseChapterStart(%s) should fail", in)
+		}
+	}
+}
+
+func TestBuildSummaryMessages_GroupsSegmentsIntoTimestampedBlocks(t *testing.T) {
+	segments := []whisperSegment{
+		{Start: 0, End: 10, Text: " Olá"},
+		{Start: 10, End: 31, Text: " turma."},
+		{Start: 31, End: 40, Text: " Hoje"},
+		{Start: 40, End: 65, Text: " vendas."},
+	}
+	messages, truncated := buildSummaryMessages("Aula 1", "pt", segments)
+	if truncated || len(messages) != 2 || messages[0].Role != "system" {
+		t.Fatalf("messages = %+v truncated=%v", messages, truncated)
+	}
+	user := messages[1].Content
+	for _, want := range []string{"Título da aula: Aula 1", "[0:00] Olá turma.", "[0:31] Hoje vendas."} {
+		if !strings.Contains(user, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, user)
+		}
+	}
+}
+
+func TestExecuteSummaryJob_StoresSummaryAndUsage(t *testing.T) {
+	chat := &fakeChat{reply: `{"summary": "Resumo", "chapters": [{"title": "Início", "start": "0:00"}], "keyPoints": ["Um", "Dois"]}`}
+	fx := newTestFixture(t)
+	f, txMock := fx.f, fx.txMock
+	f.summaryChat = chat
+
+	fx.expectAITenant(true)
+	segments, _ := json.Marshal([]whisperSegment{{Start: 0, End: 40, Text: "Bem-vindos à aula."}})
+	txMock.ExpectQuery(`FROM transcripts t`).WithArgs("v1", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "segments", "language", "lesson_id", "course_id", "title", "duration"}).
+			AddRow("tr1", segments, "pt", "l1", "c1", "Aula 1", 40.0))
+	expectCurrentVideo(txMock, "t1", "l1", "v1")
+	txMock.ExpectBegin()
+	txMock.ExpectExec(`INSERT INTO lesson_summaries`).
+		WithArgs("v1", "t1", "l1", "tr1", "Resumo", sqlmock.AnyArg(), sqlmock.AnyArg(), "pt", "fake-chat").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectExec(`DELETE FROM lesson_summaries s.*NOT EXISTS.*t.created_at >`).WithArgs("t1", "l1", "v1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	txMock.ExpectExec(`INSERT INTO token_usage`).
+		WithArgs(sqlmock.AnyArg(), "t1", "c1", "v1", "tr1", 1000, 100, 1100,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "fake-chat", "summarize", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	txMock.ExpectCommit()
+	txMock.ExpectExec(`UPDATE jobs`).WithArgs("job-s", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(summaryJobPayload{TenantID: "t1", VideoID: "v1", LessonID: "l1"})
+	if err := f.executeSummaryJob(context.Background(), "job-s", "t1", payload); err != nil {
+		t.Fatal(err)
+	}
+	if chat.calls != 1 || !strings.Contains(chat.messages[1].Content, "[0:00] Bem-vindos à aula.") {
+		t.Fatalf("chat calls = %d messages = %+v", chat.calls, chat.messages)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecuteSummaryJob_SkipsSupersededVideo(t *testing.T) {
+	chat := &fakeChat{reply: `{"summary": "Resumo antigo"}`}
+	fx := newTestFixture(t)
+	f, txMock := fx.f, fx.txMock
+	f.summaryChat = chat
+
+	fx.expectAITenant(true)
+	segments, _ := json.Marshal([]whisperSegment{{Start: 0, End: 40, Text: "Gravação antiga."}})
+	txMock.ExpectQuery(`FROM transcripts t`).WithArgs("v-old", "t1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "segments", "language", "lesson_id", "course_id", "title", "duration"}).
+			AddRow("tr-old", segments, "pt", "l1", "c1", "Aula 1", 40.0))
+	expectCurrentVideo(txMock, "t1", "l1", "v-new")
+	// No summary upsert or delete: the current recording's summary stays.
+	txMock.ExpectExec(`UPDATE jobs`).WithArgs("job-s", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	payload, _ := json.Marshal(summaryJobPayload{TenantID: "t1", VideoID: "v-old", LessonID: "l1"})
+	if err := f.executeSummaryJob(context.Background(), "job-s", "t1", payload); err != nil {
+		t.Fatal(err)
+	}
+	if chat.calls != 0 {
+		t.Fatalf("chat called %d times for a superseded video", chat.calls)
+	}
+	if err := txMock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueueSummaryAfterTranscription_OnlyWhenEnabled(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, log: logger.NewLogger()}
+	p := summaryJobPayload{TenantID: "t1", VideoID: "v1", LessonID: "l1"}
+
+	// Disabled: no query at all (sqlmock would fail the expectations below
+	// on an unexpected one).
+	f.queueSummaryAfterTranscription(context.Background(), p)
+
+	f.summariesEnabled = true
+	mock.ExpectQuery(`INSERT INTO jobs.*LESSON_SUMMARY.*NOT EXISTS`).
+		WithArgs(sqlmock.AnyArg(), "t1", 0, sqlmock.AnyArg(), 3, "v1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("j1"))
+	f.queueSummaryAfterTranscription(context.Background(), p)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetLessonAISummary(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, log: logger.NewLogger()}
+	generated := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM lesson_summaries`).WithArgs("t1", "l1").
+		WillReturnRows(sqlmock.NewRows([]string{"summary", "chapters", "key_points", "language", "model", "updated_at"}).
+			AddRow("Resumo", []byte(`[{"title":"Início","startTime":0}]`), []byte(`["Um"]`), "pt", "gpt-4o-mini", generated))
+	got, err := f.GetLessonAISummary(context.Background(), "l1", "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Summary != "Resumo" || len(got.Chapters) != 1 || got.KeyPoints[0] != "Um" || !got.GeneratedAt.Equal(generated) {
+		t.Fatalf("summary = %+v", got)
+	}
+
+	mock.ExpectQuery(`FROM lesson_summaries`).WithArgs("t1", "l2").
+		WillReturnRows(sqlmock.NewRows([]string{"summary", "chapters", "key_points", "language", "model", "updated_at"}))
+	if got, err := f.GetLessonAISummary(context.Background(), "l2", "t1"); err != nil || got != nil {
+		t.Fatalf("missing summary = %+v, %v", got, err)
+	}
+
+	// Without the transcription DB the vitrine simply gets no summary.
+	if got, err := (&Feature{}).GetLessonAISummary(context.Background(), "l1", "t1"); err != nil || got != nil {
+		t.Fatalf("no DB = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,63 @@
+package transcription
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// aiTenant is what the enqueue paths and job runners read from a Tenant
+// row. The pipeline also needs the Bunny library id and scans
+// sqlSelectTenantBunnyCreds itself.
+type aiTenant struct {
+	AIEnabled bool
+	// BunnyAPIKey feeds the duration lookups of the batch budget
+	// estimate; "" when unset.
+	BunnyAPIKey string
+}
+
+// loadTenant reads the tenant; a NULL aiEnabled counts as disabled.
+// sql.ErrNoRows means there is no such tenant.
+func (f *Feature) loadTenant(ctx context.Context, tenantID string) (aiTenant, error) {
+	var (
+		tID, tName              string
+		aiEnabled               sql.NullBool
+		bunnyLibID, bunnyAPIKey sql.NullString
+	)
+	if err := f.memberclassDB.QueryRowContext(ctx, sqlSelectTenantBunnyCreds, tenantID).
+		Scan(&tID, &tName, &aiEnabled, &bunnyLibID, &bunnyAPIKey); err != nil {
+		return aiTenant{}, err
+	}
+	return aiTenant{AIEnabled: aiEnabled.Valid && aiEnabled.Bool, BunnyAPIKey: bunnyAPIKey.String}, nil
+}
+
+// requireAITenant guards the admin endpoints: 404 when the tenant cannot
+// be read, 403 when it has AI disabled.
+func (f *Feature) requireAITenant(ctx context.Context, tenantID string) (aiTenant, int, error) {
+	t, err := f.loadTenant(ctx, tenantID)
+	if err != nil {
+		return aiTenant{}, http.StatusNotFound, fmt.Errorf("tenant não encontrado")
+	}
+	if !t.AIEnabled {
+		return aiTenant{}, http.StatusForbidden, fmt.Errorf("IA não está habilitada para este tenant")
+	}
+	return t, http.StatusOK, nil
+}
+
+// requireJobTenantAI guards the job runners: AI may have been turned off
+// (or the tenant removed) after the job was queued.
+func (f *Feature) requireJobTenantAI(ctx context.Context, tenantID string) error {
+	t, err := f.loadTenant(ctx, tenantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("tenant %s not found", tenantID)
+	}
+	if err != nil {
+		return fmt.Errorf("select tenant: %w", err)
+	}
+	if !t.AIEnabled {
+		return fmt.Errorf("tenant %s has aiEnabled=false", tenantID)
+	}
+	return nil
+}
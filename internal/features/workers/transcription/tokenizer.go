@@ -0,0 +1,415 @@
+package transcription
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer for the chunker and cost estimates: a byte-level BPE that
+// reproduces OpenAI's tiktoken encodings offline. The rank tables are the
+// upstream `.tiktoken` files (one "base64(token) rank" pair per line),
+// gzipped and embedded from bpe/ — see bpe/README.md. The files are
+// committed and TestCountTokens_* fails without them; approxTokens is only
+// a guard against a rank file that fails to parse.
+
+const (
+	// encodingCL100K is used by text-embedding-3-* and the gpt-4 /
+	// gpt-3.5 families.
+	encodingCL100K = "cl100k_base"
+	// encodingO200K is used by gpt-4o, gpt-4.1 and the o-series models.
+	encodingO200K = "o200k_base"
+)
+
+//go:embed bpe
+var bpeFiles embed.FS
+
+// bpeEncoding is one loaded tiktoken encoding.
+type bpeEncoding struct {
+	name  string
+	ranks map[string]int
+	split func(string) []string
+}
+
+// bpeSplitters are the pre-tokenizers of each supported encoding; BPE
+// merges never cross a piece boundary.
+var bpeSplitters = map[string]func(string) []string{
+	encodingCL100K: func(s string) []string { return splitPieces(s, cl100kPiece) },
+	encodingO200K:  func(s string) []string { return splitPieces(s, o200kPiece) },
+}
+
+// encodingForModel maps an OpenAI model id to its tiktoken encoding.
+func encodingForModel(model string) string {
+	for _, p := range []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"} {
+		if strings.HasPrefix(model, p) {
+			return encodingO200K
+		}
+	}
+	return encodingCL100K
+}
+
+var (
+	bpeMu    sync.Mutex
+	bpeCache = map[string]*bpeLoad{}
+)
+
+type bpeLoad struct {
+	enc *bpeEncoding
+	err error
+}
+
+// loadEncoding returns the embedded encoding `name`, parsing it on first
+// use. The result (including a missing-file error) is cached for the life
+// of the process.
+func loadEncoding(name string) (*bpeEncoding, error) {
+	bpeMu.Lock()
+	defer bpeMu.Unlock()
+	if l, ok := bpeCache[name]; ok {
+		return l.enc, l.err
+	}
+	enc, err := readEmbeddedEncoding(name)
+	bpeCache[name] = &bpeLoad{enc: enc, err: err}
+	return enc, err
+}
+
+func readEmbeddedEncoding(name string) (*bpeEncoding, error) {
+	if raw, err := bpeFiles.ReadFile("bpe/" + name + ".tiktoken.gz"); err == nil {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		defer zr.Close()
+		return parseTiktoken(name, zr)
+	}
+	raw, err := bpeFiles.ReadFile("bpe/" + name + ".tiktoken")
+	if err != nil {
+		return nil, fmt.Errorf("%s rank file not embedded: %w", name, fs.ErrNotExist)
+	}
+	return parseTiktoken(name, bytes.NewReader(raw))
+}
+
+// parseTiktoken reads a `.tiktoken` rank file.
+func parseTiktoken(name string, r io.Reader) (*bpeEncoding, error) {
+	split, ok := bpeSplitters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+	enc := &bpeEncoding{name: name, ranks: make(map[string]int, 1<<17), split: split}
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		tok, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s line %d: missing rank", name, line)
+		}
+		b, err := base64.StdEncoding.DecodeString(tok)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+		n, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+		enc.ranks[string(b)] = n
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if len(enc.ranks) == 0 {
+		return nil, fmt.Errorf("%s: empty rank file", name)
+	}
+	return enc, nil
+}
+
+// Encode returns the token ids of s. Special tokens are not recognised;
+// "<|endoftext|>" is encoded as ordinary text.
+func (e *bpeEncoding) Encode(s string) []int {
+	var out []int
+	for _, piece := range e.split(s) {
+		if r, ok := e.ranks[piece]; ok {
+			out = append(out, r)
+			continue
+		}
+		bounds := e.bytePairMerge(piece)
+		for i := 0; i+1 < len(bounds); i++ {
+			out = append(out, e.ranks[piece[bounds[i]:bounds[i+1]]])
+		}
+	}
+	return out
+}
+
+// Count returns len(Encode(s)) without building the id slice.
+func (e *bpeEncoding) Count(s string) int {
+	n := 0
+	for _, piece := range e.split(s) {
+		if _, ok := e.ranks[piece]; ok {
+			n++
+			continue
+		}
+		n += len(e.bytePairMerge(piece)) - 1
+	}
+	return n
+}
+
+// bytePairMerge runs tiktoken's merge loop over one piece: starting from
+// single bytes, repeatedly join the adjacent pair with the lowest rank
+// (leftmost on ties) until no pair is in the table. Returns the token
+// boundaries, including 0 and len(piece).
+func (e *bpeEncoding) bytePairMerge(piece string) []int {
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, at := math.MaxInt, -1
+		for i := 0; i+2 < len(bounds); i++ {
+			if r, ok := e.ranks[piece[bounds[i]:bounds[i+2]]]; ok && r < best {
+				best, at = r, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		bounds = append(bounds[:at+1], bounds[at+2:]...)
+	}
+	return bounds
+}
+
+// ---------- pre-tokenizers ----------
+//
+// Hand-written equivalents of the tiktoken split regexes, which need
+// look-ahead and so cannot run on Go's regexp:
+//
+//	cl100k: '(?i:[sdmt]|ll|ve|re) | [^\r\n\p{L}\p{N}]?\p{L}+ | \p{N}{1,3}
+//	        | ?[^\s\p{L}\p{N}]+[\r\n]* | \s*[\r\n]+ | \s+(?!\S) | \s+
+//	o200k:  [^\r\n\p{L}\p{N}]?[UPPER]*[LOWER]+(contraction)?
+//	        | [^\r\n\p{L}\p{N}]?[UPPER]+[LOWER]*(contraction)?
+//	        | \p{N}{1,3} | ?[^\s\p{L}\p{N}]+[\r\n/]* | \s*[\r\n]+ | \s+(?!\S) | \s+
+//
+// UPPER is [\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}], LOWER is [\p{Ll}\p{Lm}\p{Lo}\p{M}].
+// Each *Piece function returns the byte length of the match at the start
+// of s, trying the alternatives in regex order.
+
+// splitPieces cuts s into consecutive pieces using next.
+func splitPieces(s string, next func(string) int) []string {
+	var out []string
+	for len(s) > 0 {
+		n := next(s)
+		if n <= 0 {
+			_, n = utf8.DecodeRuneInString(s)
+		}
+		out = append(out, s[:n])
+		s = s[n:]
+	}
+	return out
+}
+
+func cl100kPiece(s string) int {
+	if n := contractionLen(s); n > 0 {
+		return n
+	}
+	if w := prefixLen(s); w > 0 {
+		if n := runLen(s[w:], unicode.IsLetter); n > 0 {
+			return w + n
+		}
+	}
+	if n := runLen(s, unicode.IsLetter); n > 0 {
+		return n
+	}
+	if n := numberLen(s); n > 0 {
+		return n
+	}
+	if n := punctLen(s, isNewline); n > 0 {
+		return n
+	}
+	return spaceLen(s)
+}
+
+func o200kPiece(s string) int {
+	skips := []int{0}
+	if w := prefixLen(s); w > 0 {
+		skips = []int{w, 0}
+	}
+	for _, skip := range skips {
+		if n := caseRunLen(s[skip:]); n > 0 {
+			end := skip + n
+			return end + contractionLen(s[end:])
+		}
+	}
+	for _, skip := range skips {
+		if n := runLen(s[skip:], isUpperish); n > 0 {
+			end := skip + n
+			end += runLen(s[end:], isLowerish)
+			return end + contractionLen(s[end:])
+		}
+	}
+	if n := numberLen(s); n > 0 {
+		return n
+	}
+	if n := punctLen(s, func(r rune) bool { return isNewline(r) || r == '/' }); n > 0 {
+		return n
+	}
+	return spaceLen(s)
+}
+
+// contractionLen matches 's 't 're 've 'm 'll 'd, case-insensitively.
+func contractionLen(s string) int {
+	if len(s) < 2 || s[0] != '\'' {
+		return 0
+	}
+	switch s[1] | 0x20 {
+	case 's', 't', 'm', 'd':
+		return 2
+	}
+	if len(s) >= 3 {
+		switch strings.ToLower(s[1:3]) {
+		case "re", "ve", "ll":
+			return 3
+		}
+	}
+	return 0
+}
+
+// prefixLen matches [^\r\n\p{L}\p{N}] — the one-rune lead-in (usually a
+// space) a word piece may carry.
+func prefixLen(s string) int {
+	r, w := utf8.DecodeRuneInString(s)
+	if isNewline(r) || unicode.IsLetter(r) || unicode.IsNumber(r) {
+		return 0
+	}
+	return w
+}
+
+// numberLen matches \p{N}{1,3}.
+func numberLen(s string) int {
+	n := 0
+	for i := 0; i < 3 && n < len(s); i++ {
+		r, w := utf8.DecodeRuneInString(s[n:])
+		if !unicode.IsNumber(r) {
+			break
+		}
+		n += w
+	}
+	return n
+}
+
+// punctLen matches ` ?[^\s\p{L}\p{N}]+` followed by any run of tail.
+func punctLen(s string, tail func(rune) bool) int {
+	start := 0
+	if s[0] == ' ' {
+		start = 1
+	}
+	n := runLen(s[start:], isPunct)
+	if n == 0 {
+		return 0
+	}
+	end := start + n
+	return end + runLen(s[end:], tail)
+}
+
+// spaceLen matches the three whitespace alternatives: up to the last
+// newline of the run; else the run minus its last rune, which is left to
+// lead the next word; else the whole run.
+func spaceLen(s string) int {
+	n := runLen(s, unicode.IsSpace)
+	if n == 0 {
+		return 0
+	}
+	if i := strings.LastIndexAny(s[:n], "\r\n"); i >= 0 {
+		return i + 1
+	}
+	if n == len(s) {
+		return n
+	}
+	if _, last := utf8.DecodeLastRuneInString(s[:n]); n > last {
+		return n - last
+	}
+	return n
+}
+
+// caseRunLen matches [UPPER]*[LOWER]+, backtracking the greedy UPPER run
+// since the two classes share Lm, Lo and M.
+func caseRunLen(s string) int {
+	upper := runLen(s, isUpperish)
+	for pos := upper; ; {
+		if n := runLen(s[pos:], isLowerish); n > 0 {
+			return pos + n
+		}
+		if pos == 0 {
+			return 0
+		}
+		_, w := utf8.DecodeLastRuneInString(s[:pos])
+		pos -= w
+	}
+}
+
+// runLen returns the byte length of the leading run of runes matching ok.
+func runLen(s string, ok func(rune) bool) int {
+	n := 0
+	for n < len(s) {
+		r, w := utf8.DecodeRuneInString(s[n:])
+		if !ok(r) {
+			break
+		}
+		n += w
+	}
+	return n
+}
+
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+func isPunct(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isUpperish(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isLowerish(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+// ---------- slice entry points ----------
+
+// embedEncoding is the encoding of embedModel, or an error when its rank
+// file is not embedded in this build.
+func embedEncoding() (*bpeEncoding, error) {
+	return loadEncoding(encodingForModel(embedModel))
+}
+
+// countTokens measures s in embedModel tokens — what the chunker sizes
+// chunks by and what embedding estimates are priced on. Falls back to
+// approxTokens when the encoding is unavailable.
+func countTokens(s string) int {
+	if enc, err := embedEncoding(); err == nil {
+		return enc.Count(s)
+	}
+	return approxTokens(s)
+}
+
+// countModelTokens measures s in the encoding of an OpenAI chat model, for
+// pricing prompts before they are sent. Falls back to countTokens when
+// that encoding is unavailable.
+func countModelTokens(model, s string) int {
+	if enc, err := loadEncoding(encodingForModel(model)); err == nil {
+		return enc.Count(s)
+	}
+	return countTokens(s)
+}
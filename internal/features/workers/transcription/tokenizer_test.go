@@ -0,0 +1,141 @@
+package transcription
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testEncoding builds a tiny cl100k-style encoding: every byte, then the
+// merges "ll" (256), "he" (257) and "llo" (258).
+func testEncoding(t *testing.T) *bpeEncoding {
+	t.Helper()
+	var b strings.Builder
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), i)
+	}
+	for i, tok := range []string{"ll", "he", "llo"} {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(tok)), 256+i)
+	}
+	enc, err := parseTiktoken(encodingCL100K, strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+func TestBPE_MergesLowestRankFirst(t *testing.T) {
+	enc := testEncoding(t)
+	// h e l l o → h e ll o → he ll o → he llo
+	if got, want := enc.Encode("hello"), []int{257, 258}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Encode(hello) = %v, want %v", got, want)
+	}
+	// " hello" is one piece; the leading space has no merge.
+	if got, want := enc.Encode(" hello"), []int{' ', 257, 258}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Encode( hello) = %v, want %v", got, want)
+	}
+	// Accented letters are two UTF-8 bytes each: "ação" is 6 byte tokens.
+	if got := enc.Count("ação"); got != 6 {
+		t.Fatalf("Count(ação) = %d, want 6", got)
+	}
+	if got := enc.Count("hello hello"); got != len(enc.Encode("hello hello")) {
+		t.Fatalf("Count and Encode disagree: %d", got)
+	}
+}
+
+func TestParseTiktoken_RejectsBadInput(t *testing.T) {
+	for name, in := range map[string]string{
+		"no rank":    "aGk=\n",
+		"bad base64": "!!! 1\n",
+		"bad rank":   "aGk= x\n",
+		"empty":      "\n",
+	} {
+		if _, err := parseTiktoken(encodingCL100K, strings.NewReader(in)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := parseTiktoken("p50k_base", strings.NewReader("aGk= 1\n")); err == nil {
+		t.Error("unknown encoding: expected error")
+	}
+}
+
+func TestSplit_CL100K(t *testing.T) {
+	got := bpeSplitters[encodingCL100K]("Hello world's 12345 !!\n\n  x")
+	want := []string{"Hello", " world", "'s", " ", "123", "45", " !!\n\n", " ", " x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("split = %q\nwant    %q", got, want)
+	}
+	got = bpeSplitters[encodingCL100K]("Então, a aula começa.\r\nFim  ")
+	want = []string{"Então", ",", " a", " aula", " começa", ".\r\n", "Fim", "  "}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("split = %q\nwant    %q", got, want)
+	}
+}
+
+func TestSplit_O200K(t *testing.T) {
+	got := bpeSplitters[encodingO200K]("CamelCase HTTPServer don't a/b\n")
+	want := []string{"Camel", "Case", " HTTPServer", " don't", " a", "/b", "\n"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("split = %q\nwant    %q", got, want)
+	}
+}
+
+func TestEncodingForModel(t *testing.T) {
+	cases := map[string]string{
+		"text-embedding-3-small": encodingCL100K,
+		"gpt-3.5-turbo":          encodingCL100K,
+		"gpt-4o-mini":            encodingO200K,
+		"o3-mini":                encodingO200K,
+	}
+	for model, want := range cases {
+		if got := encodingForModel(model); got != want {
+			t.Errorf("encodingForModel(%s) = %s, want %s", model, got, want)
+		}
+	}
+}
+
+func TestLoadEncoding_MissingFileIsNotExist(t *testing.T) {
+	if _, err := readEmbeddedEncoding("r50k_base"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+// TestCountTokens_CL100K checks known ids against the embedded ranks
+// (scripts/fetch-tiktoken-encodings.sh); a build missing them fails here.
+func TestCountTokens_CL100K(t *testing.T) {
+	enc, err := loadEncoding(encodingCL100K)
+	if err != nil {
+		t.Fatalf("cl100k_base not embedded: %v", err)
+	}
+	if got, want := enc.Encode("hello world"), []int{15339, 1917}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Encode(hello world) = %v, want %v", got, want)
+	}
+	if got, want := enc.Encode("tiktoken is great!"), []int{83, 1609, 5963, 374, 2294, 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Encode(tiktoken is great!) = %v, want %v", got, want)
+	}
+	if got := countTokens("hello world"); got != 2 {
+		t.Fatalf("countTokens = %d, want 2", got)
+	}
+}
+
+func TestCountTokens_O200K(t *testing.T) {
+	enc, err := loadEncoding(encodingO200K)
+	if err != nil {
+		t.Fatalf("o200k_base not embedded: %v", err)
+	}
+	if got, want := enc.Encode("hello world"), []int{24912, 2375}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Encode(hello world) = %v, want %v", got, want)
+	}
+	// "😀😀" is 2 tokens in o200k and 4 in cl100k, so the counts tell
+	// which encoding countModelTokens picked.
+	if got := countModelTokens("gpt-4o-mini", "😀😀"); got != 2 {
+		t.Fatalf("countModelTokens(gpt-4o-mini) = %d, want 2", got)
+	}
+	if got := countModelTokens("gpt-4-turbo", "😀😀"); got != 4 {
+		t.Fatalf("countModelTokens(gpt-4-turbo) = %d, want 4", got)
+	}
+}
@@ -0,0 +1,107 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/memberclass-backend-golang/internal/domain/ports"
+)
+
+// transcriber is the slice's speech-to-text port. executeJob only talks to
+// this interface; which backend serves a given job is decided per tenant by
+// transcriberFor. Adapters must return Whisper's verbose_json shape
+// (segments with start/end seconds) because the chunker relies on it.
+type transcriber interface {
+	// Name identifies the backend in logs and transcripts.metadata.
+	Name() string
+	// Model is recorded in transcripts.model and token_usage.model.
+	Model() string
+	// Transcribe sends one audio part. `language` is an ISO-639-1 code
+	// that sets the decoding language; "" lets the backend detect it,
+	// and whisperResponse.Language carries whatever it settled on.
+	Transcribe(ctx context.Context, audio io.Reader, filename, language string) (*whisperResponse, error)
+	// CostCents prices `durationSeconds` of audio on this backend so the
+	// token_usage row reflects what the job actually cost.
+	CostCents(durationSeconds float64) int
+}
+
+// Backend names accepted by TRANSCRIPTION_STT_PROVIDER and
+// TRANSCRIPTION_STT_TENANT_PROVIDERS.
+const (
+	transcriberOpenAI     = "openai"
+	transcriberSelfHosted = "selfhosted"
+)
+
+// sttConfig is the resolved speech-to-text routing: every configured
+// backend keyed by name, the default one, and per-tenant overrides.
+type sttConfig struct {
+	backends map[string]transcriber
+	fallback string
+	tenants  map[string]string
+}
+
+// loadSTTConfig reads the TRANSCRIPTION_STT_* env vars. Misconfiguration
+// never fails boot — it logs and falls back to OpenAI, matching how New
+// treats a missing OPENAI_API_KEY:
+//
+//   - TRANSCRIPTION_STT_PROVIDER         default backend ("openai" | "selfhosted")
+//   - TRANSCRIPTION_STT_TENANT_PROVIDERS comma list of tenantId=backend overrides
+//   - TRANSCRIPTION_STT_SELFHOSTED_*     see newSelfHostedTranscriberFromEnv
+func loadSTTConfig(log ports.Logger, openaiBaseURL, openaiAPIKey string, client *http.Client, openaiLimiter *rpmLimiter) sttConfig {
+	cfg := sttConfig{
+		backends: map[string]transcriber{
+			transcriberOpenAI: newOpenAITranscriber(openaiBaseURL, openaiAPIKey, client, openaiLimiter),
+		},
+		fallback: transcriberOpenAI,
+		tenants:  map[string]string{},
+	}
+	if sh := newSelfHostedTranscriberFromEnv(log, client); sh != nil {
+		cfg.backends[transcriberSelfHosted] = sh
+	}
+
+	if v := strings.TrimSpace(os.Getenv("TRANSCRIPTION_STT_PROVIDER")); v != "" {
+		if _, ok := cfg.backends[v]; ok {
+			cfg.fallback = v
+		} else {
+			log.Warn("transcription: TRANSCRIPTION_STT_PROVIDER names an unconfigured backend — using openai", "provider", v)
+		}
+	}
+
+	for _, pair := range strings.Split(os.Getenv("TRANSCRIPTION_STT_TENANT_PROVIDERS"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tenantID, name, ok := strings.Cut(pair, "=")
+		tenantID, name = strings.TrimSpace(tenantID), strings.TrimSpace(name)
+		if !ok || tenantID == "" {
+			log.Warn("transcription: ignoring malformed TRANSCRIPTION_STT_TENANT_PROVIDERS entry", "entry", pair)
+			continue
+		}
+		if _, ok := cfg.backends[name]; !ok {
+			log.Warn("transcription: tenant override names an unconfigured backend — ignoring", "tenant", tenantID, "provider", name)
+			continue
+		}
+		cfg.tenants[tenantID] = name
+	}
+	return cfg
+}
+
+// transcriberFor picks the backend for a job: the tenant override first,
+// then the configured default. A Feature built by hand (tests) without any
+// backends falls through to OpenAI using its own openai* fields, so the
+// pre-port wiring keeps working unchanged.
+func (f *Feature) transcriberFor(tenantID string) transcriber {
+	if name, ok := f.stt.tenants[tenantID]; ok {
+		if t, ok := f.stt.backends[name]; ok {
+			return t
+		}
+	}
+	if t, ok := f.stt.backends[f.stt.fallback]; ok {
+		return t
+	}
+	return newOpenAITranscriber(f.openaiBaseURL, f.openaiAPIKey, f.httpClient, f.openaiLimiter)
+}
@@ -0,0 +1,92 @@
+package transcription
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/memberclass-backend-golang/internal/domain/ports"
+)
+
+const (
+	// defaultSelfHostedPath is the OpenAI-compatible route exposed by
+	// faster-whisper-server / speaches. whisper.cpp's bundled server
+	// answers on /inference instead — set TRANSCRIPTION_STT_SELFHOSTED_PATH.
+	defaultSelfHostedPath = "/v1/audio/transcriptions"
+
+	// defaultSelfHostedModel is sent when no model is configured.
+	// whisper.cpp ignores the field; faster-whisper servers need a real
+	// model id (e.g. Systran/faster-whisper-large-v3).
+	defaultSelfHostedModel = "whisper-1"
+)
+
+// selfHostedTranscriber targets an OpenAI-compatible Whisper server we run
+// ourselves. Same multipart contract as OpenAI, but the model is
+// configurable, the API key is optional, and the per-minute cost is
+// whatever the operator says the box costs (default 0).
+type selfHostedTranscriber struct {
+	baseURL        string
+	path           string
+	apiKey         string
+	model          string
+	centsPerMinute float64
+	httpClient     *http.Client
+}
+
+// newSelfHostedTranscriberFromEnv builds the adapter from env. Returns nil
+// when TRANSCRIPTION_STT_SELFHOSTED_URL is unset — the backend simply isn't
+// offered in that case.
+//
+//   - TRANSCRIPTION_STT_SELFHOSTED_URL              base URL (required)
+//   - TRANSCRIPTION_STT_SELFHOSTED_PATH             route, default /v1/audio/transcriptions
+//   - TRANSCRIPTION_STT_SELFHOSTED_MODEL            model id sent in the form
+//   - TRANSCRIPTION_STT_SELFHOSTED_API_KEY          optional bearer token
+//   - TRANSCRIPTION_STT_SELFHOSTED_CENTS_PER_MINUTE cost reported to token_usage
+func newSelfHostedTranscriberFromEnv(log ports.Logger, client *http.Client) *selfHostedTranscriber {
+	base := strings.TrimRight(strings.TrimSpace(os.Getenv("TRANSCRIPTION_STT_SELFHOSTED_URL")), "/")
+	if base == "" {
+		return nil
+	}
+	t := &selfHostedTranscriber{
+		baseURL:    base,
+		path:       defaultSelfHostedPath,
+		apiKey:     os.Getenv("TRANSCRIPTION_STT_SELFHOSTED_API_KEY"),
+		model:      defaultSelfHostedModel,
+		httpClient: client,
+	}
+	if v := strings.TrimSpace(os.Getenv("TRANSCRIPTION_STT_SELFHOSTED_PATH")); v != "" {
+		t.path = "/" + strings.TrimLeft(v, "/")
+	}
+	if v := strings.TrimSpace(os.Getenv("TRANSCRIPTION_STT_SELFHOSTED_MODEL")); v != "" {
+		t.model = v
+	}
+	if v := os.Getenv("TRANSCRIPTION_STT_SELFHOSTED_CENTS_PER_MINUTE"); v != "" {
+		if c, err := strconv.ParseFloat(v, 64); err == nil && c >= 0 {
+			t.centsPerMinute = c
+		} else {
+			log.Warn("transcription: invalid TRANSCRIPTION_STT_SELFHOSTED_CENTS_PER_MINUTE — reporting 0", "value", v)
+		}
+	}
+	return t
+}
+
+func (t *selfHostedTranscriber) Name() string  { return transcriberSelfHosted }
+func (t *selfHostedTranscriber) Model() string { return t.model }
+
+// CostCents rounds up like whisperCostCents so a configured rate is never
+// under-reported.
+func (t *selfHostedTranscriber) CostCents(durationSeconds float64) int {
+	if durationSeconds <= 0 || t.centsPerMinute <= 0 {
+		return 0
+	}
+	return int(math.Ceil((durationSeconds / 60.0) * t.centsPerMinute))
+}
+
+func (t *selfHostedTranscriber) Transcribe(ctx context.Context, audio io.Reader, filename, language string) (*whisperResponse, error) {
+	return postTranscription(ctx, t.httpClient, "selfhosted whisper",
+		t.baseURL+t.path, t.apiKey, t.model, audio, filename, language)
+}
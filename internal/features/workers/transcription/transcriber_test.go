@@ -0,0 +1,112 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/memberclass-backend-golang/internal/infrastructure/adapters/logger"
+)
+
+func TestLoadSTTConfig_DefaultsToOpenAI(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_STT_PROVIDER", "")
+	t.Setenv("TRANSCRIPTION_STT_TENANT_PROVIDERS", "")
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_URL", "")
+
+	f := &Feature{stt: loadSTTConfig(logger.NewLogger(), defaultOpenAIBase, "k", http.DefaultClient, nil)}
+	if got := f.transcriberFor("any-tenant").Name(); got != transcriberOpenAI {
+		t.Fatalf("backend = %q, want openai", got)
+	}
+	if _, ok := f.stt.backends[transcriberSelfHosted]; ok {
+		t.Fatal("selfhosted registered without TRANSCRIPTION_STT_SELFHOSTED_URL")
+	}
+}
+
+func TestLoadSTTConfig_TenantOverrides(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_URL", "http://whisper.local:8000/")
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_MODEL", "Systran/faster-whisper-large-v3")
+	t.Setenv("TRANSCRIPTION_STT_PROVIDER", "openai")
+	t.Setenv("TRANSCRIPTION_STT_TENANT_PROVIDERS", "t-big=selfhosted, t-bad=nope,malformed")
+
+	f := &Feature{stt: loadSTTConfig(logger.NewLogger(), defaultOpenAIBase, "k", http.DefaultClient, nil)}
+
+	big := f.transcriberFor("t-big")
+	if big.Name() != transcriberSelfHosted || big.Model() != "Systran/faster-whisper-large-v3" {
+		t.Fatalf("t-big -> %s/%s, want selfhosted", big.Name(), big.Model())
+	}
+	if got := f.transcriberFor("t-bad").Name(); got != transcriberOpenAI {
+		t.Fatalf("unknown override should fall back to default, got %q", got)
+	}
+	if got := f.transcriberFor("t-other").Name(); got != transcriberOpenAI {
+		t.Fatalf("default = %q, want openai", got)
+	}
+}
+
+func TestLoadSTTConfig_UnconfiguredDefaultFallsBackToOpenAI(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_URL", "")
+	t.Setenv("TRANSCRIPTION_STT_PROVIDER", "selfhosted")
+	t.Setenv("TRANSCRIPTION_STT_TENANT_PROVIDERS", "")
+
+	f := &Feature{stt: loadSTTConfig(logger.NewLogger(), defaultOpenAIBase, "k", http.DefaultClient, nil)}
+	if got := f.transcriberFor("t").Name(); got != transcriberOpenAI {
+		t.Fatalf("backend = %q, want openai", got)
+	}
+}
+
+func TestSelfHostedTranscriber_SpeaksOpenAIContract(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/inference" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Fatalf("Authorization sent without a key: %q", got)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("parse multipart: %v", err)
+		}
+		if r.FormValue("model") != "large-v3" {
+			t.Fatalf("model=%q", r.FormValue("model"))
+		}
+		if r.FormValue("response_format") != "verbose_json" {
+			t.Fatalf("response_format=%q", r.FormValue("response_format"))
+		}
+		_ = json.NewEncoder(w).Encode(whisperResponse{
+			Text:     "olá",
+			Duration: 3,
+			Segments: []whisperSegment{{Start: 0, End: 3, Text: "olá"}},
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_URL", server.URL)
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_PATH", "inference")
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_MODEL", "large-v3")
+	t.Setenv("TRANSCRIPTION_STT_SELFHOSTED_API_KEY", "")
+	sh := newSelfHostedTranscriberFromEnv(logger.NewLogger(), server.Client())
+	if sh == nil {
+		t.Fatal("adapter not built")
+	}
+
+	resp, err := sh.Transcribe(context.Background(), strings.NewReader("FAKE-MP3"), "a.mp3", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "olá" || len(resp.Segments) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestSelfHostedTranscriber_CostCents(t *testing.T) {
+	free := &selfHostedTranscriber{}
+	if got := free.CostCents(3600); got != 0 {
+		t.Fatalf("free backend cost = %d, want 0", got)
+	}
+	// 0.1¢/min * 15 min = 1.5¢ → ceil to 2¢
+	paid := &selfHostedTranscriber{centsPerMinute: 0.1}
+	if got := paid.CostCents(900); got != 2 {
+		t.Fatalf("paid backend cost = %d, want 2", got)
+	}
+}
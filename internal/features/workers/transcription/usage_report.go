@@ -0,0 +1,274 @@
+package transcription
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ---------- DTOs ----------
+
+// usageRow aggregates token_usage for one (month, tenant, course, model).
+// MinutesTranscribed is the audio billed by the speech-to-text backend
+// (metadata.billedSecs on transcription rows), so it lines up with CostCents;
+// EmbeddingTokens from total_tokens on usageEmbeddingOperations rows;
+// OtherTokens is everything else (chat completions for /answer).
+type usageRow struct {
+	Month              string  `json:"month"`
+	TenantID           string  `json:"tenantId"`
+	CourseID           string  `json:"courseId,omitempty"`
+	Model              string  `json:"model"`
+	Entries            int     `json:"entries"`
+	MinutesTranscribed float64 `json:"minutesTranscribed"`
+	EmbeddingTokens    int64   `json:"embeddingTokens"`
+	OtherTokens        int64   `json:"otherTokens"`
+	CostCents          int64   `json:"costCents"`
+}
+
+// jobCountRow counts jobs for one (month, tenant, course, type, status).
+type jobCountRow struct {
+	Month    string `json:"month"`
+	TenantID string `json:"tenantId"`
+	CourseID string `json:"courseId,omitempty"`
+	Type     string `json:"type"`
+	Status   string `json:"status"`
+	Count    int    `json:"count"`
+}
+
+type usageTotals struct {
+	MinutesTranscribed float64 `json:"minutesTranscribed"`
+	EmbeddingTokens    int64   `json:"embeddingTokens"`
+	OtherTokens        int64   `json:"otherTokens"`
+	CostCents          int64   `json:"costCents"`
+}
+
+type usageReportResponse struct {
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	TenantID string        `json:"tenantId,omitempty"`
+	CourseID string        `json:"courseId,omitempty"`
+	Usage    []usageRow    `json:"usage"`
+	Jobs     []jobCountRow `json:"jobs"`
+	Totals   usageTotals   `json:"totals"`
+}
+
+const (
+	usageMonthLayout = "2006-01"
+
+	// usageMaxMonths bounds one report so a typo in `from` can't scan the
+	// whole token_usage history.
+	usageMaxMonths = 24
+
+	usageDatasetUsage = "usage"
+	usageDatasetJobs  = "jobs"
+)
+
+// usageEmbeddingOperations are the token_usage operations that only call
+// the embeddings API: transcription, re-embed, PDF indexing and the
+// incremental re-embed after a transcript correction.
+var usageEmbeddingOperations = []string{"transcribe+embed", "reembed", "pdf_embed", "transcript_edit"}
+
+// ---------- 1. HTTP handler ----------
+
+// GetUsageReport handles `GET /api/v1/ai/usage-report`.
+//
+// Query params:
+//   - from, to  (optional, YYYY-MM, inclusive; default the current month)
+//   - tenantId  (optional; all tenants when empty)
+//   - courseId  (optional)
+//   - format    (optional, "json" default or "csv")
+//   - dataset   (csv only: "usage" default or "jobs")
+//
+// JSON returns both the usage aggregate and the job counts. CSV returns
+// one dataset per file so finance can load it straight into a sheet.
+// Costs are USD cents, as priced in cost.go.
+func (f *Feature) GetUsageReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed), "")
+		return
+	}
+	if !f.requireInternalAPIKey(w, r) {
+		return
+	}
+	if f.transcriptionDB == nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "transcription DB not configured")
+		return
+	}
+
+	q := r.URL.Query()
+	from, to, err := parseUsageRange(q.Get("from"), q.Get("to"), time.Now().UTC())
+	if err != nil {
+		writeCustomError(w, http.StatusBadRequest, err.Error(), "INVALID_MONTH_RANGE")
+		return
+	}
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	dataset := q.Get("dataset")
+	if dataset == "" {
+		dataset = usageDatasetUsage
+	}
+	if format != "json" && format != "csv" {
+		writeCustomError(w, http.StatusBadRequest, "format deve ser \"json\" ou \"csv\"", "INVALID_FORMAT")
+		return
+	}
+	if dataset != usageDatasetUsage && dataset != usageDatasetJobs {
+		writeCustomError(w, http.StatusBadRequest, "dataset deve ser \"usage\" ou \"jobs\"", "INVALID_DATASET")
+		return
+	}
+
+	resp, err := f.buildUsageReport(r.Context(), from, to, q.Get("tenantId"), q.Get("courseId"))
+	if err != nil {
+		f.log.Error("transcription.usage_report.query_failed", "error", err.Error())
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", "")
+		return
+	}
+
+	if format == "json" {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="ai-%s-%s-%s.csv"`, dataset, resp.From, resp.To))
+	w.WriteHeader(http.StatusOK)
+	if dataset == usageDatasetJobs {
+		err = writeJobCountsCSV(w, resp.Jobs)
+	} else {
+		err = writeUsageCSV(w, resp.Usage)
+	}
+	if err != nil {
+		f.log.Error("transcription.usage_report.csv_failed", "error", err.Error())
+	}
+}
+
+// ---------- 2. Business rule ----------
+
+// parseUsageRange turns from/to months into the half-open [start, end)
+// timestamp range the queries filter on. Either bound defaults to the
+// other, and both to the current month.
+func parseUsageRange(fromRaw, toRaw string, now time.Time) (time.Time, time.Time, error) {
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	parse := func(v string) (time.Time, error) {
+		t, err := time.Parse(usageMonthLayout, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("mês inválido %q (formato YYYY-MM)", v)
+		}
+		return t, nil
+	}
+
+	from, to := current, current
+	var err error
+	switch {
+	case fromRaw != "" && toRaw != "":
+		if from, err = parse(fromRaw); err != nil {
+			return from, to, err
+		}
+		if to, err = parse(toRaw); err != nil {
+			return from, to, err
+		}
+	case fromRaw != "":
+		if from, err = parse(fromRaw); err != nil {
+			return from, to, err
+		}
+		to = from
+	case toRaw != "":
+		if to, err = parse(toRaw); err != nil {
+			return from, to, err
+		}
+		from = to
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("to deve ser igual ou posterior a from")
+	}
+	if to.AddDate(0, -usageMaxMonths+1, 0).After(from) {
+		return from, to, fmt.Errorf("intervalo máximo é de %d meses", usageMaxMonths)
+	}
+	return from, to.AddDate(0, 1, 0), nil
+}
+
+func (f *Feature) buildUsageReport(ctx context.Context, start, end time.Time, tenantID, courseID string) (*usageReportResponse, error) {
+	resp := &usageReportResponse{
+		From:     start.Format(usageMonthLayout),
+		To:       end.AddDate(0, -1, 0).Format(usageMonthLayout),
+		TenantID: tenantID,
+		CourseID: courseID,
+		Usage:    make([]usageRow, 0),
+		Jobs:     make([]jobCountRow, 0),
+	}
+
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlUsageReport, start, end, tenantID, courseID,
+		pq.Array(usageEmbeddingOperations))
+	if err != nil {
+		return nil, fmt.Errorf("usage report: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var u usageRow
+		if err := rows.Scan(&u.Month, &u.TenantID, &u.CourseID, &u.Model, &u.Entries,
+			&u.MinutesTranscribed, &u.EmbeddingTokens, &u.OtherTokens, &u.CostCents); err != nil {
+			return nil, fmt.Errorf("scan usage row: %w", err)
+		}
+		resp.Usage = append(resp.Usage, u)
+		resp.Totals.MinutesTranscribed += u.MinutesTranscribed
+		resp.Totals.EmbeddingTokens += u.EmbeddingTokens
+		resp.Totals.OtherTokens += u.OtherTokens
+		resp.Totals.CostCents += u.CostCents
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate usage rows: %w", err)
+	}
+
+	jobRows, err := f.transcriptionDB.QueryContext(ctx, sqlJobCountsReport, start, end, tenantID, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("job counts report: %w", err)
+	}
+	defer jobRows.Close()
+	for jobRows.Next() {
+		var j jobCountRow
+		if err := jobRows.Scan(&j.Month, &j.TenantID, &j.CourseID, &j.Type, &j.Status, &j.Count); err != nil {
+			return nil, fmt.Errorf("scan job count row: %w", err)
+		}
+		resp.Jobs = append(resp.Jobs, j)
+	}
+	if err := jobRows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate job count rows: %w", err)
+	}
+	return resp, nil
+}
+
+// ---------- 3. CSV ----------
+
+func writeUsageCSV(w http.ResponseWriter, rows []usageRow) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"month", "tenant_id", "course_id", "model", "entries",
+		"minutes_transcribed", "embedding_tokens", "other_tokens", "cost_usd_cents"})
+	for _, u := range rows {
+		_ = cw.Write([]string{
+			u.Month, u.TenantID, u.CourseID, u.Model,
+			strconv.Itoa(u.Entries),
+			strconv.FormatFloat(u.MinutesTranscribed, 'f', 2, 64),
+			strconv.FormatInt(u.EmbeddingTokens, 10),
+			strconv.FormatInt(u.OtherTokens, 10),
+			strconv.FormatInt(u.CostCents, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJobCountsCSV(w http.ResponseWriter, rows []jobCountRow) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"month", "tenant_id", "course_id", "type", "status", "count"})
+	for _, j := range rows {
+		_ = cw.Write([]string{j.Month, j.TenantID, j.CourseID, j.Type, j.Status, strconv.Itoa(j.Count)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,152 @@
+package transcription
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func TestParseUsageRange(t *testing.T) {
+	now := time.Date(2026, 3, 17, 10, 0, 0, 0, time.UTC)
+	month := func(y int, m time.Month) time.Time { return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC) }
+
+	cases := []struct {
+		name             string
+		from, to         string
+		wantFrom, wantTo time.Time
+		wantErr          bool
+	}{
+		{"defaults to current month", "", "", month(2026, 3), month(2026, 4), false},
+		{"from only", "2025-12", "", month(2025, 12), month(2026, 1), false},
+		{"to only", "", "2026-01", month(2026, 1), month(2026, 2), false},
+		{"range", "2025-11", "2026-02", month(2025, 11), month(2026, 3), false},
+		{"bad month", "2026-13", "", time.Time{}, time.Time{}, true},
+		{"inverted", "2026-02", "2026-01", time.Time{}, time.Time{}, true},
+		{"too wide", "2023-01", "2026-01", time.Time{}, time.Time{}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			from, to, err := parseUsageRange(tc.from, tc.to, now)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got [%s, %s)", from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !from.Equal(tc.wantFrom) || !to.Equal(tc.wantTo) {
+				t.Fatalf("got [%s, %s), want [%s, %s)", from, to, tc.wantFrom, tc.wantTo)
+			}
+		})
+	}
+}
+
+func expectUsageReportQueries(mock sqlmock.Sqlmock) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`billedSecs.*FROM token_usage`).
+		WithArgs(start, end, "t1", "", pq.Array([]string{"transcribe+embed", "reembed", "pdf_embed", "transcript_edit"})).
+		WillReturnRows(sqlmock.NewRows([]string{"month", "tenant_id", "course_id", "model", "entries",
+			"minutes", "embedding_tokens", "other_tokens", "cost_cents"}).
+			AddRow("2026-01", "t1", "c1", "text-embedding-3-small", 3, 42.5, 12000, 0, 27).
+			AddRow("2026-02", "t1", "", "gpt-4o-mini", 5, 0.0, 0, 900, 2).
+			// PDF indexing only: embedding tokens, no minutes.
+			AddRow("2026-02", "t1", "c2", "text-embedding-3-small", 1, 0.0, 3000, 0, 1))
+	mock.ExpectQuery(`FROM jobs`).
+		WithArgs(start, end, "t1", "").
+		WillReturnRows(sqlmock.NewRows([]string{"month", "tenant_id", "course_id", "type", "status", "count"}).
+			AddRow("2026-01", "t1", "c1", JobTypeVideoProcessing, JobStatusCompleted, 3).
+			AddRow("2026-01", "t1", "c1", JobTypeVideoProcessing, JobStatusFailed, 1))
+}
+
+func getUsageReport(t *testing.T, path string, expect bool) (*httptest.ResponseRecorder, sqlmock.Sqlmock) {
+	t.Helper()
+	_, mock, r := newJobControlRouter(t)
+	if expect {
+		expectUsageReportQueries(mock)
+	}
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("x-internal-api-key", "k")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w, mock
+}
+
+func TestGetUsageReport_JSON(t *testing.T) {
+	w, mock := getUsageReport(t, "/usage-report?tenantId=t1&from=2026-01&to=2026-02", true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	var resp usageReportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.From != "2026-01" || resp.To != "2026-02" {
+		t.Fatalf("range = %s..%s", resp.From, resp.To)
+	}
+	if len(resp.Usage) != 3 || len(resp.Jobs) != 2 {
+		t.Fatalf("usage = %d rows, jobs = %d rows", len(resp.Usage), len(resp.Jobs))
+	}
+	want := usageTotals{MinutesTranscribed: 42.5, EmbeddingTokens: 15000, OtherTokens: 900, CostCents: 30}
+	if resp.Totals != want {
+		t.Fatalf("totals = %+v, want %+v", resp.Totals, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetUsageReport_CSV(t *testing.T) {
+	w, _ := getUsageReport(t, "/usage-report?tenantId=t1&from=2026-01&to=2026-02&format=csv", true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("content-type = %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "ai-usage-2026-01-2026-02.csv") {
+		t.Fatalf("content-disposition = %q", cd)
+	}
+	want := "month,tenant_id,course_id,model,entries,minutes_transcribed,embedding_tokens,other_tokens,cost_usd_cents\n" +
+		"2026-01,t1,c1,text-embedding-3-small,3,42.50,12000,0,27\n" +
+		"2026-02,t1,,gpt-4o-mini,5,0.00,0,900,2\n" +
+		"2026-02,t1,c2,text-embedding-3-small,1,0.00,3000,0,1\n"
+	if got := w.Body.String(); got != want {
+		t.Fatalf("csv:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGetUsageReport_JobsCSV(t *testing.T) {
+	w, _ := getUsageReport(t, "/usage-report?tenantId=t1&from=2026-01&to=2026-02&format=csv&dataset=jobs", true)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", w.Code, w.Body.String())
+	}
+	want := "month,tenant_id,course_id,type,status,count\n" +
+		"2026-01,t1,c1," + JobTypeVideoProcessing + "," + JobStatusCompleted + ",3\n" +
+		"2026-01,t1,c1," + JobTypeVideoProcessing + "," + JobStatusFailed + ",1\n"
+	if got := w.Body.String(); got != want {
+		t.Fatalf("csv:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGetUsageReport_Validation(t *testing.T) {
+	cases := map[string]string{
+		"/usage-report?from=2026-1":            "INVALID_MONTH_RANGE",
+		"/usage-report?format=xlsx":            "INVALID_FORMAT",
+		"/usage-report?format=csv&dataset=foo": "INVALID_DATASET",
+	}
+	for path, code := range cases {
+		w, _ := getUsageReport(t, path, false)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), code) {
+			t.Fatalf("%s: status = %d body = %s", path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -73,8 +73,9 @@ func (f *Feature) Stop(timeout time.Duration) {
 }
 
 // run is the worker pool's main loop: it polls jobs at f.pollInterval and
-// scans for orphans at orphanResetInterval, fanning each claimed job out
-// to a pool of f.workers goroutines through a buffered channel.
+// scans for orphans (plus over-budget jobs ready to release and stale
+// checkpoints) at orphanResetInterval, fanning each claimed job out to a
+// pool of f.workers goroutines through a buffered channel.
 func (f *Feature) run(ctx context.Context) {
 	pollT := time.NewTicker(f.pollInterval)
 	orphanT := time.NewTicker(orphanResetInterval)
@@ -103,6 +104,18 @@ func (f *Feature) run(ctx context.Context) {
 			} else if n > 0 {
 				f.log.Info("transcription.worker.orphans_reset", "count", n)
 			}
+			n, err = f.releaseParkedJobs(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				f.log.Error("transcription.worker.release_parked_failed", "error", err.Error())
+			} else if n > 0 {
+				f.log.Info("transcription.worker.parked_jobs_released", "count", n)
+			}
+			n, err = f.pruneCheckpoints(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				f.log.Error("transcription.worker.checkpoint_prune_failed", "error", err.Error())
+			} else if n > 0 {
+				f.log.Info("transcription.worker.checkpoints_pruned", "count", n)
+			}
 		case <-pollT.C:
 			if err := f.tick(ctx, jobChan); err != nil && !errors.Is(err, context.Canceled) {
 				f.log.Error("transcription.worker.tick_failed", "error", err.Error())
@@ -114,7 +127,8 @@ func (f *Feature) run(ctx context.Context) {
 // tick claims up to f.workers jobs and pushes them onto jobChan. Returning
 // quickly is more important than draining the full backlog — the next
 // tick will pick up what's left. The claim limit is intentionally bounded
-// by worker count so a slow tenant can't monopolize the pool.
+// by worker count, and the claim itself is round-robin across tenants, so
+// a slow tenant can't monopolize the pool.
 func (f *Feature) tick(ctx context.Context, jobChan chan<- claimedJob) error {
 	jobs, err := f.claimPending(ctx, f.workers)
 	if err != nil {
@@ -178,9 +192,44 @@ func (f *Feature) probeEmbedDims(ctx context.Context) error {
 	return nil
 }
 
+// processOne runs a claimed job under its own context so the cancel
+// endpoints can stop it without touching the rest of the pool: locally via
+// the inflight registry, and across replicas via watchCancellation.
 func (f *Feature) processOne(ctx context.Context, j claimedJob) {
-	f.log.Info("transcription.worker.job_started", "jobId", j.ID, "tenant", j.TenantID, "attempt", j.Attempts)
-	if err := f.executeJob(ctx, j.ID, j.TenantID, j.Payload); err != nil {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	f.trackInflight(j.ID, cancel)
+	defer f.untrackInflight(j.ID)
+	go f.watchCancellation(jobCtx, j.ID, cancel)
+
+	execute := f.executeJob
+	switch j.Type {
+	case JobTypeEmbeddingGeneration:
+		execute = f.executeEmbeddingJob
+	case JobTypeLessonSummary:
+		execute = f.executeSummaryJob
+	case JobTypePdfEmbedding:
+		execute = f.executePdfEmbeddingJob
+	}
+
+	f.log.Info("transcription.worker.job_started", "jobId", j.ID, "tenant", j.TenantID, "type", j.Type, "attempt", j.Attempts)
+	if err := execute(jobCtx, j.ID, j.TenantID, j.Payload); err != nil {
+		if errors.Is(err, errJobCancelled) || (ctx.Err() == nil && jobCtx.Err() != nil) {
+			// Only the job's own context was cancelled, or the cancel
+			// landed after the work was done but before COMPLETED was
+			// written: an operator hit a cancel endpoint. The row is
+			// already CANCELLED — don't feed it back through the retry
+			// state machine.
+			f.log.Info("transcription.worker.job_cancelled", "jobId", j.ID, "tenant", j.TenantID)
+			return
+		}
+		if errors.Is(err, errBudgetExceeded) {
+			f.log.Warn("transcription.worker.job_parked_over_budget", "jobId", j.ID, "tenant", j.TenantID, "reason", err.Error())
+			if pErr := f.parkJob(ctx, j.ID, err.Error()); pErr != nil {
+				f.log.Error("transcription.worker.park_failed", "jobId", j.ID, "error", pErr.Error())
+			}
+			return
+		}
 		f.log.Error("transcription.worker.job_failed", "jobId", j.ID, "tenant", j.TenantID, "error", err.Error())
 		if mErr := f.markJobFailed(ctx, j.ID, err.Error()); mErr != nil {
 			f.log.Error("transcription.worker.mark_failed_error", "jobId", j.ID, "error", mErr.Error())
@@ -2,28 +2,34 @@ package transcription
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 )
 
 // claimedJob carries the columns returned by sqlClaimJobs into the worker
-// pool. Payload stays raw — the pipeline owns its JSON shape.
+// pool. Payload stays raw — the pipeline owns its JSON shape; Type picks
+// which pipeline runs it.
 type claimedJob struct {
 	ID          string
 	TenantID    string
 	Payload     []byte
 	Attempts    int
 	MaxAttempts int
+	Type        string
 }
 
 // claimPending atomically claims up to `limit` PENDING jobs of type
-// VIDEO_PROCESSING and flips them to RUNNING in one round-trip. FOR
-// UPDATE SKIP LOCKED makes concurrent claims safe across worker
-// goroutines (and across multiple deployed replicas).
+// VIDEO_PROCESSING, EMBEDDING_GENERATION, LESSON_SUMMARY or PDF_EMBEDDING
+// and flips them to RUNNING in one round-trip. FOR UPDATE SKIP LOCKED makes concurrent
+// claims safe across worker goroutines (and across multiple deployed
+// replicas). Tenants take turns within a priority level and none gets
+// past f.tenantMaxRunning RUNNING jobs when that is set (see sqlClaimJobs).
 func (f *Feature) claimPending(ctx context.Context, limit int) ([]claimedJob, error) {
 	if limit <= 0 {
 		return nil, nil
 	}
-	rows, err := f.transcriptionDB.QueryContext(ctx, sqlClaimJobs, limit)
+	rows, err := f.transcriptionDB.QueryContext(ctx, sqlClaimJobs, limit, f.tenantMaxRunning)
 	if err != nil {
 		return nil, fmt.Errorf("claim jobs: %w", err)
 	}
@@ -32,7 +38,7 @@ func (f *Feature) claimPending(ctx context.Context, limit int) ([]claimedJob, er
 	var out []claimedJob
 	for rows.Next() {
 		var j claimedJob
-		if err := rows.Scan(&j.ID, &j.TenantID, &j.Payload, &j.Attempts, &j.MaxAttempts); err != nil {
+		if err := rows.Scan(&j.ID, &j.TenantID, &j.Payload, &j.Attempts, &j.MaxAttempts, &j.Type); err != nil {
 			return nil, fmt.Errorf("scan claimed job: %w", err)
 		}
 		out = append(out, j)
@@ -43,13 +49,36 @@ func (f *Feature) claimPending(ctx context.Context, limit int) ([]claimedJob, er
 	return out, nil
 }
 
+// errJobCancelled is returned by markJobCompleted when the job stopped
+// being RUNNING before it could complete: an operator cancelled it while
+// the pipeline was finishing. processOne logs it as a cancellation.
+var errJobCancelled = errors.New("job cancelled before it completed")
+
+// markJobCompleted stores the job's result and flips it to COMPLETED, or
+// returns errJobCancelled when the row is no longer RUNNING.
+func (f *Feature) markJobCompleted(ctx context.Context, jobID string, result []byte) error {
+	res, err := f.transcriptionDB.ExecContext(ctx, sqlMarkJobCompleted, jobID, result)
+	if err != nil {
+		return fmt.Errorf("mark job completed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errJobCancelled
+	}
+	return nil
+}
+
 // markJobFailed flips a RUNNING job back to PENDING if attempts remain,
 // otherwise terminates it as FAILED. The state machine — including the
 // attempts >= max_attempts comparison — lives in sqlMarkJobFailed so the
-// retry decision is atomic with the status flip.
+// retry decision is atomic with the status flip. A job that is no longer
+// RUNNING (cancelled while the pipeline unwound) is left as-is.
 func (f *Feature) markJobFailed(ctx context.Context, jobID, errMsg string) error {
 	var status string
 	if err := f.transcriptionDB.QueryRowContext(ctx, sqlMarkJobFailed, jobID, errMsg).Scan(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			f.log.Info("transcription.worker.job_no_longer_running", "jobId", jobID)
+			return nil
+		}
 		return fmt.Errorf("mark job failed: %w", err)
 	}
 	f.log.Info("transcription.worker.job_state_after_failure", "jobId", jobID, "status", status)
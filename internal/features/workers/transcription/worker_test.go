@@ -2,6 +2,8 @@ package transcription
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,10 +17,10 @@ func TestClaimPending_HappyPath(t *testing.T) {
 	f := &Feature{transcriptionDB: db, log: logger.NewLogger()}
 
 	mock.ExpectQuery(`UPDATE jobs.*RETURNING id, tenant_id, payload, attempts, max_attempts`).
-		WithArgs(2).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "payload", "attempts", "max_attempts"}).
-			AddRow("job-1", "tenant-a", []byte(`{}`), 0, 3).
-			AddRow("job-2", "tenant-b", []byte(`{}`), 1, 3))
+		WithArgs(2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "payload", "attempts", "max_attempts", "type"}).
+			AddRow("job-1", "tenant-a", []byte(`{}`), 0, 3, JobTypeVideoProcessing).
+			AddRow("job-2", "tenant-b", []byte(`{}`), 1, 3, JobTypeEmbeddingGeneration))
 
 	jobs, err := f.claimPending(context.Background(), 2)
 	if err != nil {
@@ -27,7 +29,7 @@ func TestClaimPending_HappyPath(t *testing.T) {
 	if len(jobs) != 2 {
 		t.Fatalf("got %d jobs, want 2", len(jobs))
 	}
-	if jobs[0].ID != "job-1" || jobs[1].Attempts != 1 {
+	if jobs[0].ID != "job-1" || jobs[1].Attempts != 1 || jobs[1].Type != JobTypeEmbeddingGeneration {
 		t.Fatalf("bad scan: %+v", jobs)
 	}
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -35,6 +37,31 @@ func TestClaimPending_HappyPath(t *testing.T) {
 	}
 }
 
+func TestClaimPending_PassesTenantCap(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, log: logger.NewLogger(), tenantMaxRunning: 3}
+
+	mock.ExpectQuery(`CROSS JOIN LATERAL.*FOR UPDATE SKIP LOCKED.*PARTITION BY c.tenant_id.*slot <= \$2`).
+		WithArgs(4, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "payload", "attempts", "max_attempts", "type"}))
+
+	if _, err := f.claimPending(context.Background(), 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// A job_type literal the enum lacks is an error in Postgres, so the claim
+// compares as text and still runs before migrations 009 and 013.
+func TestClaimJobsSQL_ComparesTypeAsText(t *testing.T) {
+	if strings.Contains(sqlClaimJobs, " type IN (") || strings.Count(sqlClaimJobs, "type::text IN (") != 3 {
+		t.Fatalf("sqlClaimJobs must filter on type::text:\n%s", sqlClaimJobs)
+	}
+}
+
 func TestClaimPending_ZeroLimit(t *testing.T) {
 	f := &Feature{}
 	jobs, err := f.claimPending(context.Background(), 0)
@@ -60,6 +87,43 @@ func TestMarkJobFailed_FlipsStatus(t *testing.T) {
 	}
 }
 
+func TestMarkJobFailed_CancelledJobIsLeftAlone(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, log: logger.NewLogger()}
+
+	mock.ExpectQuery(`UPDATE jobs.*status = 'RUNNING'.*RETURNING status`).
+		WithArgs("j", "context canceled").
+		WillReturnRows(sqlmock.NewRows([]string{"status"}))
+
+	if err := f.markJobFailed(context.Background(), "j", "context canceled"); err != nil {
+		t.Fatalf("no-row update should be a no-op, got %v", err)
+	}
+}
+
+func TestMarkJobCompleted_CancelledWhileFinishing(t *testing.T) {
+	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	defer db.Close()
+	f := &Feature{transcriptionDB: db, log: logger.NewLogger()}
+
+	mock.ExpectExec(`UPDATE jobs.*COMPLETED.*status = 'RUNNING'`).
+		WithArgs("j", []byte(`{}`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE jobs.*COMPLETED.*status = 'RUNNING'`).
+		WithArgs("j", []byte(`{}`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := f.markJobCompleted(context.Background(), "j", []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.markJobCompleted(context.Background(), "j", []byte(`{}`)); !errors.Is(err, errJobCancelled) {
+		t.Fatalf("err = %v, want errJobCancelled", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestResetOrphans_ReturnsCount(t *testing.T) {
 	db, mock, _ := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
 	defer db.Close()
@@ -3,6 +3,7 @@ package bunny
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
@@ -183,6 +184,70 @@ func (b *BunnyService) UploadVideo(ctx context.Context, uploadVideoRequest dto.U
 	return nil
 }
 
+// AddCaption uploads a caption track (WebVTT or SRT) to a video. Bunny
+// replaces any existing track with the same srclang, so re-uploading after
+// a reprocess is safe.
+func (b *BunnyService) AddCaption(ctx context.Context, addCaptionRequest dto.AddCaptionRequest, bunnyParametersAccess dto.BunnyParametersAccess) error {
+	if bunnyParametersAccess.LibraryID == "" || bunnyParametersAccess.LibraryApiKey == "" {
+		return errors.New("libraryID and libraryApiKey is required")
+	}
+	if addCaptionRequest.GUID == "" || addCaptionRequest.SrcLang == "" || len(addCaptionRequest.CaptionsFile) == 0 {
+		return errors.New("guid, srclang and captionsFile are required")
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.baseURL)
+	builder.WriteString(bunnyParametersAccess.LibraryID)
+	builder.WriteString("/videos/")
+	builder.WriteString(addCaptionRequest.GUID)
+	builder.WriteString("/captions/")
+	builder.WriteString(addCaptionRequest.SrcLang)
+	url := builder.String()
+
+	b.log.Debug("Uploading caption to Bunny", "guid", addCaptionRequest.GUID, "srclang", addCaptionRequest.SrcLang, "url", url)
+
+	header := http.Header{
+		"Content-Type": []string{"application/json"},
+		"AccessKey":    []string{bunnyParametersAccess.LibraryApiKey},
+	}
+
+	reqBody, err := json.Marshal(map[string]string{
+		"srclang":      addCaptionRequest.SrcLang,
+		"label":        addCaptionRequest.Label,
+		"captionsFile": base64.StdEncoding.EncodeToString(addCaptionRequest.CaptionsFile),
+	})
+	if err != nil {
+		b.log.Error("Failed to marshal request body", "error", err, "guid", addCaptionRequest.GUID)
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(reqBody))
+	if err != nil {
+		b.log.Error("Failed to create HTTP request", "error", err, "url", url)
+		return err
+	}
+
+	r.Header = header
+
+	resp, err := b.client.Do(r)
+	if err != nil {
+		b.log.Error("HTTP request failed", "error", err, "url", url)
+		return err
+	}
+	defer resp.Body.Close()
+
+	b.log.Debug("HTTP response received", "statusCode", resp.StatusCode, "url", url)
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		b.log.Error("Bunny API returned error", "statusCode", resp.StatusCode, "status", resp.Status, "url", url, "responseBody", string(bodyBytes))
+		return errors.New(resp.Status)
+	}
+
+	b.log.Info("Caption uploaded successfully", "guid", addCaptionRequest.GUID, "srclang", addCaptionRequest.SrcLang)
+	return nil
+}
+
 func (b *BunnyService) GetCollections(ctx context.Context, bunnyParametersAccess dto.BunnyParametersAccess) (*dto.BunnyCollectionsResponse, error) {
 
 	if bunnyParametersAccess.LibraryID == "" || bunnyParametersAccess.LibraryApiKey == "" {
@@ -2,6 +2,8 @@ package bunny
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -317,6 +319,109 @@ func TestBunnyService_UploadVideo(t *testing.T) {
 	}
 }
 
+func TestBunnyService_AddCaption(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       dto.AddCaptionRequest
+		access        dto.BunnyParametersAccess
+		serverStatus  int
+		expectedError bool
+	}{
+		{
+			name: "should upload caption successfully",
+			request: dto.AddCaptionRequest{
+				GUID:         "test-guid",
+				SrcLang:      "pt",
+				Label:        "Português",
+				CaptionsFile: []byte("WEBVTT\n\n00:00:00.000 --> 00:00:01.000\noi\n\n"),
+			},
+			access: dto.BunnyParametersAccess{
+				LibraryID:     "test-library",
+				LibraryApiKey: "test-key",
+			},
+			serverStatus:  http.StatusOK,
+			expectedError: false,
+		},
+		{
+			name: "should return error when credentials are missing",
+			request: dto.AddCaptionRequest{
+				GUID:         "test-guid",
+				SrcLang:      "pt",
+				CaptionsFile: []byte("WEBVTT"),
+			},
+			access:        dto.BunnyParametersAccess{},
+			expectedError: true,
+		},
+		{
+			name: "should return error when captions file is empty",
+			request: dto.AddCaptionRequest{
+				GUID:    "test-guid",
+				SrcLang: "pt",
+			},
+			access: dto.BunnyParametersAccess{
+				LibraryID:     "test-library",
+				LibraryApiKey: "test-key",
+			},
+			expectedError: true,
+		},
+		{
+			name: "should return error when server returns error",
+			request: dto.AddCaptionRequest{
+				GUID:         "test-guid",
+				SrcLang:      "pt",
+				CaptionsFile: []byte("WEBVTT"),
+			},
+			access: dto.BunnyParametersAccess{
+				LibraryID:     "test-library",
+				LibraryApiKey: "test-key",
+			},
+			serverStatus:  http.StatusUnauthorized,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockLogger := mocks.NewMockLogger(t)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/test-library/videos/test-guid/captions/pt", r.URL.Path)
+				assert.Equal(t, "test-key", r.Header.Get("AccessKey"))
+
+				var body map[string]string
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				decoded, err := base64.StdEncoding.DecodeString(body["captionsFile"])
+				assert.NoError(t, err)
+				assert.Equal(t, string(tt.request.CaptionsFile), string(decoded))
+				assert.Equal(t, tt.request.SrcLang, body["srclang"])
+
+				w.WriteHeader(tt.serverStatus)
+			}))
+			defer server.Close()
+
+			service := &BunnyService{
+				client:  &http.Client{},
+				baseURL: server.URL + "/",
+				log:     mockLogger,
+			}
+
+			mockLogger.EXPECT().Debug(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+			mockLogger.EXPECT().Debug(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+			mockLogger.EXPECT().Info(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+			mockLogger.EXPECT().Error(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return().Maybe()
+
+			err := service.AddCaption(context.Background(), tt.request, tt.access)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestBunnyService_GetCollections(t *testing.T) {
 	tests := []struct {
 		name           string
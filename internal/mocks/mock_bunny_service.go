@@ -22,6 +22,54 @@ func (_m *MockBunnyService) EXPECT() *MockBunnyService_Expecter {
 	return &MockBunnyService_Expecter{mock: &_m.Mock}
 }
 
+// AddCaption provides a mock function with given fields: ctx, addCaptionRequest, bunnyParametersAccess
+func (_m *MockBunnyService) AddCaption(ctx context.Context, addCaptionRequest dto.AddCaptionRequest, bunnyParametersAccess dto.BunnyParametersAccess) error {
+	ret := _m.Called(ctx, addCaptionRequest, bunnyParametersAccess)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddCaption")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.AddCaptionRequest, dto.BunnyParametersAccess) error); ok {
+		r0 = rf(ctx, addCaptionRequest, bunnyParametersAccess)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockBunnyService_AddCaption_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddCaption'
+type MockBunnyService_AddCaption_Call struct {
+	*mock.Call
+}
+
+// AddCaption is a helper method to define mock.On call
+//   - ctx context.Context
+//   - addCaptionRequest dto.AddCaptionRequest
+//   - bunnyParametersAccess dto.BunnyParametersAccess
+func (_e *MockBunnyService_Expecter) AddCaption(ctx interface{}, addCaptionRequest interface{}, bunnyParametersAccess interface{}) *MockBunnyService_AddCaption_Call {
+	return &MockBunnyService_AddCaption_Call{Call: _e.mock.On("AddCaption", ctx, addCaptionRequest, bunnyParametersAccess)}
+}
+
+func (_c *MockBunnyService_AddCaption_Call) Run(run func(ctx context.Context, addCaptionRequest dto.AddCaptionRequest, bunnyParametersAccess dto.BunnyParametersAccess)) *MockBunnyService_AddCaption_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(dto.AddCaptionRequest), args[2].(dto.BunnyParametersAccess))
+	})
+	return _c
+}
+
+func (_c *MockBunnyService_AddCaption_Call) Return(_a0 error) *MockBunnyService_AddCaption_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockBunnyService_AddCaption_Call) RunAndReturn(run func(context.Context, dto.AddCaptionRequest, dto.BunnyParametersAccess) error) *MockBunnyService_AddCaption_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateCollection provides a mock function with given fields: ctx, createCollectionRequest, bunnyParametersAccess
 func (_m *MockBunnyService) CreateCollection(ctx context.Context, createCollectionRequest dto.CreateCollectionRequest, bunnyParametersAccess dto.BunnyParametersAccess) (*dto.CreateCollectionResponse, error) {
 	ret := _m.Called(ctx, createCollectionRequest, bunnyParametersAccess)
@@ -0,0 +1,18 @@
+-- Full-text index for hybrid search (POST /api/v1/ai/search, mode=hybrid).
+-- Run manually against the Railway pgvector database:
+--
+--     psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/003_chunks_fulltext.sql
+--
+-- Expression index instead of a stored tsvector column: no table rewrite,
+-- and the pipeline's COPY into chunks keeps its column list. The
+-- expression MUST stay byte-identical to the one buildHybridSearchQuery
+-- emits (`to_tsvector('portuguese', text)`), otherwise the planner ignores
+-- the index and falls back to a sequential scan.
+--
+-- CONCURRENTLY avoids blocking chunk inserts while the index builds, which
+-- also means this file cannot run inside a transaction (no psql -1).
+--
+-- All statements are idempotent.
+
+CREATE INDEX CONCURRENTLY IF NOT EXISTS chunks_text_fts_portuguese
+    ON chunks USING gin (to_tsvector('portuguese', text));
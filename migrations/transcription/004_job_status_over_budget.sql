@@ -0,0 +1,12 @@
+-- Add OVER_BUDGET to the job_status enum.
+--
+-- The transcription worker parks a job in OVER_BUDGET (instead of running
+-- it) when the tenant's monthly AI spend cap has been reached; parked jobs
+-- go back to PENDING once the tenant is under its cap again (new month or
+-- raised cap). See internal/features/workers/transcription/budget.go.
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on
+-- Postgres < 12; run this file on its own:
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/004_job_status_over_budget.sql
+
+ALTER TYPE job_status ADD VALUE IF NOT EXISTS 'OVER_BUDGET';
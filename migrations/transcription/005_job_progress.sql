@@ -0,0 +1,10 @@
+-- Add jobs.progress for live pipeline progress.
+--
+-- The transcription worker writes { stage, percent, part, parts, message }
+-- while a job runs; GET /api/v1/ai/jobs/{jobId} returns it and the SSE
+-- endpoints (/jobs/{jobId}/events, /tenants/{tenantId}/jobs/events) stream
+-- it. See internal/features/workers/transcription/progress.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/005_job_progress.sql
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS progress jsonb;
@@ -0,0 +1,20 @@
+-- Per-part checkpoints for transcription jobs.
+--
+-- The worker stores each audio part's speech-to-text response and each
+-- embeddings batch as it finishes, so a retry after a late failure
+-- (embedding, commit) resumes instead of re-downloading and re-paying
+-- Whisper. Rows are deleted when the job completes and pruned after 7
+-- days otherwise. See internal/features/workers/transcription/checkpoint.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/006_job_checkpoints.sql
+
+CREATE TABLE IF NOT EXISTS job_checkpoints (
+    job_id     text        NOT NULL,
+    kind       text        NOT NULL,
+    idx        integer     NOT NULL,
+    data       jsonb       NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (job_id, kind, idx)
+);
+
+CREATE INDEX IF NOT EXISTS job_checkpoints_created_at_idx ON job_checkpoints (created_at);
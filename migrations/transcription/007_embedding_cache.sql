@@ -0,0 +1,19 @@
+-- Content-hash embedding cache.
+--
+-- embedChunks looks every chunk text up by (model, dims, sha256(text))
+-- before calling OpenAI and stores the vectors of the misses, so
+-- re-processing or re-embedding a lesson whose transcript did not change
+-- costs no embedding tokens. The embedding column is left untyped so
+-- every probed chunks.embedding width fits. See
+-- internal/features/workers/transcription/embedcache.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/007_embedding_cache.sql
+
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    model       text        NOT NULL,
+    dims        integer     NOT NULL,
+    text_sha256 text        NOT NULL,
+    embedding   vector      NOT NULL,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (model, dims, text_sha256)
+);
@@ -0,0 +1,12 @@
+-- Add DIRECT_URL to the video_source_type enum.
+--
+-- Lessons whose mediaUrl is a plain MP4/HLS file (DigitalOcean Spaces or
+-- any public CDN) are pulled straight through ffmpeg and recorded with
+-- source_type DIRECT_URL. See
+-- internal/features/workers/transcription/source.go.
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on
+-- Postgres < 12; run this file on its own:
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/008_video_source_direct_url.sql
+
+ALTER TYPE video_source_type ADD VALUE IF NOT EXISTS 'DIRECT_URL';
@@ -0,0 +1,13 @@
+-- Add LESSON_SUMMARY to the job_type enum.
+--
+-- A LESSON_SUMMARY job asks the chat model for a lesson summary, chapters
+-- and key points from a stored transcript. It is queued after a
+-- VIDEO_PROCESSING job completes (TRANSCRIPTION_SUMMARIES_ENABLED) or by
+-- POST /api/v1/ai/tenants/summarize-lessons. See
+-- internal/features/workers/transcription/summary.go.
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on
+-- Postgres < 12; run this file on its own:
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/009_job_type_lesson_summary.sql
+
+ALTER TYPE job_type ADD VALUE IF NOT EXISTS 'LESSON_SUMMARY';
@@ -0,0 +1,26 @@
+-- LLM-written lesson summaries.
+--
+-- One row per video: the summary text, the chapter list
+-- ([{title, startTime}]) and the key points, written by LESSON_SUMMARY
+-- jobs and read by the vitrine lesson endpoint. When a lesson's mediaUrl
+-- changes, the summary of the replaced video is deleted once the new one
+-- is in. See internal/features/workers/transcription/summary.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/010_lesson_summaries.sql
+
+CREATE TABLE IF NOT EXISTS lesson_summaries (
+    video_id      text        PRIMARY KEY,
+    tenant_id     text        NOT NULL,
+    lesson_id     text,
+    transcript_id text        NOT NULL,
+    summary       text        NOT NULL,
+    chapters      jsonb       NOT NULL DEFAULT '[]'::jsonb,
+    key_points    jsonb       NOT NULL DEFAULT '[]'::jsonb,
+    language      text,
+    model         text        NOT NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS lesson_summaries_tenant_lesson_idx
+    ON lesson_summaries (tenant_id, lesson_id, updated_at DESC);
@@ -0,0 +1,25 @@
+-- LLM-generated quiz drafts awaiting admin review.
+--
+-- One row per generation run: the multiple-choice questions (question,
+-- options, answerIndex, explanation and the source chunk's id and time
+-- range) as jsonb. Drafts are never published from here; an admin
+-- approves or rejects them and the admin UI copies approved questions
+-- into a real Quiz. See internal/features/workers/transcription/quiz.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/011_quiz_drafts.sql
+
+CREATE TABLE IF NOT EXISTS quiz_drafts (
+    id          text        PRIMARY KEY,
+    tenant_id   text        NOT NULL,
+    lesson_id   text        NOT NULL,
+    video_id    text,
+    status      text        NOT NULL DEFAULT 'DRAFT',
+    model       text        NOT NULL,
+    questions   jsonb       NOT NULL DEFAULT '[]'::jsonb,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    reviewed_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS quiz_drafts_tenant_lesson_idx
+    ON quiz_drafts (tenant_id, lesson_id, created_at DESC);
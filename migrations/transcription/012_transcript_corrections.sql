@@ -0,0 +1,36 @@
+-- Manual transcript corrections and tenant glossaries.
+--
+-- transcript_edits keeps one row per corrected segment (old and new text,
+-- its time range, who made it) written by
+-- PATCH /api/v1/ai/lessons/{lessonId}/transcript. transcript_glossaries
+-- holds each tenant's find/replace rules ([{find, replace,
+-- caseSensitive}]), applied to every fresh Whisper transcript before it
+-- is chunked. See internal/features/workers/transcription/corrections.go
+-- and glossary.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/012_transcript_corrections.sql
+
+CREATE TABLE IF NOT EXISTS transcript_edits (
+    id            text             PRIMARY KEY,
+    tenant_id     text             NOT NULL,
+    transcript_id text             NOT NULL,
+    video_id      text             NOT NULL,
+    lesson_id     text             NOT NULL,
+    segment_index integer          NOT NULL,
+    start_time    double precision NOT NULL,
+    end_time      double precision NOT NULL,
+    old_text      text             NOT NULL,
+    new_text      text             NOT NULL,
+    edited_by     text,
+    created_at    timestamptz      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS transcript_edits_tenant_lesson_idx
+    ON transcript_edits (tenant_id, lesson_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS transcript_glossaries (
+    tenant_id  text        PRIMARY KEY,
+    rules      jsonb       NOT NULL DEFAULT '[]'::jsonb,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
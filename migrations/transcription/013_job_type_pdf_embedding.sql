@@ -0,0 +1,11 @@
+-- Add PDF_EMBEDDING to the job_type enum.
+--
+-- A PDF_EMBEDDING job chunks and embeds the page text stored in pdf_pages
+-- for one PDF lesson. It is queued by the PDF processor once a lesson's
+-- pages are extracted. See internal/features/workers/transcription/pdf.go.
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on
+-- Postgres < 12; run this file on its own:
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/013_job_type_pdf_embedding.sql
+
+ALTER TYPE job_type ADD VALUE IF NOT EXISTS 'PDF_EMBEDDING';
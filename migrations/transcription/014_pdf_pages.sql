@@ -0,0 +1,33 @@
+-- PDF lesson pages in the search corpus.
+--
+-- pdf_pages keeps the text extracted from each page of a PDF lesson.
+-- PDF_EMBEDDING jobs cut it into chunks rows with source_type = 'PDF' and
+-- the page_number instead of start_time/end_time, which is why those
+-- columns, video_id and transcript_id become nullable. Existing rows are
+-- video chunks. See internal/features/workers/transcription/pdf.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/014_pdf_pages.sql
+
+CREATE TABLE IF NOT EXISTS pdf_pages (
+    tenant_id   text        NOT NULL,
+    lesson_id   text        NOT NULL,
+    course_id   text,
+    page_number integer     NOT NULL,
+    text        text        NOT NULL,
+    source_url  text,
+    created_at  timestamptz NOT NULL DEFAULT now(),
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (tenant_id, lesson_id, page_number)
+);
+
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS source_type text NOT NULL DEFAULT 'VIDEO';
+ALTER TABLE chunks ADD COLUMN IF NOT EXISTS page_number integer;
+
+ALTER TABLE chunks ALTER COLUMN video_id      DROP NOT NULL;
+ALTER TABLE chunks ALTER COLUMN transcript_id DROP NOT NULL;
+ALTER TABLE chunks ALTER COLUMN start_time    DROP NOT NULL;
+ALTER TABLE chunks ALTER COLUMN end_time      DROP NOT NULL;
+
+CREATE INDEX IF NOT EXISTS chunks_pdf_lesson_idx
+    ON chunks (tenant_id, lesson_id)
+ WHERE source_type = 'PDF';
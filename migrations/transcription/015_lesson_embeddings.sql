@@ -0,0 +1,32 @@
+-- Lesson-level embeddings for "related lessons".
+--
+-- lesson_embeddings keeps, per lesson, the mean (pgvector AVG) of its
+-- chunk embeddings, video and PDF alike. It is refreshed whenever a job
+-- rewrites a lesson's chunks and ranked with cosine distance by GET
+-- /lessons/{lessonId}/related. The column is an untyped vector, like
+-- embedding_cache, because the chunk dimensions are probed at runtime.
+-- See internal/features/workers/transcription/related.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/015_lesson_embeddings.sql
+
+CREATE TABLE IF NOT EXISTS lesson_embeddings (
+    tenant_id   text        NOT NULL,
+    lesson_id   text        NOT NULL,
+    course_id   text,
+    embedding   vector      NOT NULL,
+    chunks      integer     NOT NULL,
+    updated_at  timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (tenant_id, lesson_id)
+);
+
+-- Backfill the lessons embedded before this migration. Grouping by
+-- vector_dims keeps AVG off lessons caught mid re-embed with two widths;
+-- such a lesson keeps whichever mean comes first and the next job on it
+-- recomputes the mean at the current width.
+INSERT INTO lesson_embeddings (tenant_id, lesson_id, course_id, embedding, chunks, updated_at)
+SELECT tenant_id, lesson_id, MAX(course_id), AVG(embedding), COUNT(*), now()
+  FROM chunks
+ WHERE embedding IS NOT NULL
+   AND lesson_id IS NOT NULL
+ GROUP BY tenant_id, lesson_id, vector_dims(embedding)
+ON CONFLICT (tenant_id, lesson_id) DO NOTHING;
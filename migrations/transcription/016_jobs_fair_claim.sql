@@ -0,0 +1,11 @@
+-- Per-tenant index for the fair job claim.
+--
+-- sqlClaimJobs now walks each tenant with PENDING jobs and locks that
+-- tenant's next jobs by priority/created_at, so the claim needs an index
+-- led by tenant_id. See internal/features/workers/transcription/sql.go.
+--
+--   psql "$DB_TRANSCRIPTION_DSN" -f migrations/transcription/016_jobs_fair_claim.sql
+
+CREATE INDEX IF NOT EXISTS jobs_pending_tenant_priority
+    ON jobs (tenant_id, priority DESC, created_at ASC)
+    WHERE status = 'PENDING';
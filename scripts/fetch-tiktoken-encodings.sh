@@ -0,0 +1,53 @@
+#!/usr/bin/env bash
+# fetch-tiktoken-encodings.sh — download OpenAI's tiktoken rank files and
+# gzip them into the transcription slice so they are embedded in the
+# binary (internal/features/workers/transcription/bpe/).
+#
+# Why this script exists:
+#   - The chunker sizes chunks in real text-embedding-3-small tokens
+#     (cl100k_base). The service never fetches vocabularies at runtime, so
+#     the files have to be committed next to the code that embeds them.
+#   - The files are committed; the tokenizer tests fail when they are
+#     missing. Re-run this only to refresh or add an encoding.
+#
+# Usage:
+#   ./scripts/fetch-tiktoken-encodings.sh                # cl100k_base + o200k_base
+#   ./scripts/fetch-tiktoken-encodings.sh cl100k_base    # just one
+#
+# Each download is checked against the sha256 tiktoken itself pins before
+# it is written.
+
+set -euo pipefail
+
+BASE_URL="${TIKTOKEN_BASE_URL:-https://openaipublic.blob.core.windows.net/encodings}"
+DEST="$(cd "$(dirname "$0")/.." && pwd)/internal/features/workers/transcription/bpe"
+
+declare -A SHA256=(
+  [cl100k_base]=223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7
+  [o200k_base]=446a9538cb6c348e3516120d7c08b09f57c36495e2acfffe59a5bf8b0cfb1a2d
+)
+
+ENCODINGS=("$@")
+if [ ${#ENCODINGS[@]} -eq 0 ]; then
+  ENCODINGS=(cl100k_base o200k_base)
+fi
+
+TMP="$(mktemp -d)"
+trap 'rm -rf "$TMP"' EXIT
+
+for name in "${ENCODINGS[@]}"; do
+  want="${SHA256[$name]:-}"
+  if [ -z "$want" ]; then
+    echo "unknown encoding: $name" >&2
+    exit 1
+  fi
+  echo "→ $name"
+  curl -fsSL "$BASE_URL/$name.tiktoken" -o "$TMP/$name.tiktoken"
+  got="$(sha256sum "$TMP/$name.tiktoken" | cut -d' ' -f1)"
+  if [ "$got" != "$want" ]; then
+    echo "sha256 mismatch for $name: got $got, want $want" >&2
+    exit 1
+  fi
+  gzip -9 -n -c "$TMP/$name.tiktoken" > "$DEST/$name.tiktoken.gz"
+  echo "  wrote $DEST/$name.tiktoken.gz"
+done